@@ -0,0 +1,164 @@
+// Package bot は外部のボットが標準入出力の行単位のコマンドでゲームをプレイするためのプロトコル.
+//
+// 囲碁のGTPと同じく、1行に1つのコマンドを受け取り、成功なら "=" で、失敗なら "?" で始まる応答を返す。
+// 応答は空行で終わる。盤面は盤面ファイルと同じ文字表記（プレイヤーに見えている情報だけ）で返す.
+//
+//	new [difficulty|WxHxM] [seed]  新しいゲームを始める（引数を省略すると前と同じ大きさ）
+//	reveal ROW COL                 マスを開く
+//	flag ROW COL                   旗を切り替える
+//	chord ROW COL                  数字と同じ数の旗が周囲にあれば、残りの周囲のマスをまとめて開く
+//	board                          今の盤面を返す
+//	quit                           終了する
+//
+// 盤面を返すコマンドの応答の1行目は "= STATE WIDTH HEIGHT REMAINING" で、続く行が盤面の文字表記.
+// STATE は playing / won / lost、REMAINING は地雷の数から旗の数を引いたもの。空行と'#'で始まる行は無視する.
+package bot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// Session は1つの入出力の間続くゲーム.
+type Session struct {
+	game *game.Game
+}
+
+// NewSession は指定した難易度とルールで最初のゲームを始める.
+func NewSession(difficulty game.Difficulty, options game.Options) *Session {
+	return &Session{game: game.NewGameWithOptions(difficulty, options)}
+}
+
+// Run は r からコマンドを読み、応答を w に書く。quit か入力の終わりで戻る.
+func (s *Session) Run(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if fields[0] == "quit" {
+			_, err := fmt.Fprint(w, "=\n\n")
+			return err
+		}
+		response, cmdErr := s.Execute(fields[0], fields[1:])
+		if cmdErr != nil {
+			response = "? " + cmdErr.Error() + "\n"
+		}
+		if _, err := fmt.Fprint(w, response, "\n"); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// moves は盤面の位置を1つ取り、ゲームに手を打つコマンド.
+var moves = map[string]func(*game.Game, game.Position){
+	"reveal": func(g *game.Game, pos game.Position) { g.Click(pos) },
+	"flag":   (*game.Game).ToggleFlag,
+	"chord":  func(g *game.Game, pos game.Position) { g.Chord(pos) },
+}
+
+// Execute はコマンドを1つ実行し、空行を除いた応答を返す.
+func (s *Session) Execute(command string, args []string) (string, error) {
+	if move, ok := moves[command]; ok {
+		pos, err := s.position(args)
+		if err != nil {
+			return "", err
+		}
+		move(s.game, pos)
+		return s.board(), nil
+	}
+
+	switch command {
+	case "new":
+		if err := s.newGame(args); err != nil {
+			return "", err
+		}
+	case "board":
+		if len(args) != 0 {
+			return "", fmt.Errorf("board takes no arguments")
+		}
+	default:
+		return "", fmt.Errorf("unknown command %q", command)
+	}
+	return s.board(), nil
+}
+
+// board はゲームの状態と盤面の応答を作る.
+func (s *Session) board() string {
+	g := s.game
+	return fmt.Sprintf("= %s %d %d %d\n%s",
+		g.State, g.Board.Width, g.Board.Height, g.GetRemainingMines(), g.Board.VisibleNotation())
+}
+
+// newGame は new コマンドの引数で新しいゲームを始める。シードを省略するとランダムなシードになる.
+func (s *Session) newGame(args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("usage: new [difficulty|WxHxM] [seed]")
+	}
+	difficulty := s.game.Difficulty
+	if len(args) > 0 {
+		var err error
+		if difficulty, err = parseDifficulty(args[0]); err != nil {
+			return err
+		}
+	}
+	options := s.game.Options
+	options.Seed = 0
+	if len(args) > 1 {
+		seed, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid seed %q", args[1])
+		}
+		options.Seed = seed
+	}
+	if err := options.CheckMines(difficulty); err != nil {
+		return err
+	}
+	s.game = game.NewGameWithOptions(difficulty, options)
+	return nil
+}
+
+// parseDifficulty は難易度のキーか "幅x高さx地雷数" を解釈する.
+func parseDifficulty(s string) (game.Difficulty, error) {
+	parts := strings.Split(s, "x")
+	if len(parts) != 3 {
+		return game.ParseDifficulty(s)
+	}
+	var size [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return game.Difficulty{}, fmt.Errorf("invalid board size %q, want WIDTHxHEIGHTxMINES", s)
+		}
+		size[i] = n
+	}
+	return game.NewLimitedDifficulty(size[0], size[1], size[2])
+}
+
+// position は "ROW COL" の引数を盤面の位置として解釈する.
+func (s *Session) position(args []string) (game.Position, error) {
+	if len(args) != 2 {
+		return game.Position{}, fmt.Errorf("want ROW COL")
+	}
+	row, err := strconv.Atoi(args[0])
+	if err != nil {
+		return game.Position{}, fmt.Errorf("invalid row %q", args[0])
+	}
+	col, err := strconv.Atoi(args[1])
+	if err != nil {
+		return game.Position{}, fmt.Errorf("invalid column %q", args[1])
+	}
+	pos := game.Position{Row: row, Col: col}
+	if !s.game.Board.IsValidPosition(pos) {
+		return game.Position{}, fmt.Errorf("position %d %d is outside the board", row, col)
+	}
+	return pos, nil
+}
@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// responses は応答を空行で区切って返す.
+func responses(t *testing.T, input string) []string {
+	t.Helper()
+	var out bytes.Buffer
+	s := NewSession(game.Beginner, game.Options{Seed: 42})
+	if err := s.Run(strings.NewReader(input), &out); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(out.String(), "\n\n"), "\n\n")
+}
+
+func TestSession_Run(t *testing.T) {
+	got := responses(t, "# コメントと空行は無視する\n\nboard\nreveal 4 4\nflag 0 0\nquit\nboard\n")
+	if len(got) != 4 {
+		t.Fatalf("got %d responses, want 4 (nothing after quit):\n%q", len(got), got)
+	}
+
+	lines := strings.Split(got[0], "\n")
+	if lines[0] != "= playing 9 9 10" || len(lines) != 10 || lines[1] != "?????????" {
+		t.Errorf("board response = %q", got[0])
+	}
+	// 開いた後の盤面は文字表記として読み直せ、未開放の地雷は見えない
+	board, err := game.ParseBoard(strings.SplitN(got[1], "\n", 2)[1])
+	if err != nil {
+		t.Fatalf("reveal response is not a board: %v\n%s", err, got[1])
+	}
+	if !board.Cells[4][4].IsRevealed || board.Mines != 0 {
+		t.Errorf("reveal response should open (4,4) and hide mines:\n%s", got[1])
+	}
+	if !strings.HasSuffix(strings.SplitN(got[1], "\n", 2)[0], " 10") {
+		t.Errorf("reveal header = %q", strings.SplitN(got[1], "\n", 2)[0])
+	}
+	if got[3] != "=" {
+		t.Errorf("quit response = %q, want =", got[3])
+	}
+}
+
+func TestSession_Execute(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{"難易度を変える", "new", []string{"expert", "7"}, "= playing 30 16 99", false},
+		{"大きさを指定する", "new", []string{"5x4x3"}, "= playing 5 4 3", false},
+		{"不明な難易度", "new", []string{"nightmare"}, "", true},
+		{"大きすぎる盤面", "new", []string{"100000x100000x10"}, "", true},
+		{"不明なシード", "new", []string{"beginner", "x"}, "", true},
+		{"盤面の外", "reveal", []string{"9", "0"}, "", true},
+		{"引数が足りない", "chord", []string{"1"}, "", true},
+		{"不明なコマンド", "undo", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSession(game.Beginner, game.Options{})
+			got, err := s.Execute(tt.command, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if first := strings.SplitN(got, "\n", 2)[0]; first != tt.want {
+				t.Errorf("Execute() first line = %q, want %q", first, tt.want)
+			}
+		})
+	}
+}
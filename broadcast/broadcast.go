@@ -0,0 +1,169 @@
+// Package broadcast はプレイ中のゲームの出来事を、別の端末の観戦者に配信する.
+//
+// 出来事は1行に1つのJSONで送る。どの出来事もその直後の画面全体（盤面・カーソル・AIの推論・時間）を含むので、
+// 途中から接続した観戦者や、遅れて一部の出来事を飛ばした観戦者も次の出来事で追いつける.
+package broadcast
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// Kind は出来事の種類.
+type Kind string
+
+const (
+	// KindPlayer はプレイヤーの操作（カーソルの移動を含む）.
+	KindPlayer Kind = "player"
+	// KindAI はAIの推論や、AIがマスを開いたこと.
+	KindAI Kind = "ai"
+)
+
+// Event は観戦者に送るゲームの出来事と、その直後の画面の状態.
+type Event struct {
+	Kind Kind `json:"kind"`
+	// Board は盤面の文字表記。プレイヤーに見えている情報とAIの印だけを含む.
+	Board      string          `json:"board"`
+	Difficulty game.Difficulty `json:"difficulty"`
+	State      game.GameState  `json:"state"`
+	Cursor     game.Position   `json:"cursor"`
+	// StartTime は最初のクリックの時刻（Unix秒）。まだ開いていなければ0.
+	StartTime   int64 `json:"start_time"`
+	ElapsedTime int64 `json:"elapsed_time"`
+	// Lives は残りのライフ、MaxLives はライフ制の最初のライフ（ライフ制でなければ0）.
+	Lives    int `json:"lives"`
+	MaxLives int `json:"max_lives,omitempty"`
+	// Hint は確定できる手がないときにAIが勧めるマス.
+	Hint *Hint `json:"hint,omitempty"`
+	// Safe と Mines はAIが最後に安全・地雷と確定させたマス.
+	Safe  []game.Position `json:"safe,omitempty"`
+	Mines []game.Position `json:"mines,omitempty"`
+	// Thinking はAIが考えている途中かどうか、Auto は自動プレイ中かどうか.
+	Thinking bool `json:"thinking"`
+	Auto     bool `json:"auto"`
+}
+
+// Hint はAIが推測で開くことを勧めるマスと、その地雷確率.
+type Hint struct {
+	Position    game.Position `json:"position"`
+	Probability float64       `json:"probability"`
+}
+
+// watcherBuffer は観戦者ごとに溜めておく出来事の数。溢れた出来事はその観戦者には送らない.
+const watcherBuffer = 16
+
+// maxEventBytes は1つの出来事の大きさの上限.
+const maxEventBytes = 1 << 20
+
+// Hub は1つのゲームの出来事を、接続している観戦者全員に配信する.
+type Hub struct {
+	mu sync.Mutex
+	// last は最後に配信した出来事。接続した直後の観戦者に送る.
+	last []byte
+	// lastState は最後に配信した出来事の、種類を除いた画面の状態.
+	lastState []byte
+	watchers  map[chan []byte]struct{}
+}
+
+// NewHub は観戦者のいない配信を作成する.
+func NewHub() *Hub {
+	return &Hub{watchers: map[chan []byte]struct{}{}}
+}
+
+// Publish は出来事を配信する。直前と画面が変わらない出来事は送らない.
+// プレイヤーの操作を待たせないよう、遅い観戦者には送れなかった出来事を飛ばす.
+func (h *Hub) Publish(ev Event) {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	// 種類だけが違う出来事は、観戦者の画面を変えないので送らない
+	screen := ev
+	screen.Kind = ""
+	state, err := json.Marshal(screen)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if bytes.Equal(state, h.lastState) {
+		return
+	}
+	h.last = line
+	h.lastState = state
+	for w := range h.watchers {
+		select {
+		case w <- line:
+		default:
+		}
+	}
+}
+
+// Serve は l で観戦者の接続を受け付け、出来事を送り続ける。l が閉じられると戻る.
+func (h *Hub) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go h.watch(conn)
+	}
+}
+
+// watch は1人の観戦者に出来事を送る。観戦者が切断するか書き込めなくなったら接続を閉じる.
+func (h *Hub) watch(conn net.Conn) {
+	defer conn.Close()
+	// 観戦者からは何も送られてこないので、読み込みが終わったら切断されたとみなす
+	gone := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		close(gone)
+	}()
+
+	w := make(chan []byte, watcherBuffer)
+	h.mu.Lock()
+	if h.last != nil {
+		w <- h.last
+	}
+	h.watchers[w] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.watchers, w)
+		h.mu.Unlock()
+	}()
+	for {
+		select {
+		case line := <-w:
+			if _, err := conn.Write(line); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// Receive は r から出来事を読んで events に送る。入力の終わりで events を閉じて戻る.
+func Receive(r io.Reader, events chan<- Event) error {
+	defer close(events)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventBytes)
+	for scanner.Scan() {
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return err
+		}
+		events <- ev
+	}
+	return scanner.Err()
+}
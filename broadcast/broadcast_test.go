@@ -0,0 +1,75 @@
+package broadcast
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestHub(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	hub := NewHub()
+	go func() { _ = hub.Serve(l) }()
+
+	// 接続する前の出来事は、接続した直後に最後の1つだけが届く
+	hub.Publish(Event{Kind: KindPlayer, Board: "??\n", Difficulty: game.Beginner})
+	hub.Publish(Event{Kind: KindPlayer, Board: "1?\n", Difficulty: game.Beginner, StartTime: 1})
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	events := make(chan Event)
+	go func() { _ = Receive(conn, events) }()
+
+	first := receive(t, events)
+	if first.Board != "1?\n" || first.State != game.Playing {
+		t.Fatalf("first event = %+v, want the latest board", first)
+	}
+
+	// 種類だけが違う出来事は送らず、画面が変わった出来事だけが届く
+	hub.Publish(Event{Kind: KindAI, Board: "1?\n", Difficulty: game.Beginner, StartTime: 1})
+	hub.Publish(Event{
+		Kind: KindAI, Board: "1M\n", Difficulty: game.Beginner, StartTime: 1,
+		Mines: []game.Position{{Row: 0, Col: 1}},
+	})
+	next := receive(t, events)
+	if next.Kind != KindAI || next.Board != "1M\n" || len(next.Mines) != 1 {
+		t.Errorf("next event = %+v, want the AI mark", next)
+	}
+}
+
+func receive(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event arrived")
+	}
+	return Event{}
+}
+
+func TestReceive(t *testing.T) {
+	input := `{"kind":"player","board":"?\n","state":"won"}` + "\n" + "not json\n"
+	events := make(chan Event, 2)
+	err := Receive(strings.NewReader(input), events)
+	if err == nil {
+		t.Error("Receive() should fail on a broken line")
+	}
+	ev, ok := <-events
+	if !ok || ev.State != game.Won {
+		t.Errorf("first event = %+v, %v, want a won game", ev, ok)
+	}
+	if _, ok := <-events; ok {
+		t.Error("events should be closed")
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"os/signal"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/sim"
+)
+
+func runBench(e *env, args []string) error {
+	fs := newFlagSet(e, "bench")
+	gf := addGameFlags(fs, game.Beginner.Name)
+	games := fs.Int("n", 1000, "解かせるゲーム数")
+	strategy := fs.String("strategy", "basic", "ソルバーの戦略")
+	guess := fs.String("guess", "lowest", "行き詰まったときの推測方針（none なら推測しない）")
+	workers := fs.Int("workers", 0, "並列数（0ならCPU数）")
+	asJSON := fs.Bool("json", false, "結果をJSONで出力する")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	difficulty, err := gf.resolve()
+	if err != nil {
+		return err
+	}
+	options, err := gf.options(difficulty)
+	if err != nil {
+		return err
+	}
+
+	seed := gf.seed
+	if seed == 0 {
+		seed = rand.Int63() //nolint:gosec // ベンチマークのシードにはmath/randで十分
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := sim.Run(ctx, sim.Config{
+		Difficulty:  difficulty,
+		Options:     options,
+		Games:       *games,
+		Seed:        seed,
+		Strategy:    *strategy,
+		GuessPolicy: *guess,
+		Workers:     *workers,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return report.WriteJSON(e.stdout)
+	}
+	return report.WriteText(e.stdout)
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// command はサブコマンドの定義.
+type command struct {
+	name    string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+// env はサブコマンドの入出力先.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+var commands = []command{
+	{"play", "TUIでゲームをプレイする（既定）", runPlay},
+	{"solve", "盤面ファイルにソルバーを適用して結果を表示する", runSolve},
+	{"render", "盤面ファイルをSVGかPNGの画像にする", runRender},
+	{"generate", "シード付きの盤面を生成して表示する", runGenerate},
+	{"bench", "シード付きの多数のゲームをソルバーに解かせて勝率などを集計する", runBench},
+	{"bot", "標準入出力の行単位のコマンドでボットがプレイする", runBot},
+	{"mcp", "LLMのエージェントがプレイするためのMCPサーバーを標準入出力で起動する", runMCP},
+	{"serve", "外部のボットがHTTPとJSONでプレイするためのサーバーを起動する", runServe},
+	{"ssh", "SSHで接続した端末ごとにゲームを提供するサーバーを起動する", runSSH},
+	{"watch", "play -broadcast で配信中のゲームを観戦する", runWatch},
+	{"web", "ブラウザで遊ぶためのページをHTTPで配信する（先に make wasm が必要）", runWeb},
+}
+
+// Run はコマンドライン引数を解釈して実行し、終了コードを返す.
+// サブコマンドが省略された場合は play として扱う.
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	name := "play"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	} else if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		printUsage(stderr)
+		return 0
+	}
+
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		err := c.run(e, args)
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if name == "help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "unknown command %q\n\n", name)
+	printUsage(stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: ai-minesweeper [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "各コマンドのフラグは `ai-minesweeper <command> -h` で確認できます。")
+}
+
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// gameFlags は盤面の大きさとシードを指定する共通フラグ.
+type gameFlags struct {
+	difficulty   string
+	width        int
+	height       int
+	mines        int
+	seed         int64
+	topology     string
+	neighborhood string
+	maxCellMines int
+}
+
+func addGameFlags(fs *flag.FlagSet, defaultDifficulty string) *gameFlags {
+	f := &gameFlags{}
+	fs.StringVar(&f.difficulty, "difficulty", defaultDifficulty, "難易度 (beginner / intermediate / expert)")
+	fs.IntVar(&f.width, "width", 0, "カスタム盤面の幅（指定するとカスタム難易度になる）")
+	fs.IntVar(&f.height, "height", 0, "カスタム盤面の高さ")
+	fs.IntVar(&f.mines, "mines", -1, "カスタム盤面の地雷数（省略時はマス数の約15%）")
+	fs.Int64Var(&f.seed, "seed", 0, "地雷配置のシード（0ならランダム）")
+	fs.StringVar(&f.topology, "topology", "", "マスのつながり方 (square / torus / hex / triangle)")
+	fs.StringVar(&f.neighborhood, "neighborhood", "", "数字が数える範囲 (standard / knight / cross / radius2)")
+	fs.IntVar(&f.maxCellMines, "max-cell-mines", 0, "1マスに置ける地雷の最大数（0か1なら通常のルール、最大5）")
+	return f
+}
+
+func (f *gameFlags) isCustom() bool {
+	return f.width > 0 || f.height > 0
+}
+
+// resolve はフラグから難易度を決める.
+func (f *gameFlags) resolve() (game.Difficulty, error) {
+	if !f.isCustom() {
+		return game.ParseDifficulty(f.difficulty)
+	}
+	if f.width <= 0 || f.height <= 0 {
+		return game.Difficulty{}, fmt.Errorf("-width and -height must both be given for a custom board")
+	}
+	mines := f.mines
+	if mines < 0 {
+		mines = f.width * f.height * 15 / 100
+	}
+	return game.NewCustomDifficulty(f.width, f.height, mines)
+}
+
+// options はフラグからゲームのルール設定を作り、difficulty の地雷をそのルールで置けるかも調べる.
+func (f *gameFlags) options(difficulty game.Difficulty) (game.Options, error) {
+	topology, err := game.ParseTopology(f.topology)
+	if err != nil {
+		return game.Options{}, err
+	}
+	neighborhood, err := game.ParseNeighborhood(f.neighborhood)
+	if err != nil {
+		return game.Options{}, err
+	}
+	if err := neighborhood.Check(topology); err != nil {
+		return game.Options{}, err
+	}
+	if err := game.CheckMaxCellMines(f.maxCellMines); err != nil {
+		return game.Options{}, err
+	}
+	options := game.Options{
+		Seed:         f.seed,
+		Topology:     topology,
+		Neighborhood: neighborhood,
+		MaxCellMines: f.maxCellMines,
+	}
+	return options, options.CheckMines(difficulty)
+}
+
+// parsePosition は "row,col" 形式の位置を解釈する.
+func parsePosition(s string) (game.Position, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return game.Position{}, fmt.Errorf("invalid position %q, want row,col", s)
+	}
+	row, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return game.Position{}, fmt.Errorf("invalid row in %q: %w", s, err)
+	}
+	col, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return game.Position{}, fmt.Errorf("invalid column in %q: %w", s, err)
+	}
+	return game.Position{Row: row, Col: col}, nil
+}
+
+// center は盤面の中央の位置.
+func center(d game.Difficulty) game.Position {
+	return game.Position{Row: d.Height / 2, Col: d.Width / 2}
+}
@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func run(t *testing.T, stdin string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	code = Run(args, strings.NewReader(stdin), &out, &errOut)
+	return out.String(), errOut.String(), code
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	_, stderr, code := run(t, "", "fly")
+	if code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+	if !strings.Contains(stderr, "unknown command") {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
+
+func TestGenerate_SameSeedSameOutput(t *testing.T) {
+	out1, _, code := run(t, "", "generate", "-difficulty", "intermediate", "-seed", "99")
+	if code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	out2, _, _ := run(t, "", "generate", "-difficulty", "intermediate", "-seed", "99")
+	if out1 != out2 {
+		t.Error("generate with the same seed should be deterministic")
+	}
+
+	board, err := game.ParseBoard(out1)
+	if err != nil {
+		t.Fatalf("generated board cannot be parsed: %v", err)
+	}
+	if board.Width != 16 || board.Height != 16 || board.Mines != 40 {
+		t.Errorf("board = %dx%d with %d mines, want 16x16 with 40", board.Width, board.Height, board.Mines)
+	}
+	if !board.Cells[8][8].IsRevealed {
+		t.Error("first click at the center should be opened")
+	}
+	if !strings.Contains(out1, "# rating: ") {
+		t.Errorf("generate should print the board rating:\n%s", out1)
+	}
+}
+
+func TestGenerate_CustomSize(t *testing.T) {
+	out, stderr, code := run(t, "",
+		"generate", "-width", "12", "-height", "6", "-mines", "10", "-first", "0,0", "-hide-mines")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+	if strings.Contains(out, "*") {
+		t.Error("-hide-mines should not print mines")
+	}
+
+	board, err := game.ParseBoard(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Width != 12 || board.Height != 6 {
+		t.Errorf("board = %dx%d, want 12x6", board.Width, board.Height)
+	}
+}
+
+func TestGenerate_TooManyMinesForNeighborhood(t *testing.T) {
+	// 半径2の範囲では最初のクリックの周囲25マスに地雷を置けないので、6x6に12個は置けない
+	args := []string{"generate", "-width", "6", "-height", "6", "-neighborhood", "radius2", "-seed", "1"}
+	if _, _, code := run(t, "", append(args, "-mines", "12")...); code == 0 {
+		t.Error("generate should reject more mines than the board can hold")
+	}
+
+	out, stderr, code := run(t, "", append(args, "-mines", "11")...)
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+	if !strings.Contains(out, "mines: 11 ") {
+		t.Errorf("output should report the mines on the board:\n%s", out)
+	}
+}
+
+func TestGenerate_Topology(t *testing.T) {
+	out, stderr, code := run(t, "", "generate", "-topology", "hex", "-seed", "3")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+	board, err := game.ParseBoard(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.GetTopology() != game.HexTopology {
+		t.Errorf("topology = %s, want hex", board.GetTopology().Name())
+	}
+
+	if _, _, code := run(t, "", "generate", "-topology", "sphere"); code == 0 {
+		t.Error("generate with an unknown topology should fail")
+	}
+}
+
+func TestSolve_FromStdin(t *testing.T) {
+	// (0,0)の1の周囲で未開放なのは(0,1)だけなので地雷
+	input := "1?\n11\n"
+
+	out, stderr, code := run(t, input, "solve", "-json", "-")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+
+	var result solveOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid json %q: %v", out, err)
+	}
+	if len(result.MineCells) != 1 || result.MineCells[0] != (game.Position{Row: 0, Col: 1}) {
+		t.Errorf("MineCells = %v, want [{0 1}]", result.MineCells)
+	}
+}
+
+func TestSolve_TextOutput(t *testing.T) {
+	out, _, code := run(t, "1?\n11\n", "solve", "-")
+	if code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	if !strings.Contains(out, "mines (1): 0,1") {
+		t.Errorf("output = %q", out)
+	}
+	if !strings.Contains(out, "1m\n11\n") {
+		t.Errorf("annotated board missing in %q", out)
+	}
+}
+
+func TestSolve_Neighborhood(t *testing.T) {
+	// 上下左右だけを数えるので、(1,0)の空白は(0,1)に触れていない
+	out, _, code := run(t, "# neighborhood: cross\n1?\n.?\n", "solve", "-")
+	if code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+	if !strings.Contains(out, "# neighborhood: cross\n1m\n.s\n") {
+		t.Errorf("annotated board missing in %q", out)
+	}
+}
+
+func TestSolve_WrongFlag(t *testing.T) {
+	// (0,0)の旗は右の1と矛盾する
+	out, stderr, code := run(t, "F1*1.\n", "solve", "-json", "-flags", "distrust", "-")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+
+	var result solveOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid json %q: %v", out, err)
+	}
+	if len(result.Contradictions) != 1 || result.Contradictions[0].Position != (game.Position{Row: 0, Col: 0}) {
+		t.Errorf("Contradictions = %+v, want one at {0 0}", result.Contradictions)
+	}
+	if len(result.SafeCells) != 0 {
+		t.Errorf("SafeCells = %v, the wrong flag should not make the mine safe", result.SafeCells)
+	}
+}
+
+func TestSolve_MissingFile(t *testing.T) {
+	if _, _, code := run(t, "", "solve"); code == 0 {
+		t.Error("solve without a file should fail")
+	}
+}
+
+func TestRender(t *testing.T) {
+	// (0,1)は地雷と確定するので、-deduce で赤い枠を描く
+	out, stderr, code := run(t, "1?\n11\n", "render", "-o", "-", "-format", "svg", "-deduce", "-")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+	if !strings.HasPrefix(out, "<svg") || !strings.Contains(out, `stroke="#cc2222"`) {
+		t.Errorf("output should be an SVG with the deduced mine:\n%s", out)
+	}
+
+	path := filepath.Join(t.TempDir(), "board.png")
+	if _, stderr, code := run(t, "1?\n11\n", "render", "-o", path, "-probabilities", "-"); code != 0 {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Error("the .png output should be a PNG image")
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no output", []string{"render", "-"}},
+		{"stdout without format", []string{"render", "-o", "-", "-"}},
+		{"unknown extension", []string{"render", "-o", "board.gif", "-"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, code := run(t, "1?\n11\n", tt.args...); code == 0 {
+				t.Errorf("%v should fail", tt.args)
+			}
+		})
+	}
+}
+
+func TestBench(t *testing.T) {
+	out, stderr, code := run(t, "", "bench", "-n", "5", "-seed", "1")
+	if code != 0 {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+	if !strings.Contains(out, "games: 5") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestBench_InvalidRules(t *testing.T) {
+	// ほかのコマンドと同じく、組み合わせられないルールはエラーにする
+	tests := [][]string{
+		{"-topology", "triangle", "-neighborhood", "knight"},
+		{"-topology", "sphere"},
+		{"-neighborhood", "bishop"},
+		{"-max-cell-mines", "6"},
+	}
+	for _, flags := range tests {
+		args := append([]string{"bench", "-n", "5", "-seed", "1"}, flags...)
+		if _, _, code := run(t, "", args...); code == 0 {
+			t.Errorf("%v should fail", args)
+		}
+	}
+}
+
+func TestGameFlags_Resolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   gameFlags
+		want    game.Difficulty
+		wantErr bool
+	}{
+		{"named", gameFlags{difficulty: "expert", mines: -1}, game.Expert, false},
+		{
+			"custom with default density",
+			gameFlags{width: 10, height: 10, mines: -1},
+			game.Difficulty{Name: "custom", Width: 10, Height: 10, Mines: 15},
+			false,
+		},
+		{"custom without height", gameFlags{width: 10, mines: -1}, game.Difficulty{}, true},
+		{"unknown difficulty", gameFlags{difficulty: "insane", mines: -1}, game.Difficulty{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.flags.resolve()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	pos, err := parsePosition("3, 7")
+	if err != nil || pos != (game.Position{Row: 3, Col: 7}) {
+		t.Errorf("parsePosition() = %v, %v", pos, err)
+	}
+	for _, bad := range []string{"3", "a,1", "1,b"} {
+		if _, err := parsePosition(bad); err == nil {
+			t.Errorf("parsePosition(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+func runGenerate(e *env, args []string) error {
+	fs := newFlagSet(e, "generate")
+	gf := addGameFlags(fs, game.Beginner.Name)
+	first := fs.String("first", "", "最初にクリックする位置 row,col（省略時は中央）")
+	open := fs.Bool("open", true, "最初のクリックで開いた状態で出力する")
+	hideMines := fs.Bool("hide-mines", false, "未開放の地雷を'?'で出力する")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	difficulty, err := gf.resolve()
+	if err != nil {
+		return err
+	}
+
+	pos := center(difficulty)
+	if *first != "" {
+		pos, err = parsePosition(*first)
+		if err != nil {
+			return err
+		}
+	}
+
+	options, err := gf.options(difficulty)
+	if err != nil {
+		return err
+	}
+	g := game.NewGameWithOptions(difficulty, options)
+	if !g.Board.IsValidPosition(pos) {
+		return fmt.Errorf("first click %d,%d is outside the board", pos.Row, pos.Col)
+	}
+	g.Click(pos)
+	rating := solver.Rate(g.Board, pos)
+	if !*open {
+		for _, row := range g.Board.Cells {
+			for _, cell := range row {
+				cell.IsRevealed = false
+			}
+		}
+	}
+
+	fmt.Fprintf(e.stdout, "# difficulty: %s %dx%d mines: %d seed: %d first: %d,%d\n",
+		difficulty.Name, difficulty.Width, difficulty.Height, g.Board.Mines, g.Seed, pos.Row, pos.Col)
+	fmt.Fprintf(e.stdout, "# rating: %s guesses: %d score: %d\n", rating.Hardest, rating.Guesses, rating.Score())
+	if *hideMines {
+		fmt.Fprint(e.stdout, g.Board.VisibleNotation())
+	} else {
+		fmt.Fprint(e.stdout, g.Board.Notation())
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/mcp"
+)
+
+func runMCP(e *env, args []string) error {
+	fs := newFlagSet(e, "mcp")
+	gf := addGameFlags(fs, game.Beginner.Name)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	difficulty, err := gf.resolve()
+	if err != nil {
+		return err
+	}
+	options, err := gf.options(difficulty)
+	if err != nil {
+		return err
+	}
+	return mcp.NewServer(difficulty, options).Run(e.stdin, e.stdout)
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/r-horie/ai-minesweeper/broadcast"
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/tui"
+)
+
+func runPlay(e *env, args []string) error {
+	fs := newFlagSet(e, "play")
+	gf := addGameFlags(fs, "")
+	configPath := fs.String("config", "", "設定ファイルのパス（省略時はXDG設定ディレクトリ）")
+	assist := fs.String("assist", "", "AIの支援レベル (off / flags / full)")
+	theme := fs.String("theme", "", "テーマ名またはテーマファイルのパス")
+	lang := fs.String("lang", "", "表示言語 (en / ja)")
+	speed := fs.Int("speed", -1, "AIが1マス開くごとの待ち時間（ミリ秒）")
+	flags := fs.String("flags", "", "プレイヤーの旗の扱い (trust / distrust / ignore)")
+	questionMarks := fs.Bool("question-marks", false, "旗の操作で「?」の印も付けられるようにする")
+	lives := fs.Int("lives", 0, "地雷を踏める回数（0なら設定ファイルに従う）")
+	infinite := fs.Bool("infinite", false, "端のない無限盤面でプレイする（地雷を踏むまでに開いたマスの数がスコア）")
+	density := fs.Float64("density", game.DefaultInfiniteDensity, "無限盤面の地雷の密度")
+	broadcastAddr := fs.String("broadcast", "", "ゲームの出来事を観戦者に配信するアドレス（例: 127.0.0.1:7070）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if gf.difficulty != "" {
+		cfg.Difficulty = gf.difficulty
+	}
+	if *assist != "" {
+		cfg.AssistLevel = config.AssistLevel(*assist)
+	}
+	if *theme != "" {
+		cfg.Theme = *theme
+	}
+	if *lang != "" {
+		cfg.Language = *lang
+	}
+	if *speed >= 0 {
+		cfg.AISpeed = *speed
+	}
+	if *flags != "" {
+		cfg.FlagPolicy = *flags
+	}
+	if *questionMarks {
+		cfg.QuestionMarks = true
+	}
+	if *lives > 0 {
+		cfg.Lives = *lives
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if *infinite {
+		if *broadcastAddr != "" {
+			return fmt.Errorf("-broadcast cannot be used with -infinite")
+		}
+		return playInfinite(cfg, gf.seed, *density)
+	}
+
+	gf.difficulty = cfg.Difficulty
+	difficulty, err := gf.resolve()
+	if err != nil {
+		return err
+	}
+
+	if gf.topology == "" {
+		gf.topology = cfg.Topology
+	}
+	if gf.neighborhood == "" {
+		gf.neighborhood = cfg.Neighborhood
+	}
+	if gf.maxCellMines == 0 {
+		gf.maxCellMines = cfg.MaxCellMines
+	}
+	options, err := gf.options(difficulty)
+	if err != nil {
+		return err
+	}
+	options.Lives = cfg.Lives
+	options.QuestionMarks = cfg.QuestionMarks
+	model, err := tui.NewModelWithGame(cfg, game.NewGameWithOptions(difficulty, options))
+	if err != nil {
+		return err
+	}
+	if *broadcastAddr != "" {
+		l, listenErr := net.Listen("tcp", *broadcastAddr)
+		if listenErr != nil {
+			return listenErr
+		}
+		defer l.Close()
+		hub := broadcast.NewHub()
+		go func() { _ = hub.Serve(l) }()
+		model = model.WithBroadcast(hub)
+		fmt.Fprintf(e.stderr, "broadcasting on %s (ai-minesweeper watch %s)\n", l.Addr(), l.Addr())
+	}
+
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	_, err = p.Run()
+	return err
+}
+
+// playInfinite は無限盤面のゲームをTUIでプレイする.
+func playInfinite(cfg config.Config, seed int64, density float64) error {
+	if err := game.CheckInfiniteDensity(density); err != nil {
+		return err
+	}
+	model, err := tui.NewInfiniteModel(cfg, game.NewInfiniteGame(seed, density))
+	if err != nil {
+		return err
+	}
+	_, err = tea.NewProgram(model, tea.WithAltScreen()).Run()
+	return err
+}
+
+func loadConfig(path string) (config.Config, error) {
+	if path == "" {
+		return config.Load()
+	}
+	return config.LoadFile(path)
+}
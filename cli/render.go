@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/r-horie/ai-minesweeper/render"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+func runRender(e *env, args []string) error {
+	fs := newFlagSet(e, "render")
+	output := fs.String("o", "", "書き出す画像のファイル（- なら標準出力）")
+	format := fs.String("format", "", "画像の形式 (svg / png)。省略時は -o の拡張子から決める")
+	mines := fs.Int("mines", -1, "盤面全体の地雷数（省略時は盤面の'*'と'X'の数）")
+	probabilities := fs.Bool("probabilities", false, "未開放のマスにソルバーの地雷確率を重ねる")
+	deduce := fs.Bool("deduce", false, "ソルバーが確定させたマスと、確定できなければ推測で開くマスを枠で囲む")
+	showMines := fs.Bool("show-mines", false, "盤面ファイルの未開放の地雷（'*'）も描く")
+	cellSize := fs.Int("cell", render.DefaultCellSize, "マスの幅（ピクセル）")
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: ai-minesweeper render -o IMAGE [flags] FILE   (FILEが - なら標準入力)")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *output == "" {
+		fs.Usage()
+		return fmt.Errorf("render needs one board file and an output image (-o)")
+	}
+
+	imageFormat, err := outputFormat(*output, *format)
+	if err != nil {
+		return err
+	}
+	board, err := readBoard(e, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *mines >= 0 {
+		board.Mines = *mines
+	}
+
+	opts := render.Options{CellSize: *cellSize, ShowMines: *showMines}
+	s := solver.NewSolver(board)
+	if *probabilities {
+		opts.Probabilities = s.Probabilities()
+	}
+	if *deduce {
+		result := s.Solve()
+		opts.Safe, opts.Mines = result.SafeCells, result.MineCells
+		if !result.CanProgress {
+			if guess, ok := s.Guess(solver.GuessLowestProbability); ok {
+				opts.Guess = &guess.Position
+			}
+		}
+	}
+
+	if *output == "-" {
+		return render.Write(e.stdout, imageFormat, board, opts)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	err = render.Write(f, imageFormat, board, opts)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// outputFormat は -format か、なければ出力ファイルの拡張子から画像の形式を決める.
+func outputFormat(output, format string) (render.Format, error) {
+	if format != "" {
+		return render.ParseFormat(format)
+	}
+	if output == "-" {
+		return "", fmt.Errorf("-format is required when writing the image to stdout")
+	}
+	return render.FormatOf(output)
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/r-horie/ai-minesweeper/server"
+)
+
+func runServe(e *env, args []string) error {
+	fs := newFlagSet(e, "serve")
+	addr := fs.String("addr", "127.0.0.1:8080", "待ち受けるアドレス（既定はこのマシンからの接続だけ）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fmt.Fprintf(e.stderr, "listening on http://%s\n", *addr)
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+type solveOutput struct {
+	SafeCells      []game.Position       `json:"safe_cells"`
+	MineCells      []game.Position       `json:"mine_cells"`
+	CanProgress    bool                  `json:"can_progress"`
+	Contradictions []contradictionOutput `json:"contradictions"`
+}
+
+type contradictionOutput struct {
+	Kind     string          `json:"kind"`
+	Position game.Position   `json:"position"`
+	Flags    []game.Position `json:"flags"`
+}
+
+func runSolve(e *env, args []string) error {
+	fs := newFlagSet(e, "solve")
+	mines := fs.Int("mines", -1, "盤面全体の地雷数（省略時は盤面の'*'と'X'の数）")
+	asJSON := fs.Bool("json", false, "結果をJSONで出力する")
+	flags := fs.String("flags", string(solver.FlagsIgnore), "盤面の旗の扱い (trust / distrust / ignore)")
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: ai-minesweeper solve [flags] FILE   (FILEが - なら標準入力)")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("solve needs exactly one board file")
+	}
+
+	board, err := readBoard(e, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *mines >= 0 {
+		board.Mines = *mines
+	}
+
+	policy, err := solver.ParseFlagPolicy(*flags)
+	if err != nil {
+		return err
+	}
+	result := solver.NewSolverWithOptions(board, solver.Options{FlagPolicy: policy}).Solve()
+	contradictions := solver.Validate(board)
+
+	if *asJSON {
+		out := solveOutput{
+			SafeCells:      result.SafeCells,
+			MineCells:      result.MineCells,
+			CanProgress:    result.CanProgress,
+			Contradictions: []contradictionOutput{},
+		}
+		for _, c := range contradictions {
+			out.Contradictions = append(out.Contradictions,
+				contradictionOutput{Kind: c.Kind.String(), Position: c.Position, Flags: c.Flags})
+		}
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	for _, c := range contradictions {
+		fmt.Fprintf(e.stderr, "warning: %s at %d,%d (flags: %s)\n",
+			c.Kind, c.Position.Row, c.Position.Col, formatPositions(c.Flags))
+	}
+
+	fmt.Fprintf(e.stdout, "safe (%d): %s\n", len(result.SafeCells), formatPositions(result.SafeCells))
+	fmt.Fprintf(e.stdout, "mines (%d): %s\n", len(result.MineCells), formatPositions(result.MineCells))
+	fmt.Fprintln(e.stdout)
+	fmt.Fprint(e.stdout, annotate(board, result))
+	return nil
+}
+
+func readBoard(e *env, path string) (*game.Board, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(e.stdin)
+	} else {
+		data, err = os.ReadFile(path) //nolint:gosec // ユーザーが指定した盤面ファイルを読む
+	}
+	if err != nil {
+		return nil, err
+	}
+	return game.ParseBoard(string(data))
+}
+
+func formatPositions(positions []game.Position) string {
+	if len(positions) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(positions))
+	for i, p := range positions {
+		parts[i] = fmt.Sprintf("%d,%d", p.Row, p.Col)
+	}
+	return strings.Join(parts, " ")
+}
+
+// annotate はプレイヤーに見える盤面に、安全と判明したマスを's'、地雷と判明したマスを'm'で書き込む.
+// トポロジーなどのルールを指定するコメント行はそのまま残す.
+func annotate(board *game.Board, result solver.SolverResult) string {
+	lines := strings.Split(strings.TrimRight(board.VisibleNotation(), "\n"), "\n")
+	header := len(lines) - board.Height
+	grid := make([][]byte, len(lines))
+	for i, line := range lines {
+		grid[i] = []byte(line)
+	}
+	for _, p := range result.SafeCells {
+		grid[header+p.Row][p.Col] = 's'
+	}
+	for _, p := range result.MineCells {
+		grid[header+p.Row][p.Col] = 'm'
+	}
+
+	var sb strings.Builder
+	for _, row := range grid {
+		sb.Write(row)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+
+	"github.com/r-horie/ai-minesweeper/sshserver"
+)
+
+func runSSH(e *env, args []string) error {
+	fs := newFlagSet(e, "ssh")
+	addr := fs.String("addr", "127.0.0.1:2222", "待ち受けるアドレス（既定はこのマシンからの接続だけ）")
+	hostKey := fs.String("host-key", ".ssh/ai_minesweeper_ed25519", "ホスト鍵のファイル（なければ作成する）")
+	lobby := fs.Bool("lobby", false, "接続しているプレイヤーの一覧と進み具合を全員の画面に出す")
+	race := fs.String("race", "", "全員が同じ盤面で競うレースにする。勝者の決め方 (first / guesses)")
+	seed := fs.Int64("seed", 0, "レースの最初のラウンドのシード（0ならラウンドごとにランダム）")
+	coop := fs.Bool("coop", false, "接続しているプレイヤー全員で1つの盤面を一緒に遊ぶ")
+	configPath := fs.String("config", "", "設定ファイルのパス（省略時はXDG設定ディレクトリ）")
+	difficulty := fs.String("difficulty", "", "難易度 (beginner / intermediate / expert)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *difficulty != "" {
+		cfg.Difficulty = *difficulty
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if countTrue(*lobby, *race != "", *coop) > 1 {
+		return fmt.Errorf("-lobby, -race and -coop cannot be combined")
+	}
+	var rule sshserver.RaceRule
+	if *race != "" {
+		if rule, err = sshserver.ParseRaceRule(*race); err != nil {
+			return err
+		}
+	}
+
+	srv, err := sshserver.New(sshserver.Options{
+		Addr:        *addr,
+		HostKeyPath: *hostKey,
+		Config:      cfg,
+		Lobby:       *lobby,
+		Race:        rule,
+		Seed:        *seed,
+		Coop:        *coop,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	fmt.Fprintf(e.stderr, "listening on ssh://%s\n", *addr)
+
+	select {
+	case serveErr := <-errc:
+		return serveErr
+	case <-ctx.Done():
+	}
+	// 接続中のプレイヤーには少しだけ猶予を与えてから切断する
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func countTrue(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
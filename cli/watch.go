@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/r-horie/ai-minesweeper/broadcast"
+	"github.com/r-horie/ai-minesweeper/tui"
+)
+
+func runWatch(e *env, args []string) error {
+	fs := newFlagSet(e, "watch")
+	configPath := fs.String("config", "", "設定ファイルのパス（省略時はXDG設定ディレクトリ）")
+	theme := fs.String("theme", "", "テーマ名またはテーマファイルのパス")
+	lang := fs.String("lang", "", "表示言語 (en / ja)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: ai-minesweeper watch [flags] ADDRESS")
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *theme != "" {
+		cfg.Theme = *theme
+	}
+	if *lang != "" {
+		cfg.Language = *lang
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	conn, err := net.Dial("tcp", fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	events := make(chan broadcast.Event)
+	go func() { _ = broadcast.Receive(conn, events) }()
+
+	model, err := tui.NewSpectatorModel(cfg, events)
+	if err != nil {
+		return err
+	}
+	_, err = tea.NewProgram(model, tea.WithAltScreen()).Run()
+	return err
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/r-horie/ai-minesweeper/web"
+)
+
+func runWeb(e *env, args []string) error {
+	fs := newFlagSet(e, "web")
+	addr := fs.String("addr", "127.0.0.1:8000", "待ち受けるアドレス（既定はこのマシンからの接続だけ）")
+	dir := fs.String("dir", "", "組み込みのファイルの代わりに配信するディレクトリ（フロントエンドの開発用）")
+	fs.Usage = func() {
+		fmt.Fprintln(e.stderr, "Usage: ai-minesweeper web [flags]")
+		fmt.Fprintln(e.stderr, "ページが使う main.wasm と wasm_exec.js はバイナリに組み込まれるので、")
+		fmt.Fprintln(e.stderr, "リポジトリで make build（または make wasm の後に go build / go install）でビルドしてください。")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           web.Handler(*dir),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fmt.Fprintf(e.stderr, "open http://%s in your browser\n", *addr)
+	return srv.ListenAndServe()
+}
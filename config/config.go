@@ -0,0 +1,290 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/i18n"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+// AppName は設定ディレクトリ名に使うアプリケーション名.
+const AppName = "ai-minesweeper"
+
+// AssistLevel はAIがどこまで手を出すかの段階.
+type AssistLevel string
+
+const (
+	// AssistOff はAIを使わない.
+	AssistOff AssistLevel = "off"
+	// AssistFlags は確定した地雷に旗を立てるだけ.
+	AssistFlags AssistLevel = "flags"
+	// AssistFull は確定した地雷に旗を立て、安全なマスも開く.
+	AssistFull AssistLevel = "full"
+)
+
+// Action はキーに割り当てられる操作.
+type Action string
+
+const (
+	ActionUp           Action = "up"
+	ActionDown         Action = "down"
+	ActionLeft         Action = "left"
+	ActionRight        Action = "right"
+	ActionReveal       Action = "reveal"
+	ActionFlag         Action = "flag"
+	ActionNewGame      Action = "new_game"
+	ActionTheme        Action = "theme"
+	ActionBeginner     Action = "beginner"
+	ActionIntermediate Action = "intermediate"
+	ActionExpert       Action = "expert"
+	ActionAuto         Action = "auto"
+	ActionSnapshot     Action = "snapshot"
+	ActionHelp         Action = "help"
+	ActionQuit         Action = "quit"
+)
+
+// Actions はすべての操作（ヘルプの表示順）.
+var Actions = []Action{
+	ActionUp,
+	ActionDown,
+	ActionLeft,
+	ActionRight,
+	ActionReveal,
+	ActionFlag,
+	ActionNewGame,
+	ActionBeginner,
+	ActionIntermediate,
+	ActionExpert,
+	ActionTheme,
+	ActionAuto,
+	ActionSnapshot,
+	ActionHelp,
+	ActionQuit,
+}
+
+// Config はユーザー設定.
+type Config struct {
+	// Difficulty は起動時の難易度（beginner / intermediate / expert）.
+	Difficulty string `json:"difficulty"`
+	// Theme は組み込みテーマ名またはテーマファイルのパス。空なら自動選択.
+	Theme string `json:"theme"`
+	// AISpeed はAIが1マス開くごとの待ち時間（ミリ秒）.
+	AISpeed     int         `json:"ai_speed_ms"`
+	AssistLevel AssistLevel `json:"assist_level"`
+	// GuessPolicy は行き詰まったときにAIが勧める（自動プレイでは開く）マスの選び方.
+	GuessPolicy string `json:"guess_policy"`
+	// FlagPolicy はプレイヤーが立てた旗をAIがどう扱うか（trust / distrust / ignore）.
+	FlagPolicy string `json:"flag_policy"`
+	// QuestionMarks が true なら、旗の操作で 旗 → ? → 無し と「?」の印も付けられる.
+	QuestionMarks bool `json:"question_marks"`
+	// Topology は盤面のマスのつながり方（square / torus / hex / triangle）。空なら正方形.
+	Topology string `json:"topology"`
+	// Neighborhood は数字が数えるマスの範囲（standard / knight / cross / radius2）。空なら standard.
+	Neighborhood string `json:"neighborhood"`
+	// MaxCellMines は1マスに置ける地雷の最大数（0〜5）。2以上なら数字が8を超えることがある.
+	MaxCellMines int `json:"max_cell_mines"`
+	// Lives は地雷を踏める回数。0か1なら最初の地雷でゲームオーバー.
+	Lives int `json:"lives"`
+	// SnapshotDir は盤面の画像（SVGとPNG）を保存するディレクトリ。空なら現在のディレクトリ.
+	SnapshotDir string `json:"snapshot_dir"`
+	// Language は表示言語（en / ja）。空ならLANGなどの環境変数から決める.
+	Language string `json:"language"`
+	// KeyBindings は操作ごとのキー。ファイルで指定した操作だけが上書きされる.
+	KeyBindings map[Action][]string `json:"key_bindings"`
+}
+
+// Default は標準の設定を返す.
+func Default() Config {
+	return Config{
+		Difficulty:  "beginner",
+		Theme:       "",
+		AISpeed:     200,
+		AssistLevel: AssistFull,
+		GuessPolicy: string(solver.GuessLowestProbability),
+		FlagPolicy:  string(solver.FlagsIgnore),
+		KeyBindings: DefaultKeyBindings(),
+	}
+}
+
+// DefaultKeyBindings は標準のキー割り当てを返す.
+func DefaultKeyBindings() map[Action][]string {
+	return map[Action][]string{
+		ActionUp:           {"up", "k"},
+		ActionDown:         {"down", "j"},
+		ActionLeft:         {"left", "h"},
+		ActionRight:        {"right", "l"},
+		ActionReveal:       {" ", "space", "enter"},
+		ActionFlag:         {"f"},
+		ActionNewGame:      {"r"},
+		ActionTheme:        {"t"},
+		ActionBeginner:     {"1"},
+		ActionIntermediate: {"2"},
+		ActionExpert:       {"3"},
+		ActionAuto:         {"a"},
+		ActionSnapshot:     {"p"},
+		ActionHelp:         {"?"},
+		ActionQuit:         {"q", "ctrl+c", "ctrl+q"},
+	}
+}
+
+// Path は設定ファイルのパスを返す.
+// $XDG_CONFIG_HOME が設定されていればそれを優先し、なければOS標準の設定ディレクトリを使う.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		dir, err = os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, AppName, "config.json"), nil
+}
+
+// Load は標準の場所から設定を読み込む。ファイルがなければ標準の設定を返す.
+func Load() (Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Default(), nil //nolint:nilerr // 設定ディレクトリがない環境では標準設定で動かす
+	}
+	return LoadFile(path)
+}
+
+// LoadFile は指定されたファイルから設定を読み込む。ファイルがなければ標準の設定を返す.
+func LoadFile(path string) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path) //nolint:gosec // ユーザーの設定ファイルを読む
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	// 割り当ては設定した操作だけを読み、標準の割り当てには後で重ねる
+	cfg.KeyBindings = nil
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Default(), fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	cfg.KeyBindings = mergeKeyBindings(cfg.KeyBindings)
+	if err := cfg.Validate(); err != nil {
+		return Default(), fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate は設定値を検証.
+func (c Config) Validate() error {
+	if c.AISpeed < 0 {
+		return fmt.Errorf("ai_speed_ms must not be negative: %d", c.AISpeed)
+	}
+
+	if _, err := game.ParseDifficulty(c.Difficulty); err != nil {
+		return err
+	}
+
+	if c.Language != "" {
+		if _, ok := i18n.Parse(c.Language); !ok {
+			return fmt.Errorf("unsupported language %q", c.Language)
+		}
+	}
+
+	if _, err := solver.ParseGuessPolicy(c.GuessPolicy); err != nil {
+		return err
+	}
+
+	if _, err := solver.ParseFlagPolicy(c.FlagPolicy); err != nil {
+		return err
+	}
+
+	if err := c.validateBoardRules(); err != nil {
+		return err
+	}
+
+	if c.Lives < 0 {
+		return fmt.Errorf("lives must not be negative: %d", c.Lives)
+	}
+
+	switch c.AssistLevel {
+	case AssistOff, AssistFlags, AssistFull:
+	default:
+		return fmt.Errorf("unknown assist_level %q", c.AssistLevel)
+	}
+
+	return c.validateKeyBindings()
+}
+
+// validateKeyBindings は未知の操作、2つの操作に割り当てたキー、キーのない終了とヘルプを拒否する.
+func (c Config) validateKeyBindings() error {
+	owner := map[string]Action{}
+	for action, keys := range c.KeyBindings {
+		if !isKnownAction(action) {
+			return fmt.Errorf("unknown action %q in key_bindings", action)
+		}
+		for _, key := range keys {
+			if other, ok := owner[key]; ok && other != action {
+				return fmt.Errorf("key %q is bound to both %q and %q", key, other, action)
+			}
+			owner[key] = action
+		}
+	}
+	// 終了とヘルプの操作がなくなると、抜け出す方法も割り当てを調べる方法もなくなる
+	for _, action := range []Action{ActionQuit, ActionHelp} {
+		if len(c.KeyBindings[action]) == 0 {
+			return fmt.Errorf("action %q needs at least one key in key_bindings", action)
+		}
+	}
+	return nil
+}
+
+// mergeKeyBindings は標準のキー割り当てに設定ファイルの割り当てを重ねる.
+// 設定した操作は割り当てを丸ごと置き換え、設定したキーはほかの操作の標準の割り当てから外す.
+func mergeKeyBindings(overrides map[Action][]string) map[Action][]string {
+	taken := map[string]bool{}
+	for _, keys := range overrides {
+		for _, key := range keys {
+			taken[key] = true
+		}
+	}
+	merged := DefaultKeyBindings()
+	for action, keys := range merged {
+		merged[action] = slices.DeleteFunc(keys, func(key string) bool { return taken[key] })
+	}
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// validateBoardRules はマスのつながり方と数字が数える範囲を組み合わせられるか、
+// 1マスの地雷の最大数が範囲内かを検証.
+func (c Config) validateBoardRules() error {
+	if err := game.CheckMaxCellMines(c.MaxCellMines); err != nil {
+		return err
+	}
+	topology, err := game.ParseTopology(c.Topology)
+	if err != nil {
+		return err
+	}
+	neighborhood, err := game.ParseNeighborhood(c.Neighborhood)
+	if err != nil {
+		return err
+	}
+	return neighborhood.Check(topology)
+}
+
+func isKnownAction(action Action) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
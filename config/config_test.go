@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefault_IsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Errorf("Default().Validate() error = %v", err)
+	}
+}
+
+func TestLoadFile_Missing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.AISpeed != Default().AISpeed {
+		t.Errorf("AISpeed = %d, want %d", cfg.AISpeed, Default().AISpeed)
+	}
+}
+
+func TestLoadFile_OverridesOnlyGivenBindings(t *testing.T) {
+	path := writeConfig(t, `{
+		"difficulty": "expert",
+		"theme": "colorblind",
+		"ai_speed_ms": 50,
+		"assist_level": "flags",
+		"question_marks": true,
+		"key_bindings": {"flag": ["m"]}
+	}`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	if cfg.Difficulty != "expert" {
+		t.Errorf("Difficulty = %q, want expert", cfg.Difficulty)
+	}
+	if cfg.Theme != "colorblind" {
+		t.Errorf("Theme = %q, want colorblind", cfg.Theme)
+	}
+	if cfg.AISpeed != 50 {
+		t.Errorf("AISpeed = %d, want 50", cfg.AISpeed)
+	}
+	if cfg.AssistLevel != AssistFlags {
+		t.Errorf("AssistLevel = %q, want %q", cfg.AssistLevel, AssistFlags)
+	}
+	if !cfg.QuestionMarks {
+		t.Error("QuestionMarks = false, want true")
+	}
+	if got := cfg.KeyBindings[ActionFlag]; len(got) != 1 || got[0] != "m" {
+		t.Errorf("KeyBindings[flag] = %v, want [m]", got)
+	}
+	// 指定していない操作は標準のまま
+	if got := cfg.KeyBindings[ActionReveal]; len(got) == 0 {
+		t.Error("KeyBindings[reveal] should keep the default")
+	}
+}
+
+func TestLoadFile_TakesDefaultKey(t *testing.T) {
+	// ほかの操作の標準のキーに割り当てると、そのキーは標準の割り当てから外れる
+	cfg, err := LoadFile(writeConfig(t, `{"key_bindings": {"flag": ["q"]}}`))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if got := cfg.KeyBindings[ActionFlag]; len(got) != 1 || got[0] != "q" {
+		t.Errorf("KeyBindings[flag] = %v, want [q]", got)
+	}
+	if got := cfg.KeyBindings[ActionQuit]; len(got) != 2 || slices.Contains(got, "q") {
+		t.Errorf("KeyBindings[quit] = %v, want the defaults without q", got)
+	}
+	// 標準の割り当ては変わらない
+	if got := DefaultKeyBindings()[ActionQuit]; !slices.Contains(got, "q") {
+		t.Errorf("DefaultKeyBindings()[quit] = %v, want it to keep q", got)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"broken json", `{`},
+		{"negative speed", `{"ai_speed_ms": -1}`},
+		{"unknown assist level", `{"assist_level": "magic"}`},
+		{"unknown difficulty", `{"difficulty": "nightmare"}`},
+		{"unsupported language", `{"language": "xx"}`},
+		{"unknown guess policy", `{"guess_policy": "coin"}`},
+		{"unknown flag policy", `{"flag_policy": "blind"}`},
+		{"unknown topology", `{"topology": "sphere"}`},
+		{"unknown neighborhood", `{"neighborhood": "bishop"}`},
+		{"knight on hex", `{"topology": "hex", "neighborhood": "knight"}`},
+		{"too many cell mines", `{"max_cell_mines": 6}`},
+		{"negative lives", `{"lives": -1}`},
+		{"unknown action", `{"key_bindings": {"jump": ["x"]}}`},
+		{"duplicated key", `{"key_bindings": {"flag": ["r"], "new_game": ["r"]}}`},
+		{"no quit key", `{"key_bindings": {"quit": []}}`},
+		{"no help key", `{"key_bindings": {"help": []}}`},
+		{"quit keys taken", `{"key_bindings": {"flag": ["q", "ctrl+c", "ctrl+q"]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadFile(writeConfig(t, tt.content)); err == nil {
+				t.Error("LoadFile() should fail")
+			}
+		})
+	}
+}
+
+func TestPath_UsesXDGConfigHome(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	path, err := Path()
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	want := filepath.Join(dir, AppName, "config.json")
+	if path != want {
+		t.Errorf("Path() = %q, want %q", path, want)
+	}
+}
@@ -5,8 +5,8 @@ import (
 )
 
 type Position struct {
-	Row int
-	Col int
+	Row int `json:"row"`
+	Col int `json:"col"`
 }
 
 type Board struct {
@@ -14,9 +14,20 @@ type Board struct {
 	Height int
 	Mines  int
 	Cells  [][]*Cell
+	// Topology はマスのつながり方。nilなら正方形のマス目.
+	Topology Topology
+	// Neighborhood は数字が数えるマスの範囲。空なら standard.
+	Neighborhood Neighborhood
+	// MaxCellMines は1マスに置ける地雷の最大数。0なら1.
+	MaxCellMines int
 }
 
 func NewBoard(width, height, mines int) *Board {
+	return NewBoardWithTopology(width, height, mines, nil)
+}
+
+// NewBoardWithTopology は指定したマスのつながり方の盤面を作成する.
+func NewBoardWithTopology(width, height, mines int, topology Topology) *Board {
 	if mines > width*height {
 		mines = width * height
 	}
@@ -30,29 +41,50 @@ func NewBoard(width, height, mines int) *Board {
 	}
 
 	return &Board{
-		Width:  width,
-		Height: height,
-		Mines:  mines,
-		Cells:  cells,
+		Width:    width,
+		Height:   height,
+		Mines:    mines,
+		Cells:    cells,
+		Topology: topology,
+	}
+}
+
+// CellCapacity は1マスに置ける地雷の最大数.
+func (b *Board) CellCapacity() int {
+	return max(b.MaxCellMines, 1)
+}
+
+// GetTopology は盤面のマスのつながり方を返す。未設定なら正方形のマス目.
+func (b *Board) GetTopology() Topology {
+	if b.Topology == nil {
+		return SquareTopology
 	}
+	return b.Topology
 }
 
 func (b *Board) Initialize(firstClick Position) {
+	b.InitializeWithRand(firstClick, rand.New(rand.NewSource(rand.Int63()))) //nolint:gosec // 地雷配置にはmath/randで十分
+}
+
+// InitializeWithRand は指定された乱数で地雷を配置する。同じシードなら同じ盤面になる.
+// 最初のクリックとその隣接マスには地雷を置かない。置ける場所が足りなければ地雷を減らす.
+// MaxCellMines が2以上なら、すでに地雷のあるマスにも上限まで重ねて置く.
+func (b *Board) InitializeWithRand(firstClick Position, rng *rand.Rand) {
+	safeZone := b.safeZone(firstClick)
+	capacity := b.CellCapacity()
+	b.Mines = min(b.Mines, (b.Width*b.Height-len(safeZone))*capacity)
+
 	mineCount := 0
 	for mineCount < b.Mines {
-		row := rand.Intn(b.Height) //nolint:gosec // 地雷配置にはmath/randで十分
-		col := rand.Intn(b.Width)  //nolint:gosec // 地雷配置にはmath/randで十分
+		row := rng.Intn(b.Height)
+		col := rng.Intn(b.Width)
 
-		if row == firstClick.Row && col == firstClick.Col {
+		if safeZone[Position{row, col}] {
 			continue
 		}
 
-		if abs(row-firstClick.Row) <= 1 && abs(col-firstClick.Col) <= 1 {
-			continue
-		}
-
-		if !b.Cells[row][col].IsMine {
-			b.Cells[row][col].SetMine()
+		if b.Cells[row][col].MineCount() < capacity {
+			b.Cells[row][col].AddMine()
 			mineCount++
 		}
 	}
@@ -67,6 +99,47 @@ func (b *Board) Initialize(firstClick Position) {
 	}
 }
 
+// safeZone は最初のクリックで地雷を置かないマス。クリックしたマスとその周囲.
+func (b *Board) safeZone(firstClick Position) map[Position]bool {
+	zone := map[Position]bool{firstClick: true}
+	for _, adj := range b.GetAdjacentPositions(firstClick) {
+		zone[adj] = true
+	}
+	return zone
+}
+
+// SafeZoneSize は最初のクリックで地雷を置かないマスの数の、クリックする位置による最大値.
+func (b *Board) SafeZoneSize() int {
+	size := 0
+	for i := 0; i < b.Height; i++ {
+		for j := 0; j < b.Width; j++ {
+			size = max(size, len(b.safeZone(Position{i, j})))
+		}
+	}
+	return size
+}
+
+// Clone は盤面の複製を作成する。仮の手を試すときに元の盤面を変えないために使う.
+func (b *Board) Clone() *Board {
+	clone := &Board{
+		Width:        b.Width,
+		Height:       b.Height,
+		Mines:        b.Mines,
+		Cells:        make([][]*Cell, b.Height),
+		Topology:     b.Topology,
+		Neighborhood: b.Neighborhood,
+		MaxCellMines: b.MaxCellMines,
+	}
+	for i, row := range b.Cells {
+		clone.Cells[i] = make([]*Cell, len(row))
+		for j, cell := range row {
+			c := *cell
+			clone.Cells[i][j] = &c
+		}
+	}
+	return clone
+}
+
 func (b *Board) GetCell(pos Position) *Cell {
 	if b.IsValidPosition(pos) {
 		return b.Cells[pos.Row][pos.Col]
@@ -78,39 +151,46 @@ func (b *Board) IsValidPosition(pos Position) bool {
 	return pos.Row >= 0 && pos.Row < b.Height && pos.Col >= 0 && pos.Col < b.Width
 }
 
+// GetAdjacentPositions は数字が数えるマスを返す。盤面もソルバーもこの範囲で地雷を数える.
+// トポロジーと組み合わせられない数え方のときは、トポロジーの隣接マスを使う.
 func (b *Board) GetAdjacentPositions(pos Position) []Position {
-	positions := []Position{}
-	for dr := -1; dr <= 1; dr++ {
-		for dc := -1; dc <= 1; dc++ {
-			if dr == 0 && dc == 0 {
-				continue
-			}
-			newPos := Position{pos.Row + dr, pos.Col + dc}
-			if b.IsValidPosition(newPos) {
-				positions = append(positions, newPos)
-			}
+	topology := b.GetTopology()
+	if offsets := b.Neighborhood.offsets(); offsets != nil {
+		if t, ok := topology.(latticeTopology); ok {
+			return t.latticeNeighbors(pos, b.Width, b.Height, offsets)
 		}
 	}
-	return positions
+	return topology.Neighbors(pos, b.Width, b.Height)
 }
 
 func (b *Board) countAdjacentMines(pos Position) int {
 	count := 0
 	for _, adjPos := range b.GetAdjacentPositions(pos) {
-		if b.Cells[adjPos.Row][adjPos.Col].IsMine {
-			count++
-		}
+		count += b.Cells[adjPos.Row][adjPos.Col].MineCount()
 	}
 	return count
 }
 
 func (b *Board) RevealCell(pos Position) bool {
+	return b.reveal(pos, nil)
+}
+
+// Reveal はRevealCellと同じくマスを開き、連鎖して開いたマスも含めて新たに開いたマスの一覧を返す.
+func (b *Board) Reveal(pos Position) (hitMine bool, revealed []Position) {
+	hitMine = b.reveal(pos, &revealed)
+	return hitMine, revealed
+}
+
+func (b *Board) reveal(pos Position, revealed *[]Position) bool {
 	cell := b.GetCell(pos)
-	if cell == nil || cell.IsRevealed || cell.IsFlagged {
+	if cell == nil || cell.IsRevealed || cell.IsFlagged || cell.IsMarked {
 		return false
 	}
 
 	cell.Reveal()
+	if revealed != nil {
+		*revealed = append(*revealed, pos)
+	}
 
 	if cell.IsMine {
 		return true
@@ -118,7 +198,7 @@ func (b *Board) RevealCell(pos Position) bool {
 
 	if cell.Adjacent == 0 {
 		for _, adjPos := range b.GetAdjacentPositions(pos) {
-			b.RevealCell(adjPos)
+			b.reveal(adjPos, revealed)
 		}
 	}
 
@@ -438,6 +438,38 @@ func TestBoard_RevealCell(t *testing.T) {
 	}
 }
 
+func TestBoard_Reveal(t *testing.T) {
+	board, err := ParseBoard("????*\n?????\n?????\n?????\n????*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hitMine, revealed := board.Reveal(Position{Row: 2, Col: 0})
+	if hitMine {
+		t.Fatal("Reveal() hit a mine")
+	}
+
+	// 連鎖して開いたマスもすべて一度ずつ返す
+	seen := map[Position]bool{}
+	for _, pos := range revealed {
+		if seen[pos] {
+			t.Errorf("position %v returned twice", pos)
+		}
+		seen[pos] = true
+		if !board.GetCell(pos).IsRevealed {
+			t.Errorf("position %v returned but not revealed", pos)
+		}
+	}
+	want := board.Width*board.Height - board.Mines - board.CountUnrevealedSafeCells()
+	if len(revealed) != want {
+		t.Errorf("Reveal() returned %d positions, want %d", len(revealed), want)
+	}
+
+	if _, again := board.Reveal(Position{Row: 2, Col: 0}); len(again) != 0 {
+		t.Errorf("revealing an open cell returned %v", again)
+	}
+}
+
 func TestBoard_CountUnrevealedSafeCells(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -1,30 +1,90 @@
 package game
 
 type Cell struct {
-	IsMine     bool
+	IsMine bool
+	// Mines はマスにある地雷の数。1マスに複数の地雷を置くルールでだけ2以上になる.
+	// 0のままでもIsMineなら1個とみなす（MineCountを使う）.
+	Mines      int
 	IsRevealed bool
-	IsFlagged  bool
-	Adjacent   int
+	// IsFlagged はプレイヤーが立てた旗。AIの推論には使わない.
+	IsFlagged bool
+	// Flags はプレイヤーが見積もったこのマスの地雷の数。0のままでもIsFlaggedなら1本とみなす.
+	Flags int
+	// IsMarked はAIが推論で地雷と確定させた印。プレイヤーの旗とは別に管理する.
+	IsMarked bool
+	// Marks はAIが確定させたこのマスの地雷の数。0のままでもIsMarkedなら1個とみなす.
+	Marks int
+	// IsQuestioned はプレイヤーが付けた「?」の印。地雷とは数えず、開くこともできる.
+	IsQuestioned bool
+	Adjacent     int
 }
 
 func NewCell() *Cell {
 	return &Cell{
-		IsMine:     false,
-		IsRevealed: false,
-		IsFlagged:  false,
-		Adjacent:   0,
+		IsMine:       false,
+		Mines:        0,
+		IsRevealed:   false,
+		IsFlagged:    false,
+		Flags:        0,
+		IsMarked:     false,
+		Marks:        0,
+		IsQuestioned: false,
+		Adjacent:     0,
 	}
 }
 
 func (c *Cell) Reveal() {
-	if !c.IsFlagged {
+	if !c.IsFlagged && !c.IsMarked {
 		c.IsRevealed = true
+		c.IsQuestioned = false
 	}
 }
 
 func (c *Cell) ToggleFlag() {
-	if !c.IsRevealed {
-		c.IsFlagged = !c.IsFlagged
+	c.CycleFlag(false)
+}
+
+// CycleFlag はプレイヤーの印を 無し → 旗 → ? → 無し の順に切り替える.
+// questionMarks が false なら「?」を飛ばし、旗の立て外しだけになる.
+func (c *Cell) CycleFlag(questionMarks bool) {
+	c.CycleFlags(1, questionMarks)
+}
+
+// CycleFlags は旗の数を 無し → 1 → … → maxFlags → ? → 無し の順に切り替える.
+// 1マスに複数の地雷を置くルールで、プレイヤーが地雷の数を見積もるのに使う.
+func (c *Cell) CycleFlags(maxFlags int, questionMarks bool) {
+	if c.IsRevealed {
+		return
+	}
+
+	switch {
+	case c.IsFlagged && c.FlagCount() < maxFlags:
+		c.Flags = c.FlagCount() + 1
+	case c.IsFlagged:
+		c.IsFlagged = false
+		c.Flags = 0
+		c.IsQuestioned = questionMarks
+	case c.IsQuestioned:
+		c.IsQuestioned = false
+	default:
+		c.IsFlagged = true
+	}
+}
+
+// Mark はAIが地雷と確定させた印を付ける.
+func (c *Cell) Mark() {
+	c.MarkMines(1)
+}
+
+// MarkMines はAIが地雷の数まで確定させた印を付ける。Marksは2個以上のときだけ記録する.
+func (c *Cell) MarkMines(count int) {
+	if c.IsRevealed || count <= 0 {
+		return
+	}
+	c.IsMarked = true
+	c.Marks = 0
+	if count > 1 {
+		c.Marks = count
 	}
 }
 
@@ -32,6 +92,37 @@ func (c *Cell) SetMine() {
 	c.IsMine = true
 }
 
+// AddMine はマスに地雷を1個加える。1個目はSetMineと同じで、Minesは2個目から数える.
+func (c *Cell) AddMine() {
+	if c.IsMine {
+		c.Mines = c.MineCount() + 1
+	}
+	c.IsMine = true
+}
+
+// MineCount はマスにある地雷の数.
+func (c *Cell) MineCount() int {
+	return countOf(c.IsMine, c.Mines)
+}
+
+// FlagCount はプレイヤーの旗の数.
+func (c *Cell) FlagCount() int {
+	return countOf(c.IsFlagged, c.Flags)
+}
+
+// MarkCount はAIの印が示す地雷の数.
+func (c *Cell) MarkCount() int {
+	return countOf(c.IsMarked, c.Marks)
+}
+
 func (c *Cell) SetAdjacent(count int) {
 	c.Adjacent = count
 }
+
+// countOf は有無のフラグと数から数を求める。数を設定していなければ1とみなす.
+func countOf(present bool, n int) int {
+	if !present {
+		return 0
+	}
+	return max(n, 1)
+}
@@ -1,6 +1,8 @@
 package game
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -104,6 +106,99 @@ func TestCell_ToggleFlag(t *testing.T) {
 	}
 }
 
+func TestCell_CycleFlag(t *testing.T) {
+	tests := []struct {
+		name          string
+		questionMarks bool
+		flagged       bool
+		questioned    bool
+		wantFlag      bool
+		wantQuestion  bool
+	}{
+		{"none to flag", true, false, false, true, false},
+		{"flag to question", true, true, false, false, true},
+		{"question to none", true, false, true, false, false},
+		{"flag to none without question marks", false, true, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell := NewCell()
+			cell.IsFlagged = tt.flagged
+			cell.IsQuestioned = tt.questioned
+
+			cell.CycleFlag(tt.questionMarks)
+
+			if cell.IsFlagged != tt.wantFlag || cell.IsQuestioned != tt.wantQuestion {
+				t.Errorf("CycleFlag() = flag %v question %v, want flag %v question %v",
+					cell.IsFlagged, cell.IsQuestioned, tt.wantFlag, tt.wantQuestion)
+			}
+		})
+	}
+}
+
+func TestCell_CycleFlags(t *testing.T) {
+	// 地雷を3個まで置けるルールでは、旗の数を1→2→3と増やしてから「?」、無しに戻る
+	cell := NewCell()
+	var got []string
+	for range 5 {
+		cell.CycleFlags(3, true)
+		got = append(got, fmt.Sprintf("%d/%v", cell.FlagCount(), cell.IsQuestioned))
+	}
+	want := []string{"1/false", "2/false", "3/false", "0/true", "0/false"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CycleFlags() sequence = %v, want %v", got, want)
+	}
+}
+
+func TestCell_AddMine(t *testing.T) {
+	cell := NewCell()
+	cell.AddMine()
+	// 1個目はSetMineと同じ
+	if !cell.IsMine || cell.Mines != 0 || cell.MineCount() != 1 {
+		t.Errorf("AddMine() once = %+v, want a single mine", cell)
+	}
+	cell.AddMine()
+	if cell.MineCount() != 2 {
+		t.Errorf("MineCount() = %d, want 2", cell.MineCount())
+	}
+
+	cell.MarkMines(2)
+	if !cell.IsMarked || cell.MarkCount() != 2 {
+		t.Errorf("MarkMines(2) = %+v, want 2 marked mines", cell)
+	}
+}
+
+func TestCell_Reveal_ClearsQuestion(t *testing.T) {
+	// 「?」のマスは旗と違って開ける
+	cell := NewCell()
+	cell.IsQuestioned = true
+	cell.Reveal()
+	if !cell.IsRevealed || cell.IsQuestioned {
+		t.Errorf("Reveal() = %+v, want revealed without a question mark", cell)
+	}
+}
+
+func TestCell_Mark(t *testing.T) {
+	cell := NewCell()
+	cell.Mark()
+	if !cell.IsMarked || cell.IsFlagged {
+		t.Errorf("Mark() = %+v, want marked without a flag", cell)
+	}
+	// AIの印が付いたマスは開けない
+	cell.Reveal()
+	if cell.IsRevealed {
+		t.Error("marked cell should not be revealed")
+	}
+
+	revealed := NewCell()
+	revealed.IsRevealed = true
+	revealed.Mark()
+	if revealed.IsMarked {
+		t.Error("revealed cell should not be marked")
+	}
+}
+
 func TestCell_SetMine(t *testing.T) {
 	cell := NewCell()
 
@@ -1,6 +1,12 @@
 package game
 
-import "time"
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
 
 type GameState int
 
@@ -10,59 +16,251 @@ const (
 	Lost
 )
 
+// String はゲームの状態の名前（playing / won / lost）.
+func (s GameState) String() string {
+	switch s {
+	case Playing:
+		return "playing"
+	case Won:
+		return "won"
+	case Lost:
+		return "lost"
+	}
+	return fmt.Sprintf("GameState(%d)", int(s))
+}
+
+// MarshalText は状態を名前（playing / won / lost）で書き出す.
+func (s GameState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText は名前から状態を読む.
+func (s *GameState) UnmarshalText(text []byte) error {
+	for _, state := range []GameState{Playing, Won, Lost} {
+		if state.String() == string(text) {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown game state %q", text)
+}
+
+// Difficulty は盤面の大きさと地雷数.
+// Name は表示名ではなくキー（"beginner" など）で、表示時に各言語の名前へ変換する.
 type Difficulty struct {
-	Name   string
-	Width  int
-	Height int
-	Mines  int
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Mines  int    `json:"mines"`
 }
 
 var (
-	Beginner     = Difficulty{"初級", 9, 9, 10}
-	Intermediate = Difficulty{"中級", 16, 16, 40}
-	Expert       = Difficulty{"上級", 30, 16, 99}
+	Beginner     = Difficulty{"beginner", 9, 9, 10}
+	Intermediate = Difficulty{"intermediate", 16, 16, 40}
+	Expert       = Difficulty{"expert", 30, 16, 99}
+)
+
+// CustomDifficultyName はカスタム難易度のキー.
+const CustomDifficultyName = "custom"
+
+// NewCustomDifficulty は任意の大きさの難易度を作成.
+// 最初のクリックの周囲9マスには地雷を置かないので、その分の空きが必要.
+func NewCustomDifficulty(width, height, mines int) (Difficulty, error) {
+	if width < 1 || height < 1 {
+		return Difficulty{}, fmt.Errorf("board size must be positive: %dx%d", width, height)
+	}
+	if mines < 0 || mines > width*height-9 {
+		return Difficulty{}, fmt.Errorf("mines must be between 0 and %d for a %dx%d board", max(0, width*height-9), width, height)
+	}
+	return Difficulty{CustomDifficultyName, width, height, mines}, nil
+}
+
+// 他人が大きさを決められる所（サーバー・ボット・MCP）で受け付ける盤面の上限.
+// これより大きい盤面はメモリも解析の時間も使いすぎる.
+const (
+	MaxWidth  = 200
+	MaxHeight = 200
+	MaxCells  = 10000
 )
 
+// NewLimitedDifficulty は NewCustomDifficulty と同じだが、MaxWidth・MaxHeight・MaxCells を超える大きさを拒否する.
+func NewLimitedDifficulty(width, height, mines int) (Difficulty, error) {
+	if width > MaxWidth || height > MaxHeight || width*height > MaxCells {
+		return Difficulty{}, fmt.Errorf("board size %dx%d is too large (max %dx%d, %d cells)",
+			width, height, MaxWidth, MaxHeight, MaxCells)
+	}
+	return NewCustomDifficulty(width, height, mines)
+}
+
+// ParseDifficulty はキー（beginner / intermediate / expert）から難易度を取得.
+func ParseDifficulty(name string) (Difficulty, error) {
+	switch strings.ToLower(name) {
+	case Beginner.Name:
+		return Beginner, nil
+	case Intermediate.Name:
+		return Intermediate, nil
+	case Expert.Name:
+		return Expert, nil
+	}
+	return Difficulty{}, fmt.Errorf("unknown difficulty %q", name)
+}
+
+// Options はゲームごとのルール設定.
+type Options struct {
+	// Seed は地雷配置の乱数シード。0ならゲームごとにランダムなシードを使う.
+	Seed int64
+	// QuestionMarks が true なら、旗の操作で 無し → 旗 → ? → 無し と切り替わる.
+	QuestionMarks bool
+	// Topology は盤面のマスのつながり方。nilなら正方形のマス目.
+	Topology Topology
+	// Neighborhood は数字が数えるマスの範囲。空なら standard.
+	Neighborhood Neighborhood
+	// MaxCellMines は1マスに置ける地雷の最大数。0か1なら通常のルール.
+	MaxCellMines int
+	// Lives は地雷を踏める回数。踏むたびに1減り、0になると負け。0か1なら最初の地雷で負け.
+	Lives int
+}
+
+// Game は1回のゲーム.
+// 複数のゴルーチンで同じゲームを共有するとき（協力プレイなど）は、操作の間 Lock を、読むだけなら RLock を持つ.
+// メソッドは自分ではロックしないので、1つのゴルーチンだけで使うならロックは要らない.
 type Game struct {
+	mu sync.RWMutex
+
 	Board       *Board
 	State       GameState
 	FirstClick  bool
 	Difficulty  Difficulty
 	StartTime   int64
 	ElapsedTime int64
+	Options     Options
+	// Seed は現在の盤面のシード。同じシードと最初のクリック位置で盤面を再現できる.
+	Seed int64
+	// Lives は残りのライフ。ライフ制でなければ1.
+	Lives int
+	// Explosions は踏んだ地雷のマスの数.
+	Explosions int
 }
 
 func NewGame(difficulty Difficulty) *Game {
+	return NewGameWithOptions(difficulty, Options{})
+}
+
+func NewGameWithOptions(difficulty Difficulty, options Options) *Game {
 	return &Game{
-		Board:      NewBoard(difficulty.Width, difficulty.Height, difficulty.Mines),
+		Board:      options.newBoard(difficulty),
 		State:      Playing,
 		FirstClick: true,
 		Difficulty: difficulty,
+		Options:    options,
+		Seed:       options.seed(),
+		Lives:      options.lives(),
+	}
+}
+
+// newBoard はルール設定に従った空の盤面を作成する.
+func (o Options) newBoard(difficulty Difficulty) *Board {
+	board := NewBoardWithTopology(difficulty.Width, difficulty.Height, difficulty.Mines, o.Topology)
+	board.Neighborhood = o.Neighborhood
+	board.MaxCellMines = o.MaxCellMines
+	return board
+}
+
+// CheckMines は難易度の地雷を、最初のクリックの周囲を避けて置けるかを調べる.
+// 避けるマスの数はマスのつながり方と数字が数える範囲で変わるので、最も広い場合で数える.
+func (o Options) CheckMines(difficulty Difficulty) error {
+	board := o.newBoard(difficulty)
+	limit := (difficulty.Width*difficulty.Height - board.SafeZoneSize()) * board.CellCapacity()
+	if difficulty.Mines > limit {
+		return fmt.Errorf("mines must be at most %d for a %dx%d board with these rules: %d",
+			max(0, limit), difficulty.Width, difficulty.Height, difficulty.Mines)
+	}
+	return nil
+}
+
+// CheckMaxCellMines は1マスに置ける地雷の最大数が範囲内かを調べる。0は1と同じ.
+func CheckMaxCellMines(n int) error {
+	if n < 0 || n > MaxCellMinesLimit {
+		return fmt.Errorf("max cell mines must be between 0 and %d: %d", MaxCellMinesLimit, n)
 	}
+	return nil
 }
 
-func (g *Game) Click(pos Position) {
+// lives は最初のライフ。ライフ制でなければ1.
+func (o Options) lives() int {
+	return max(o.Lives, 1)
+}
+
+func (o Options) seed() int64 {
+	if o.Seed != 0 {
+		return o.Seed
+	}
+	return rand.Int63() //nolint:gosec // シードの生成にはmath/randで十分
+}
+
+// Click はマスを開き、新たに開いたマスの一覧を返す.
+// 地雷を踏むとライフが1減り、その地雷は開いたまま残る。ライフがなくなると負け.
+func (g *Game) Click(pos Position) []Position {
 	if g.State != Playing {
-		return
+		return nil
 	}
 
 	if g.FirstClick {
-		g.Board.Initialize(pos)
+		g.Board.InitializeWithRand(pos, rand.New(rand.NewSource(g.Seed))) //nolint:gosec // 地雷配置にはmath/randで十分
 		g.FirstClick = false
 		g.StartTime = getCurrentTime()
 	}
 
-	hitMine := g.Board.RevealCell(pos)
+	hitMine, revealed := g.Board.Reveal(pos)
 
 	if hitMine {
+		g.Explosions++
+		g.Lives--
+	}
+	if hitMine && g.Lives <= 0 {
 		g.State = Lost
 		g.revealAllMines()
 	} else if g.Board.CountUnrevealedSafeCells() == 0 {
 		g.State = Won
 		g.ElapsedTime = getCurrentTime() - g.StartTime
 	}
+	return revealed
 }
 
+// Chord は開いた数字のマスの周囲に、その数字と同じ数の旗（AIの印と踏んだ地雷を含む）があれば、
+// 周囲の残りのマスをまとめて開き、新たに開いたマスの一覧を返す。旗の数が合わなければ何もしない.
+func (g *Game) Chord(pos Position) []Position {
+	cell := g.Board.GetCell(pos)
+	if g.State != Playing || cell == nil || !cell.IsRevealed || cell.IsMine || cell.Adjacent == 0 {
+		return nil
+	}
+
+	known := 0
+	var targets []Position
+	for _, adj := range g.Board.GetAdjacentPositions(pos) {
+		c := g.Board.GetCell(adj)
+		switch {
+		case c.IsRevealed:
+			known += c.MineCount()
+		case c.IsFlagged || c.IsMarked:
+			known += max(c.FlagCount(), c.MarkCount())
+		default:
+			targets = append(targets, adj)
+		}
+	}
+	if known != cell.Adjacent {
+		return nil
+	}
+
+	var revealed []Position
+	for _, adj := range targets {
+		revealed = append(revealed, g.Click(adj)...)
+	}
+	return revealed
+}
+
+// ToggleFlag はプレイヤーの旗を切り替える。Options.QuestionMarks なら旗の次に「?」を挟む.
+// 1マスに複数の地雷を置くルールでは、旗の数を1から上限まで増やしてから外す.
 func (g *Game) ToggleFlag(pos Position) {
 	if g.State != Playing {
 		return
@@ -70,25 +268,62 @@ func (g *Game) ToggleFlag(pos Position) {
 
 	cell := g.Board.GetCell(pos)
 	if cell != nil {
-		cell.ToggleFlag()
+		cell.CycleFlags(g.Board.CellCapacity(), g.Options.QuestionMarks)
+	}
+}
+
+// MarkMine はAIが地雷と確定させたマスに印を付ける.
+func (g *Game) MarkMine(pos Position) {
+	g.MarkMines(pos, 1)
+}
+
+// MarkMines はAIが地雷の数まで確定させたマスに印を付ける.
+func (g *Game) MarkMines(pos Position, count int) {
+	if g.State != Playing {
+		return
+	}
+
+	cell := g.Board.GetCell(pos)
+	if cell != nil {
+		cell.MarkMines(count)
 	}
 }
 
 func (g *Game) Reset() {
-	g.Board = NewBoard(g.Difficulty.Width, g.Difficulty.Height, g.Difficulty.Mines)
+	g.Board = g.Options.newBoard(g.Difficulty)
 	g.State = Playing
 	g.FirstClick = true
 	g.StartTime = 0
 	g.ElapsedTime = 0
+	g.Seed = g.Options.seed()
+	g.Lives = g.Options.lives()
+	g.Explosions = 0
 }
 
+// Lock は共有しているゲームを操作する前に呼び、ほかのゴルーチンの読み書きを待たせる.
+func (g *Game) Lock() { g.mu.Lock() }
+
+// Unlock は Lock を解く.
+func (g *Game) Unlock() { g.mu.Unlock() }
+
+// RLock は共有しているゲームを読む前（画面の描画など）に呼ぶ。読むだけのゴルーチンどうしは待たない.
+func (g *Game) RLock() { g.mu.RLock() }
+
+// RUnlock は RLock を解く.
+func (g *Game) RUnlock() { g.mu.RUnlock() }
+
 func (g *Game) GetRemainingMines() int {
 	flaggedCount := 0
 	for i := 0; i < g.Board.Height; i++ {
 		for j := 0; j < g.Board.Width; j++ {
-			if g.Board.Cells[i][j].IsFlagged {
-				flaggedCount++
+			cell := g.Board.Cells[i][j]
+			if cell.IsRevealed && g.State != Lost {
+				// ライフ制で踏んだ地雷は見えているので、見つかった地雷として数える
+				flaggedCount += cell.MineCount()
+				continue
 			}
+			// 旗とAIの印が両方あれば、多い方の数だけ地雷として数える
+			flaggedCount += max(cell.FlagCount(), cell.MarkCount())
 		}
 	}
 	return g.Board.Mines - flaggedCount
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"sync"
 	"testing"
 )
 
@@ -197,6 +198,155 @@ func TestGame_ToggleFlag(t *testing.T) {
 	}
 }
 
+func TestGame_ToggleFlag_QuestionMarks(t *testing.T) {
+	g := NewGameWithOptions(Beginner, Options{QuestionMarks: true})
+	pos := Position{Row: 1, Col: 1}
+	cell := g.Board.GetCell(pos)
+
+	// 無し → 旗 → ? → 無し の順に切り替わる
+	g.ToggleFlag(pos)
+	if !cell.IsFlagged || cell.IsQuestioned {
+		t.Fatalf("first toggle = %+v, want flagged", cell)
+	}
+	g.ToggleFlag(pos)
+	if cell.IsFlagged || !cell.IsQuestioned {
+		t.Fatalf("second toggle = %+v, want questioned", cell)
+	}
+	// 「?」は残りの地雷数に数えない
+	if got := g.GetRemainingMines(); got != Beginner.Mines {
+		t.Errorf("GetRemainingMines() = %d, want %d", got, Beginner.Mines)
+	}
+	g.ToggleFlag(pos)
+	if cell.IsFlagged || cell.IsQuestioned {
+		t.Errorf("third toggle = %+v, want no mark", cell)
+	}
+}
+
+func TestGame_MaxCellMines(t *testing.T) {
+	// 64マスに60個の地雷を1マス3個までで置くので、必ず重なる
+	difficulty := Difficulty{CustomDifficultyName, 8, 8, 60}
+	g := NewGameWithOptions(difficulty, Options{Seed: 1, MaxCellMines: 3})
+	g.Click(Position{Row: 0, Col: 0})
+
+	total, stacked := 0, 0
+	for i := range g.Board.Cells {
+		for j, cell := range g.Board.Cells[i] {
+			if n := cell.MineCount(); n > 3 {
+				t.Errorf("cell (%d,%d) has %d mines, want at most 3", i, j, n)
+			} else if n > 1 {
+				stacked++
+			}
+			total += cell.MineCount()
+			if !cell.IsMine {
+				if want := g.Board.countAdjacentMines(Position{i, j}); cell.Adjacent != want {
+					t.Errorf("cell (%d,%d) Adjacent = %d, want %d", i, j, cell.Adjacent, want)
+				}
+			}
+		}
+	}
+	if total != 60 || stacked == 0 {
+		t.Errorf("placed %d mines with %d stacked cells, want 60 with some stacked", total, stacked)
+	}
+
+	// 旗は上限まで数を増やしてから外れ、残りの地雷数は旗の数の合計で減る
+	pos := Position{Row: 7, Col: 7}
+	g.ToggleFlag(pos)
+	g.ToggleFlag(pos)
+	if got := g.GetRemainingMines(); got != 58 {
+		t.Errorf("GetRemainingMines() with a 2-flag = %d, want 58", got)
+	}
+	g.MarkMines(Position{Row: 7, Col: 6}, 3)
+	if got := g.GetRemainingMines(); got != 55 {
+		t.Errorf("GetRemainingMines() with a 3-mark = %d, want 55", got)
+	}
+	g.ToggleFlag(pos)
+	g.ToggleFlag(pos)
+	if g.Board.GetCell(pos).IsFlagged {
+		t.Error("flag should be removed after reaching the limit")
+	}
+}
+
+func TestGame_Click_Lives(t *testing.T) {
+	g := NewGameWithOptions(Beginner, Options{Lives: 2})
+	g.Board = NewBoard(3, 3, 2)
+	g.Board.Cells[0][0].SetMine()
+	g.Board.Cells[2][2].SetMine()
+	g.FirstClick = false
+
+	// 1個目の地雷ではライフが減るだけで、踏んだ地雷は開いたまま続けられる
+	g.Click(Position{Row: 0, Col: 0})
+	if g.State != Playing || g.Lives != 1 || g.Explosions != 1 {
+		t.Fatalf("after first hit: state %v, lives %d, explosions %d", g.State, g.Lives, g.Explosions)
+	}
+	if !g.Board.Cells[0][0].IsRevealed || g.Board.Cells[2][2].IsRevealed {
+		t.Error("only the exploded mine should be revealed")
+	}
+	if got := g.GetRemainingMines(); got != 1 {
+		t.Errorf("GetRemainingMines() = %d, want 1", got)
+	}
+
+	// ライフがなくなると負け
+	g.Click(Position{Row: 2, Col: 2})
+	if g.State != Lost || g.Lives != 0 {
+		t.Errorf("after second hit: state %v, lives %d, want lost with 0 lives", g.State, g.Lives)
+	}
+
+	g.Reset()
+	if g.Lives != 2 || g.Explosions != 0 {
+		t.Errorf("Reset() lives %d, explosions %d, want 2 and 0", g.Lives, g.Explosions)
+	}
+}
+
+func TestGame_Click_LivesCanStillWin(t *testing.T) {
+	g := NewGameWithOptions(Beginner, Options{Lives: 3})
+	g.Board = NewBoard(2, 1, 1)
+	g.Board.Cells[0][0].SetMine()
+	g.Board.Cells[0][1].SetAdjacent(1)
+	g.FirstClick = false
+
+	g.Click(Position{Row: 0, Col: 0})
+	g.Click(Position{Row: 0, Col: 1})
+	if g.State != Won {
+		t.Errorf("State = %v, want Won after clearing the safe cells", g.State)
+	}
+}
+
+func TestGame_Chord(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     *Position
+		want     int
+		wantLost bool
+	}{
+		{"旗が足りなければ何もしない", nil, 0, false},
+		{"正しい旗なら残りをすべて開く", &Position{Row: 0, Col: 0}, 7, false},
+		{"間違った旗なら地雷を踏む", &Position{Row: 0, Col: 1}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := ParseBoard("*??\n?1?\n???")
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := NewGame(Beginner)
+			g.Board = board
+			g.FirstClick = false
+			if tt.flag != nil {
+				g.ToggleFlag(*tt.flag)
+			}
+
+			revealed := g.Chord(Position{Row: 1, Col: 1})
+			if len(revealed) != tt.want {
+				t.Errorf("Chord() revealed %d cells, want %d", len(revealed), tt.want)
+			}
+			if lost := g.State == Lost; lost != tt.wantLost {
+				t.Errorf("State = %v, want lost %v", g.State, tt.wantLost)
+			}
+		})
+	}
+}
+
 // Phase 2 Tests
 
 func TestGame_Click(t *testing.T) { //nolint:gocyclo // テストケースが多いため複雑度が高い
@@ -539,3 +689,177 @@ func TestGame_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDifficulty(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Difficulty
+		wantErr bool
+	}{
+		{"beginner", Beginner, false},
+		{"Intermediate", Intermediate, false},
+		{"expert", Expert, false},
+		{"初級", Difficulty{}, true},
+		{"nightmare", Difficulty{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDifficulty(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDifficulty() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDifficulty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGameWithOptions_SameSeedSameBoard(t *testing.T) {
+	first := Position{Row: 4, Col: 4}
+
+	g1 := NewGameWithOptions(Beginner, Options{Seed: 42})
+	g2 := NewGameWithOptions(Beginner, Options{Seed: 42})
+	g1.Click(first)
+	g2.Click(first)
+
+	if g1.Board.Notation() != g2.Board.Notation() {
+		t.Errorf("same seed produced different boards:\n%s\n%s", g1.Board.Notation(), g2.Board.Notation())
+	}
+
+	// 固定シードならリセット後も同じ盤面になる
+	g1.Reset()
+	g1.Click(first)
+	if g1.Board.Notation() != g2.Board.Notation() {
+		t.Error("Reset() with a fixed seed should replay the same board")
+	}
+}
+
+func TestNewGame_RandomSeed(t *testing.T) {
+	g := NewGame(Beginner)
+	if g.Seed == 0 {
+		t.Error("NewGame() should pick a seed")
+	}
+}
+
+func TestNewCustomDifficulty(t *testing.T) {
+	tests := []struct {
+		name                 string
+		width, height, mines int
+		wantErr              bool
+	}{
+		{"valid", 20, 10, 50, false},
+		{"no mines", 5, 5, 0, false},
+		{"zero width", 0, 10, 5, true},
+		{"too many mines", 5, 5, 17, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewCustomDifficulty(tt.width, tt.height, tt.mines)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewCustomDifficulty() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && d.Name != CustomDifficultyName {
+				t.Errorf("Name = %q, want %q", d.Name, CustomDifficultyName)
+			}
+		})
+	}
+}
+
+func TestOptions_CheckMines(t *testing.T) {
+	// 5x5の盤面の中央をクリックすると、周囲8マスか半径2の24マスには地雷を置かない
+	difficulty := func(mines int) Difficulty {
+		return Difficulty{Name: CustomDifficultyName, Width: 5, Height: 5, Mines: mines}
+	}
+	tests := []struct {
+		name    string
+		options Options
+		mines   int
+		wantErr bool
+	}{
+		{"standard", Options{}, 16, false},
+		{"standard too many", Options{}, 17, true},
+		{"radius2", Options{Neighborhood: NeighborhoodRadius2}, 1, true},
+		{"two mines per cell", Options{MaxCellMines: 2}, 32, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.CheckMines(difficulty(tt.mines))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckMines() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewLimitedDifficulty(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantErr       bool
+	}{
+		{"expert", 30, 16, false},
+		{"largest", MaxWidth, MaxCells / MaxWidth, false},
+		{"too wide", MaxWidth + 1, 10, true},
+		{"too tall", 10, MaxHeight + 1, true},
+		{"too many cells", MaxWidth, MaxHeight, true},
+		{"huge", 100000, 100000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLimitedDifficulty(tt.width, tt.height, 10)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewLimitedDifficulty(%d, %d) error = %v, wantErr %v", tt.width, tt.height, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGame_Lock(t *testing.T) {
+	// 複数のゴルーチンがロックを持って同じゲームを操作しても、旗の数が食い違わない
+	g := NewGameWithOptions(Beginner, Options{Seed: 1})
+	g.Click(Position{Row: 4, Col: 4})
+	hidden := g.Board.CountUnrevealedSafeCells() + g.Board.Mines
+
+	var wg sync.WaitGroup
+	for col := 0; col < g.Board.Width; col++ {
+		wg.Add(1)
+		go func(col int) {
+			defer wg.Done()
+			g.Lock()
+			defer g.Unlock()
+			for row := 0; row < g.Board.Height; row++ {
+				if !g.Board.Cells[row][col].IsRevealed {
+					g.ToggleFlag(Position{Row: row, Col: col})
+				}
+			}
+		}(col)
+	}
+	wg.Wait()
+
+	g.RLock()
+	defer g.RUnlock()
+	if got, want := g.GetRemainingMines(), g.Board.Mines-hidden; got != want {
+		t.Errorf("GetRemainingMines() = %d, want %d", got, want)
+	}
+}
+
+func TestGameState_Text(t *testing.T) {
+	for _, state := range []GameState{Playing, Won, Lost} {
+		text, err := state.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got GameState
+		if err := got.UnmarshalText(text); err != nil || got != state {
+			t.Errorf("UnmarshalText(%q) = %v, %v, want %v", text, got, err, state)
+		}
+	}
+	var s GameState
+	if err := s.UnmarshalText([]byte("paused")); err == nil {
+		t.Error("UnmarshalText(\"paused\") returned no error")
+	}
+}
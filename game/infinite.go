@@ -0,0 +1,260 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// ChunkSize は無限盤面を生成する単位のチャンクの一辺のマス数.
+const ChunkSize = 16
+
+// 無限盤面の地雷の密度。低すぎると空白のマスがどこまでもつながり、連鎖して開くのが止まらなくなる.
+const (
+	MinInfiniteDensity     = 0.12
+	MaxInfiniteDensity     = 0.5
+	DefaultInfiniteDensity = 0.18
+)
+
+// maxCascade は無限盤面で1回のクリックで連鎖して開くマスの上限.
+const maxCascade = 100000
+
+// chunkCoord はチャンクの位置。マスの位置をChunkSizeで切り捨てて割ったもの.
+type chunkCoord struct {
+	row, col int
+}
+
+// chunk は無限盤面のChunkSize四方のマス。counted は隣接数を数え終えたかどうか.
+type chunk struct {
+	cells   [ChunkSize * ChunkSize]Cell
+	counted bool
+}
+
+// InfiniteBoard は必要になった部分だけをチャンク単位で生成する、端のない盤面.
+// 各チャンクの地雷配置は盤面のシードとチャンクの位置だけで決まるので、生成する順序によらず同じ盤面になる.
+// 原点 (0,0) とその周囲8マスには地雷を置かない.
+type InfiniteBoard struct {
+	Seed    int64
+	Density float64
+	chunks  map[chunkCoord]*chunk
+}
+
+// NewInfiniteBoard は無限盤面を作成する。マスはまだ生成しない.
+func NewInfiniteBoard(seed int64, density float64) *InfiniteBoard {
+	return &InfiniteBoard{
+		Seed:    seed,
+		Density: density,
+		chunks:  map[chunkCoord]*chunk{},
+	}
+}
+
+// CheckInfiniteDensity は無限盤面の地雷の密度が範囲内かを調べる.
+func CheckInfiniteDensity(density float64) error {
+	if density < MinInfiniteDensity || density > MaxInfiniteDensity {
+		return fmt.Errorf("density must be between %.2f and %.2f: %g", MinInfiniteDensity, MaxInfiniteDensity, density)
+	}
+	return nil
+}
+
+// GetCell はマスを返す。まだ生成していなければ、そのチャンクと周囲のチャンクを生成する.
+func (b *InfiniteBoard) GetCell(pos Position) *Cell {
+	coord := chunkOf(pos)
+	c := b.chunk(coord)
+	if !c.counted {
+		b.countChunk(coord, c)
+	}
+	return &c.cells[cellIndex(pos)]
+}
+
+// ChunkCount は生成済みのチャンクの数.
+func (b *InfiniteBoard) ChunkCount() int {
+	return len(b.chunks)
+}
+
+// GetAdjacentPositions は周囲8マスを返す.
+func (b *InfiniteBoard) GetAdjacentPositions(pos Position) []Position {
+	positions := make([]Position, 0, 8)
+	for dr := -1; dr <= 1; dr++ {
+		for dc := -1; dc <= 1; dc++ {
+			if dr != 0 || dc != 0 {
+				positions = append(positions, Position{pos.Row + dr, pos.Col + dc})
+			}
+		}
+	}
+	return positions
+}
+
+// Reveal はマスを開き、連鎖して開いたマスも含めて新たに開いたマスの一覧を返す.
+// 連鎖はmaxCascadeマスで打ち切り、残りは未開放のままにする.
+func (b *InfiniteBoard) Reveal(pos Position) (hitMine bool, revealed []Position) {
+	queue := []Position{pos}
+	seen := map[Position]bool{pos: true}
+	for len(queue) > 0 && len(revealed) < maxCascade {
+		p := queue[0]
+		queue = queue[1:]
+		cell := b.GetCell(p)
+		if cell.IsRevealed || cell.IsFlagged || cell.IsMarked {
+			continue
+		}
+		cell.Reveal()
+		revealed = append(revealed, p)
+		if cell.IsMine {
+			return true, revealed
+		}
+		if cell.Adjacent != 0 {
+			continue
+		}
+		for _, adj := range b.GetAdjacentPositions(p) {
+			if !seen[adj] {
+				seen[adj] = true
+				queue = append(queue, adj)
+			}
+		}
+	}
+	return false, revealed
+}
+
+// Window は origin を左上とする width×height の範囲を、見えている情報だけの通常の盤面として切り出す.
+// 範囲の外周の開いたマスは、数字が範囲外のマスも数えているので未開放として扱う.
+// 切り出した盤面の地雷数は分からないので0になる.
+func (b *InfiniteBoard) Window(origin Position, width, height int) *Board {
+	window := NewBoard(width, height, 0)
+	for i := 0; i < height; i++ {
+		for j := 0; j < width; j++ {
+			src := b.GetCell(Position{origin.Row + i, origin.Col + j})
+			dst := window.Cells[i][j]
+			edge := i == 0 || j == 0 || i == height-1 || j == width-1
+			dst.IsFlagged = src.IsFlagged
+			dst.Flags = src.Flags
+			dst.IsMarked = src.IsMarked
+			dst.Marks = src.Marks
+			if src.IsRevealed && !edge {
+				dst.IsRevealed = true
+				dst.IsMine = src.IsMine
+				dst.Adjacent = src.Adjacent
+			}
+		}
+	}
+	return window
+}
+
+// chunk はチャンクを返す。まだなければ地雷を配置して作る.
+func (b *InfiniteBoard) chunk(coord chunkCoord) *chunk {
+	if c, ok := b.chunks[coord]; ok {
+		return c
+	}
+	c := &chunk{}
+	rng := rand.New(rand.NewSource(chunkSeed(b.Seed, coord))) //nolint:gosec // 地雷配置にはmath/randで十分
+	for i := range c.cells {
+		pos := Position{coord.row*ChunkSize + i/ChunkSize, coord.col*ChunkSize + i%ChunkSize}
+		// 乱数は必ず1マス1回使い、原点の周囲かどうかで後のマスの配置が変わらないようにする
+		mine := rng.Float64() < b.Density
+		if mine && (abs(pos.Row) > 1 || abs(pos.Col) > 1) {
+			c.cells[i].SetMine()
+		}
+	}
+	b.chunks[coord] = c
+	return c
+}
+
+// countChunk はチャンクのマスの隣接数を数える。周囲のチャンクは地雷の配置だけを生成する.
+func (b *InfiniteBoard) countChunk(coord chunkCoord, c *chunk) {
+	for i := range c.cells {
+		pos := Position{coord.row*ChunkSize + i/ChunkSize, coord.col*ChunkSize + i%ChunkSize}
+		count := 0
+		for _, adj := range b.GetAdjacentPositions(pos) {
+			if b.chunk(chunkOf(adj)).cells[cellIndex(adj)].IsMine {
+				count++
+			}
+		}
+		c.cells[i].SetAdjacent(count)
+	}
+	c.counted = true
+}
+
+// chunkOf はマスを含むチャンクの位置。負の座標でも切り捨てで割る.
+func chunkOf(pos Position) chunkCoord {
+	return chunkCoord{floorDiv(pos.Row, ChunkSize), floorDiv(pos.Col, ChunkSize)}
+}
+
+// cellIndex はチャンクの中でのマスの番号.
+func cellIndex(pos Position) int {
+	return (pos.Row-floorDiv(pos.Row, ChunkSize)*ChunkSize)*ChunkSize + pos.Col - floorDiv(pos.Col, ChunkSize)*ChunkSize
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// chunkSeed は盤面のシードとチャンクの位置から、チャンクの乱数のシードを作る.
+func chunkSeed(seed int64, coord chunkCoord) int64 {
+	h := uint64(seed)
+	h = mix64(h ^ uint64(int64(coord.row))*0x9e3779b97f4a7c15) //nolint:gosec // ハッシュの計算なので桁あふれしてよい
+	h = mix64(h ^ uint64(int64(coord.col))*0xc2b2ae3d27d4eb4f) //nolint:gosec // 同上
+	return int64(h)                                            //nolint:gosec // 同上
+}
+
+// mix64 はsplitmix64の混ぜ合わせ関数.
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// InfiniteGame は無限盤面のゲーム。原点から始め、最初に地雷を踏むまでに開いたマスの数を競う.
+type InfiniteGame struct {
+	Board *InfiniteBoard
+	State GameState
+	// Score は最初に地雷を踏むまでに開いた安全なマスの数.
+	Score     int
+	StartTime int64
+}
+
+// NewInfiniteGame は無限盤面のゲームを作成し、安全な原点を開く.
+// seed が0ならランダムなシードを使う.
+func NewInfiniteGame(seed int64, density float64) *InfiniteGame {
+	if seed == 0 {
+		seed = Options{}.seed()
+	}
+	g := &InfiniteGame{
+		Board:     NewInfiniteBoard(seed, density),
+		State:     Playing,
+		StartTime: getCurrentTime(),
+	}
+	g.Click(Position{})
+	return g
+}
+
+// Click はマスを開き、新たに開いたマスの一覧を返す。地雷を踏むとゲームが終わる.
+func (g *InfiniteGame) Click(pos Position) []Position {
+	if g.State != Playing {
+		return nil
+	}
+	hitMine, revealed := g.Board.Reveal(pos)
+	if hitMine {
+		g.State = Lost
+		return revealed
+	}
+	g.Score += len(revealed)
+	return revealed
+}
+
+// ToggleFlag はプレイヤーの旗を切り替える.
+func (g *InfiniteGame) ToggleFlag(pos Position) {
+	if g.State == Playing {
+		g.Board.GetCell(pos).ToggleFlag()
+	}
+}
+
+// MarkMine はAIが地雷と確定させたマスに印を付ける.
+func (g *InfiniteGame) MarkMine(pos Position) {
+	if g.State == Playing {
+		g.Board.GetCell(pos).Mark()
+	}
+}
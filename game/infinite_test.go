@@ -0,0 +1,129 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestInfiniteBoard_Deterministic(t *testing.T) {
+	// チャンクを生成する順序が違っても、同じシードなら同じ盤面になる
+	a := NewInfiniteBoard(42, DefaultInfiniteDensity)
+	b := NewInfiniteBoard(42, DefaultInfiniteDensity)
+	positions := []Position{{0, 0}, {-1, -1}, {-17, 5}, {40, -33}, {15, 16}}
+	for _, pos := range positions {
+		a.GetCell(pos)
+	}
+	for i := len(positions) - 1; i >= 0; i-- {
+		pos := positions[i]
+		if ca, cb := a.GetCell(pos), b.GetCell(pos); *ca != *cb {
+			t.Errorf("cell %v differs: %+v vs %+v", pos, ca, cb)
+		}
+	}
+
+	other := NewInfiniteBoard(43, DefaultInfiniteDensity)
+	same := true
+	for row := -20; row < 20 && same; row++ {
+		for col := -20; col < 20; col++ {
+			pos := Position{row, col}
+			if a.GetCell(pos).IsMine != other.GetCell(pos).IsMine {
+				same = false
+				break
+			}
+		}
+	}
+	if same {
+		t.Error("different seeds should give different boards")
+	}
+}
+
+func TestInfiniteBoard_AdjacentAcrossChunks(t *testing.T) {
+	// チャンクの境界や負の座標でも隣接数は周囲8マスの地雷の数になる
+	b := NewInfiniteBoard(7, 0.3)
+	for row := -ChunkSize - 2; row < ChunkSize+2; row++ {
+		for col := -ChunkSize - 2; col < ChunkSize+2; col++ {
+			pos := Position{row, col}
+			want := 0
+			for _, adj := range b.GetAdjacentPositions(pos) {
+				if b.GetCell(adj).IsMine {
+					want++
+				}
+			}
+			if got := b.GetCell(pos).Adjacent; got != want {
+				t.Fatalf("cell %v Adjacent = %d, want %d", pos, got, want)
+			}
+		}
+	}
+}
+
+func TestInfiniteBoard_OriginIsSafe(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		b := NewInfiniteBoard(seed, MaxInfiniteDensity)
+		if c := b.GetCell(Position{}); c.IsMine || c.Adjacent != 0 {
+			t.Errorf("seed %d: origin = %+v, want an empty cell", seed, c)
+		}
+	}
+}
+
+func TestInfiniteGame_Click(t *testing.T) {
+	g := NewInfiniteGame(3, DefaultInfiniteDensity)
+	if g.Score == 0 || !g.Board.GetCell(Position{}).IsRevealed {
+		t.Fatalf("new game should open the origin, score %d", g.Score)
+	}
+
+	// 原点の連鎖の外側で最も近い地雷を踏むとゲームが終わり、スコアは変わらない
+	score := g.Score
+	var mine Position
+	found := false
+	for r := 1; r < 50 && !found; r++ {
+		for col := -r; col <= r && !found; col++ {
+			pos := Position{r, col}
+			if c := g.Board.GetCell(pos); c.IsMine && !c.IsRevealed {
+				mine, found = pos, true
+			}
+		}
+	}
+	if !found {
+		t.Fatal("no mine found near the origin")
+	}
+	g.Click(mine)
+	if g.State != Lost || g.Score != score {
+		t.Errorf("after hitting a mine: state %v, score %d, want lost with score %d", g.State, g.Score, score)
+	}
+	if g.Click(Position{Row: -5, Col: -5}) != nil {
+		t.Error("Click() after losing should do nothing")
+	}
+}
+
+func TestInfiniteBoard_Window(t *testing.T) {
+	g := NewInfiniteGame(5, DefaultInfiniteDensity)
+	window := g.Board.Window(Position{Row: -3, Col: -3}, 7, 7)
+	if window.Width != 7 || window.Height != 7 || window.Mines != 0 {
+		t.Fatalf("window = %dx%d with %d mines", window.Width, window.Height, window.Mines)
+	}
+	// 原点は窓の中央
+	if c := window.Cells[3][3]; !c.IsRevealed {
+		t.Error("origin should be revealed in the window")
+	}
+	for i := 0; i < 7; i++ {
+		for j := 0; j < 7; j++ {
+			c := window.Cells[i][j]
+			edge := i == 0 || j == 0 || i == 6 || j == 6
+			if edge && c.IsRevealed {
+				t.Errorf("edge cell (%d,%d) should be hidden in the window", i, j)
+			}
+			if !c.IsRevealed && c.IsMine {
+				t.Errorf("hidden cell (%d,%d) leaks a mine", i, j)
+			}
+		}
+	}
+}
+
+func TestCheckInfiniteDensity(t *testing.T) {
+	if err := CheckInfiniteDensity(DefaultInfiniteDensity); err != nil {
+		t.Errorf("default density: %v", err)
+	}
+	for _, d := range []float64{0, 0.05, 0.9} {
+		if err := CheckInfiniteDensity(d); err == nil {
+			t.Errorf("CheckInfiniteDensity(%g) should fail", d)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Neighborhood は数字が数えるマスの範囲のルール.
+// standard 以外は正方形のマス目での相対位置で決まるので、square と torus のトポロジーでだけ使える.
+type Neighborhood string
+
+const (
+	// NeighborhoodStandard はトポロジーの隣接マス（正方形のマス目なら周囲8マス）.
+	NeighborhoodStandard Neighborhood = "standard"
+	// NeighborhoodKnight はチェスのナイトが動ける8マス.
+	NeighborhoodKnight Neighborhood = "knight"
+	// NeighborhoodCross は上下左右の4マス.
+	NeighborhoodCross Neighborhood = "cross"
+	// NeighborhoodRadius2 は2マス以内の24マス.
+	NeighborhoodRadius2 Neighborhood = "radius2"
+)
+
+// Neighborhoods は組み込みの数え方の一覧.
+var Neighborhoods = []Neighborhood{NeighborhoodStandard, NeighborhoodKnight, NeighborhoodCross, NeighborhoodRadius2}
+
+// ParseNeighborhood は名前から数え方を取得する。空文字は standard.
+func ParseNeighborhood(name string) (Neighborhood, error) {
+	if name == "" {
+		return NeighborhoodStandard, nil
+	}
+	for _, n := range Neighborhoods {
+		if strings.EqualFold(string(n), name) {
+			return n, nil
+		}
+	}
+	return "", fmt.Errorf("unknown neighborhood %q", name)
+}
+
+// Check はトポロジーとこの数え方を組み合わせられるかを調べる.
+func (n Neighborhood) Check(topology Topology) error {
+	if n.offsets() == nil {
+		return nil
+	}
+	if _, ok := topology.(latticeTopology); !ok {
+		return fmt.Errorf("neighborhood %q needs a square or torus topology, not %q", n, topology.Name())
+	}
+	return nil
+}
+
+// offsets は正方形のマス目での相対位置。standard はトポロジーの隣接マスを使うのでnil.
+func (n Neighborhood) offsets() []Position {
+	switch n {
+	case NeighborhoodKnight:
+		return knightOffsets
+	case NeighborhoodCross:
+		return crossOffsets
+	case NeighborhoodRadius2:
+		return radius2Offsets
+	}
+	return nil
+}
+
+// latticeTopology は相対位置で隣接マスを決められる正方形のマス目のトポロジー.
+type latticeTopology interface {
+	latticeNeighbors(pos Position, width, height int, offsets []Position) []Position
+}
+
+func (squareTopology) latticeNeighbors(pos Position, width, height int, offsets []Position) []Position {
+	return offsetNeighbors(pos, width, height, false, offsets)
+}
+
+func (torusTopology) latticeNeighbors(pos Position, width, height int, offsets []Position) []Position {
+	return offsetNeighbors(pos, width, height, true, offsets)
+}
+
+var (
+	knightOffsets = []Position{
+		{-2, -1}, {-2, 1}, {-1, -2}, {-1, 2},
+		{1, -2}, {1, 2}, {2, -1}, {2, 1},
+	}
+	crossOffsets   = []Position{{-1, 0}, {0, -1}, {0, 1}, {1, 0}}
+	radius2Offsets = squareRange(2)
+)
+
+// squareRange は中心を除く一辺 2r+1 の正方形の範囲の相対位置を返す.
+func squareRange(r int) []Position {
+	offsets := make([]Position, 0, (2*r+1)*(2*r+1)-1)
+	for dr := -r; dr <= r; dr++ {
+		for dc := -r; dc <= r; dc++ {
+			if dr != 0 || dc != 0 {
+				offsets = append(offsets, Position{dr, dc})
+			}
+		}
+	}
+	return offsets
+}
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestBoard_GetAdjacentPositions_Neighborhood(t *testing.T) {
+	tests := []struct {
+		name         string
+		topology     Topology
+		neighborhood Neighborhood
+		pos          Position
+		wantCount    int
+	}{
+		{"standard", SquareTopology, NeighborhoodStandard, Position{3, 3}, 8},
+		{"knight center", SquareTopology, NeighborhoodKnight, Position{3, 3}, 8},
+		{"knight corner", SquareTopology, NeighborhoodKnight, Position{0, 0}, 2},
+		{"knight on torus", TorusTopology, NeighborhoodKnight, Position{0, 0}, 8},
+		{"cross center", SquareTopology, NeighborhoodCross, Position{3, 3}, 4},
+		{"cross edge", SquareTopology, NeighborhoodCross, Position{0, 3}, 3},
+		{"radius2 center", SquareTopology, NeighborhoodRadius2, Position{3, 3}, 24},
+		{"radius2 corner", SquareTopology, NeighborhoodRadius2, Position{0, 0}, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := NewBoardWithTopology(7, 7, 0, tt.topology)
+			board.Neighborhood = tt.neighborhood
+
+			got := board.GetAdjacentPositions(tt.pos)
+			if len(got) != tt.wantCount {
+				t.Errorf("GetAdjacentPositions(%v) = %v, want %d positions", tt.pos, got, tt.wantCount)
+			}
+			// 数える範囲は対称でないと、数字と推論が食い違う
+			for _, adj := range got {
+				if !containsPosition(board.GetAdjacentPositions(adj), tt.pos) {
+					t.Errorf("%v counts %v but not vice versa", tt.pos, adj)
+				}
+			}
+		})
+	}
+}
+
+func TestNeighborhood_Check(t *testing.T) {
+	for _, topology := range Topologies {
+		for _, n := range Neighborhoods {
+			err := n.Check(topology)
+			_, lattice := topology.(latticeTopology)
+			if wantErr := n != NeighborhoodStandard && !lattice; (err != nil) != wantErr {
+				t.Errorf("%s.Check(%s) error = %v, wantErr %v", n, topology.Name(), err, wantErr)
+			}
+		}
+	}
+}
+
+func TestParseNeighborhood(t *testing.T) {
+	for _, n := range Neighborhoods {
+		if got, err := ParseNeighborhood(string(n)); err != nil || got != n {
+			t.Errorf("ParseNeighborhood(%q) = %q, %v", n, got, err)
+		}
+	}
+	if got, err := ParseNeighborhood(""); err != nil || got != NeighborhoodStandard {
+		t.Errorf("ParseNeighborhood(\"\") = %q, %v, want standard", got, err)
+	}
+	if _, err := ParseNeighborhood("bishop"); err == nil {
+		t.Error("ParseNeighborhood(\"bishop\") should fail")
+	}
+}
+
+func TestParseBoard_Neighborhood(t *testing.T) {
+	// 半径2の範囲では数字が9を超えることがあり、10以上は英小文字で書く
+	text := "# neighborhood: radius2\n?****\n*****\n**n**\n*****\n*****\n"
+	board, err := ParseBoard(text)
+	if err != nil {
+		t.Fatalf("ParseBoard() error = %v", err)
+	}
+	if board.Neighborhood != NeighborhoodRadius2 {
+		t.Errorf("Neighborhood = %q, want radius2", board.Neighborhood)
+	}
+	if c := board.Cells[2][2]; c.Adjacent != 23 {
+		t.Errorf("cell (2,2) Adjacent = %d, want 23", c.Adjacent)
+	}
+	// 未開放のマスの数字も半径2で数える
+	if c := board.Cells[0][0]; c.Adjacent != 7 {
+		t.Errorf("cell (0,0) Adjacent = %d, want 7", c.Adjacent)
+	}
+	if got := board.Notation(); got != text {
+		t.Errorf("Notation() = %q, want %q", got, text)
+	}
+
+	if _, err := ParseBoard("# topology: hex\n# neighborhood: knight\n??\n"); err == nil {
+		t.Error("ParseBoard() with knight on hex should fail")
+	}
+}
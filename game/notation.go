@@ -0,0 +1,369 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 盤面の文字表記.
+//
+//	?    未開放のマス
+//	*    未開放の地雷
+//	B-E  未開放の2〜5個の地雷が重なったマス（1マスに複数の地雷を置くルールだけ）
+//	F    旗の立ったマス
+//	!    旗の立った未開放の地雷（地雷の位置を含めた表記だけ。見えている情報だけなら'F'）
+//	M    AIが地雷と確定させた印のあるマス
+//	&    旗とAIの印の両方があるマス
+//	.    開いた空白のマス
+//	1-9  開いた数字のマス
+//	a-z  開いた10〜35の数字のマス（数え方によっては8を超える）
+//	X    開いた地雷
+//
+// 空行と'#'で始まる行は無視する。ただし "# topology: hex" の行は盤面のマスのつながり方を、
+// "# neighborhood: knight" の行は数字が数えるマスの範囲を、"# max-cell-mines: 3" の行は
+// 1マスに置ける地雷の最大数を指定する。旗と印の数は表記しない.
+//
+// 地雷が重なったマスは旗や印があっても、開いていても'B'〜'E'で地雷の数を書き、その状態を
+// "# flagged: 0,1 2,3"（旗）、"# marked: 0,1"（AIの印）、"# exploded: 1,1"（開いた地雷）の行で「行,列」の並びとして指定する.
+const (
+	NotationHidden      = '?'
+	NotationMine        = '*'
+	NotationFlag        = 'F'
+	NotationFlaggedMine = '!'
+	NotationMarked      = 'M'
+	NotationFlaggedMark = '&'
+	NotationEmpty       = '.'
+	NotationExploded    = 'X'
+)
+
+// NotationSymbol は文字表記の記号と、その英語の説明。記号は1文字か "1-9" のような範囲.
+type NotationSymbol struct {
+	Symbol      string
+	Description string
+	// Hidden が true なら地雷の位置を含めた表記（Notation）だけに出て、VisibleNotation には出ない.
+	Hidden bool
+}
+
+// NotationSymbols は文字表記の記号の一覧。MCPの盤面の凡例などに使う.
+var NotationSymbols = []NotationSymbol{
+	{Symbol: string(NotationHidden), Description: "hidden"},
+	{Symbol: string(NotationMine), Description: "hidden mine", Hidden: true},
+	{Symbol: "B-E", Description: "2-5 mines in one cell", Hidden: true},
+	{Symbol: string(NotationFlag), Description: "flag"},
+	{Symbol: string(NotationFlaggedMine), Description: "flag on a mine", Hidden: true},
+	{Symbol: string(NotationMarked), Description: "mine marked by the AI"},
+	{Symbol: string(NotationFlaggedMark), Description: "flag on a mine marked by the AI"},
+	{Symbol: string(NotationEmpty), Description: "empty"},
+	{Symbol: "1-9", Description: "number of adjacent mines"},
+	{Symbol: "a-z", Description: "10-35 adjacent mines"},
+	{Symbol: string(NotationExploded), Description: "exploded mine"},
+}
+
+// 盤面のルールを指定するコメント行の接頭辞.
+const (
+	topologyDirective     = "# topology:"
+	neighborhoodDirective = "# neighborhood:"
+	maxCellMinesDirective = "# max-cell-mines:"
+	flaggedDirective      = "# flagged:"
+	markedDirective       = "# marked:"
+	explodedDirective     = "# exploded:"
+)
+
+// MaxCellMinesLimit は1マスに置ける地雷の数の上限。文字表記の'B'〜'E'で書ける範囲.
+const MaxCellMinesLimit = 5
+
+// multiMineBase は2個以上の地雷が重なったマスの記号の基準。n個なら multiMineBase+n-1.
+const multiMineBase = 'A'
+
+// maxNumberNotation は文字表記で書ける最大の数字.
+const maxNumberNotation = 9 + 'z' - 'a' + 1
+
+// boardRules は文字表記のコメント行で指定された盤面のルール.
+type boardRules struct {
+	topology     Topology
+	neighborhood Neighborhood
+	maxCellMines int
+	// flagged・marked・exploded は地雷が重なったマスの状態.
+	flagged  []Position
+	marked   []Position
+	exploded []Position
+}
+
+// parseDirective はルールを指定するコメント行を読み取る。ルールの行でなければ false を返す.
+func (r *boardRules) parseDirective(line string) (bool, error) {
+	if name, ok := strings.CutPrefix(line, topologyDirective); ok {
+		t, err := ParseTopology(strings.TrimSpace(name))
+		r.topology = t
+		return true, err
+	}
+	if name, ok := strings.CutPrefix(line, neighborhoodDirective); ok {
+		n, err := ParseNeighborhood(strings.TrimSpace(name))
+		r.neighborhood = n
+		return true, err
+	}
+	if value, ok := strings.CutPrefix(line, maxCellMinesDirective); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return true, fmt.Errorf("invalid max-cell-mines %q", value)
+		}
+		r.maxCellMines = n
+		return true, CheckMaxCellMines(n)
+	}
+	for _, d := range []struct {
+		prefix    string
+		positions *[]Position
+	}{
+		{flaggedDirective, &r.flagged},
+		{markedDirective, &r.marked},
+		{explodedDirective, &r.exploded},
+	} {
+		if value, ok := strings.CutPrefix(line, d.prefix); ok {
+			positions, err := parsePositions(value)
+			*d.positions = append(*d.positions, positions...)
+			return true, err
+		}
+	}
+	return false, nil
+}
+
+// parsePositions は空白で区切った「行,列」の並びを読み取る.
+func parsePositions(value string) ([]Position, error) {
+	var positions []Position
+	for _, field := range strings.Fields(value) {
+		row, col, ok := strings.Cut(field, ",")
+		r, rowErr := strconv.Atoi(row)
+		c, colErr := strconv.Atoi(col)
+		if !ok || rowErr != nil || colErr != nil {
+			return nil, fmt.Errorf("invalid position %q, want row,col", field)
+		}
+		positions = append(positions, Position{Row: r, Col: c})
+	}
+	return positions, nil
+}
+
+// applyStates は地雷が重なったマスに、コメント行で指定された旗・印・開いた状態を付ける.
+func (r *boardRules) applyStates(board *Board) error {
+	for _, group := range [][]Position{r.flagged, r.marked, r.exploded} {
+		for _, pos := range group {
+			if cell := board.GetCell(pos); cell == nil || cell.MineCount() < 2 {
+				return fmt.Errorf("position %d,%d in a state line is not a cell with several mines", pos.Row, pos.Col)
+			}
+		}
+	}
+	for _, pos := range r.flagged {
+		board.GetCell(pos).IsFlagged = true
+	}
+	for _, pos := range r.marked {
+		cell := board.GetCell(pos)
+		cell.MarkMines(cell.MineCount())
+	}
+	for _, pos := range r.exploded {
+		board.GetCell(pos).IsRevealed = true
+	}
+	return nil
+}
+
+// ParseBoard は文字表記から盤面を作成する.
+// 地雷数は'*'と'!'と'M'と'&'と'X'の数に'B'〜'E'の重なった地雷を足したものになり、未開放のマスの隣接数は配置された地雷から計算する.
+func ParseBoard(text string) (*Board, error) { //nolint:gocyclo // 記号ごとの分岐が必要
+	var rows []string
+	var rules boardRules
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if ok, err := rules.parseDirective(line); err != nil {
+			return nil, err
+		} else if ok {
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rows = append(rows, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty board")
+	}
+
+	width := len(rows[0])
+	board := NewBoardWithTopology(width, len(rows), 0, rules.topology)
+	board.Neighborhood = rules.neighborhood
+	board.MaxCellMines = rules.maxCellMines
+	if err := board.Neighborhood.Check(board.GetTopology()); err != nil {
+		return nil, err
+	}
+	mines := 0
+
+	for i, row := range rows {
+		if len(row) != width {
+			return nil, fmt.Errorf("line %d has width %d, want %d", i+1, len(row), width)
+		}
+		for j, ch := range row {
+			cell := board.Cells[i][j]
+			switch {
+			case ch == NotationHidden:
+			case ch == NotationMine:
+				cell.SetMine()
+				mines++
+			case ch == NotationFlag:
+				cell.IsFlagged = true
+			case ch == NotationFlaggedMine:
+				cell.SetMine()
+				cell.IsFlagged = true
+				mines++
+			case ch == NotationMarked, ch == NotationFlaggedMark:
+				cell.SetMine()
+				cell.IsMarked = true
+				cell.IsFlagged = ch == NotationFlaggedMark
+				mines++
+			case ch == NotationEmpty:
+				cell.IsRevealed = true
+			case ch == NotationExploded:
+				cell.SetMine()
+				cell.IsRevealed = true
+				mines++
+			case ch >= multiMineBase+1 && ch <= multiMineBase+MaxCellMinesLimit-1:
+				n := int(ch - multiMineBase + 1)
+				if n > board.CellCapacity() {
+					return nil, fmt.Errorf("line %d: %d mines in a cell exceed max-cell-mines %d", i+1, n, board.CellCapacity())
+				}
+				for range n {
+					cell.AddMine()
+				}
+				mines += n
+			default:
+				n, ok := parseNumberNotation(ch)
+				if !ok {
+					return nil, fmt.Errorf("line %d: unknown symbol %q", i+1, ch)
+				}
+				cell.IsRevealed = true
+				cell.SetAdjacent(n)
+			}
+		}
+	}
+	board.Mines = mines
+	if err := rules.applyStates(board); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < board.Height; i++ {
+		for j := 0; j < board.Width; j++ {
+			cell := board.Cells[i][j]
+			if !cell.IsRevealed && !cell.IsMine {
+				cell.SetAdjacent(board.countAdjacentMines(Position{i, j}))
+			}
+		}
+	}
+
+	return board, nil
+}
+
+// Notation は地雷の位置を含めた盤面の文字表記を返す.
+// 旗の下の地雷は'!'になる.
+func (b *Board) Notation() string {
+	return b.notation(false)
+}
+
+// VisibleNotation はプレイヤーに見えている情報だけの文字表記を返す.
+func (b *Board) VisibleNotation() string {
+	return b.notation(true)
+}
+
+func (b *Board) notation(visibleOnly bool) string {
+	var sb strings.Builder
+	if t := b.GetTopology(); t != SquareTopology {
+		fmt.Fprintf(&sb, "%s %s\n", topologyDirective, t.Name())
+	}
+	if n := b.Neighborhood; n != "" && n != NeighborhoodStandard {
+		fmt.Fprintf(&sb, "%s %s\n", neighborhoodDirective, n)
+	}
+	if b.CellCapacity() > 1 {
+		fmt.Fprintf(&sb, "%s %d\n", maxCellMinesDirective, b.CellCapacity())
+	}
+	if !visibleOnly {
+		b.writeStates(&sb)
+	}
+	for i := 0; i < b.Height; i++ {
+		for j := 0; j < b.Width; j++ {
+			sb.WriteByte(cellNotation(b.Cells[i][j], visibleOnly))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// writeStates は地雷が重なったマスの旗・印・開いた状態をコメント行に書く.
+func (b *Board) writeStates(sb *strings.Builder) {
+	for _, d := range []struct {
+		prefix string
+		has    func(*Cell) bool
+	}{
+		{flaggedDirective, func(c *Cell) bool { return c.IsFlagged }},
+		{markedDirective, func(c *Cell) bool { return c.IsMarked }},
+		{explodedDirective, func(c *Cell) bool { return c.IsRevealed }},
+	} {
+		var fields []string
+		for i := 0; i < b.Height; i++ {
+			for j := 0; j < b.Width; j++ {
+				if cell := b.Cells[i][j]; cell.MineCount() > 1 && d.has(cell) {
+					fields = append(fields, fmt.Sprintf("%d,%d", i, j))
+				}
+			}
+		}
+		if len(fields) > 0 {
+			fmt.Fprintf(sb, "%s %s\n", d.prefix, strings.Join(fields, " "))
+		}
+	}
+}
+
+func cellNotation(cell *Cell, visibleOnly bool) byte {
+	switch {
+	case cell.MineCount() > 1 && !visibleOnly:
+		// 旗・印・開いた状態は writeStates のコメント行に書く
+		return byte(multiMineBase + min(cell.MineCount(), MaxCellMinesLimit) - 1)
+	case cell.IsFlagged && cell.IsMarked:
+		return NotationFlaggedMark
+	case cell.IsFlagged && cell.IsMine && !visibleOnly:
+		return NotationFlaggedMine
+	case cell.IsFlagged:
+		return NotationFlag
+	case cell.IsMarked:
+		return NotationMarked
+	case !cell.IsRevealed && cell.IsMine && !visibleOnly:
+		return NotationMine
+	case !cell.IsRevealed:
+		return NotationHidden
+	case cell.IsMine:
+		return NotationExploded
+	case cell.Adjacent == 0:
+		return NotationEmpty
+	}
+	return numberNotation(cell.Adjacent)
+}
+
+// parseNumberNotation は数字の記号（1-9, a-z）を数に変換する.
+func parseNumberNotation(ch rune) (int, bool) {
+	switch {
+	case ch >= '1' && ch <= '9':
+		return int(ch - '0'), true
+	case ch >= 'a' && ch <= 'z':
+		return int(ch-'a') + 10, true
+	}
+	return 0, false
+}
+
+// numberNotation は数を数字の記号にする。表記できない大きな数は'z'にまとめる.
+func numberNotation(n int) byte {
+	switch {
+	case n <= 9:
+		return byte('0' + n)
+	case n < maxNumberNotation:
+		return byte('a' + n - 10)
+	}
+	return 'z'
+}
@@ -0,0 +1,277 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBoard(t *testing.T) {
+	text := `
+# コメント行
+1*?
+11F
+..X
+`
+	board, err := ParseBoard(text)
+	if err != nil {
+		t.Fatalf("ParseBoard() error = %v", err)
+	}
+
+	if board.Width != 3 || board.Height != 3 {
+		t.Fatalf("size = %dx%d, want 3x3", board.Width, board.Height)
+	}
+	if board.Mines != 2 {
+		t.Errorf("Mines = %d, want 2", board.Mines)
+	}
+
+	if c := board.Cells[0][1]; !c.IsMine || c.IsRevealed {
+		t.Errorf("cell (0,1) should be a hidden mine: %+v", c)
+	}
+	if c := board.Cells[0][0]; !c.IsRevealed || c.Adjacent != 1 {
+		t.Errorf("cell (0,0) should be revealed 1: %+v", c)
+	}
+	if c := board.Cells[1][2]; !c.IsFlagged {
+		t.Errorf("cell (1,2) should be flagged: %+v", c)
+	}
+	if c := board.Cells[2][2]; !c.IsMine || !c.IsRevealed {
+		t.Errorf("cell (2,2) should be an exploded mine: %+v", c)
+	}
+	// 未開放のマスの隣接数は地雷から計算される
+	if c := board.Cells[0][2]; c.Adjacent != 1 {
+		t.Errorf("cell (0,2) Adjacent = %d, want 1", c.Adjacent)
+	}
+}
+
+func TestParseBoard_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", "\n# only comment\n"},
+		{"ragged", "???\n??\n"},
+		{"unknown symbol", "??@\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseBoard(tt.text); err == nil {
+				t.Error("ParseBoard() should fail")
+			}
+		})
+	}
+}
+
+func TestParseBoard_Marked(t *testing.T) {
+	board, err := ParseBoard("1M\n1F\n")
+	if err != nil {
+		t.Fatalf("ParseBoard() error = %v", err)
+	}
+
+	// AIの印は地雷として数え、プレイヤーの旗とは区別する
+	if c := board.Cells[0][1]; !c.IsMine || !c.IsMarked || c.IsFlagged {
+		t.Errorf("cell (0,1) should be a marked mine: %+v", c)
+	}
+	if c := board.Cells[1][1]; c.IsMarked {
+		t.Errorf("cell (1,1) should be a flag, not a mark: %+v", c)
+	}
+	if board.Mines != 1 {
+		t.Errorf("Mines = %d, want 1", board.Mines)
+	}
+	if got := board.VisibleNotation(); got != "1M\n1F\n" {
+		t.Errorf("VisibleNotation() = %q", got)
+	}
+}
+
+func TestParseBoard_MaxCellMines(t *testing.T) {
+	// 'C'は地雷3個、'B'は地雷2個が重なったマス
+	text := "# max-cell-mines: 3\nC*\n6B\n"
+	board, err := ParseBoard(text)
+	if err != nil {
+		t.Fatalf("ParseBoard() error = %v", err)
+	}
+	if board.CellCapacity() != 3 {
+		t.Errorf("CellCapacity() = %d, want 3", board.CellCapacity())
+	}
+	if board.Mines != 6 {
+		t.Errorf("Mines = %d, want 6", board.Mines)
+	}
+	if c := board.Cells[0][0]; c.MineCount() != 3 {
+		t.Errorf("cell (0,0) MineCount() = %d, want 3", c.MineCount())
+	}
+	if got := board.Notation(); got != text {
+		t.Errorf("Notation() = %q, want %q", got, text)
+	}
+	if got := board.VisibleNotation(); got != "# max-cell-mines: 3\n??\n6?\n" {
+		t.Errorf("VisibleNotation() = %q", got)
+	}
+	if clone := board.Clone(); clone.CellCapacity() != 3 {
+		t.Error("Clone() should keep MaxCellMines")
+	}
+
+	for _, bad := range []string{"C?\n", "# max-cell-mines: 6\n??\n", "# max-cell-mines: two\n??\n"} {
+		if _, err := ParseBoard(bad); err == nil {
+			t.Errorf("ParseBoard(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBoard_Notation_RoundTrip(t *testing.T) {
+	text := "1*?\n11F\n..X\n"
+	board, err := ParseBoard(text)
+	if err != nil {
+		t.Fatalf("ParseBoard() error = %v", err)
+	}
+
+	if got := board.Notation(); got != text {
+		t.Errorf("Notation() = %q, want %q", got, text)
+	}
+
+	visible := board.VisibleNotation()
+	if strings.Contains(visible, "*") {
+		t.Errorf("VisibleNotation() must not reveal hidden mines: %q", visible)
+	}
+	if visible != "1??\n11F\n..X\n" {
+		t.Errorf("VisibleNotation() = %q", visible)
+	}
+}
+
+func TestBoard_Notation_FlagsWithMines(t *testing.T) {
+	// 旗の下の地雷とAIの印は、旗があっても文字表記を往復して残る。地雷が重なったマスでも数を失わない
+	tests := []struct {
+		name    string
+		text    string
+		visible string
+		mines   int
+	}{
+		{"flag on a safe cell", "2F\n**\n", "2F\n??\n", 2},
+		{"flag on a mine", "2!\n**\n", "2F\n??\n", 3},
+		{"flag and mark", "2&\n*M\n", "2&\n?M\n", 3},
+		{
+			"flag on several mines",
+			"# max-cell-mines: 2\n# flagged: 0,1\n2B\n??\n",
+			"# max-cell-mines: 2\n2F\n??\n",
+			2,
+		},
+		{
+			"flag and mark on several mines",
+			"# max-cell-mines: 2\n# flagged: 0,1\n# marked: 0,1\n2B\n??\n",
+			"# max-cell-mines: 2\n2&\n??\n",
+			2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := ParseBoard(tt.text)
+			if err != nil {
+				t.Fatalf("ParseBoard() error = %v", err)
+			}
+			if board.Mines != tt.mines {
+				t.Errorf("Mines = %d, want %d", board.Mines, tt.mines)
+			}
+			if got := board.Notation(); got != tt.text {
+				t.Errorf("Notation() = %q, want %q", got, tt.text)
+			}
+			if got := board.VisibleNotation(); got != tt.visible {
+				t.Errorf("VisibleNotation() = %q, want %q", got, tt.visible)
+			}
+			cell := board.GetCell(Position{Row: 0, Col: 1})
+			if !cell.IsFlagged {
+				t.Error("the flag should survive the round trip")
+			}
+			if cell.IsMarked && cell.MarkCount() != cell.MineCount() {
+				t.Errorf("mark count = %d, want the %d mines of the cell", cell.MarkCount(), cell.MineCount())
+			}
+		})
+	}
+}
+
+func TestBoard_Notation_MultiMineStates(t *testing.T) {
+	// 地雷が重なったマスは旗を立てても開いても地雷の数を失わず、文字表記を往復する
+	board, err := ParseBoard("# max-cell-mines: 3\n2??\n???\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flagged, exploded := board.GetCell(Position{Row: 0, Col: 1}), board.GetCell(Position{Row: 1, Col: 2})
+	for range 3 {
+		flagged.AddMine()
+	}
+	exploded.AddMine()
+	exploded.AddMine()
+	flagged.IsFlagged = true
+	exploded.IsRevealed = true
+	board.Mines = 5
+
+	text := board.Notation()
+	want := "# max-cell-mines: 3\n# flagged: 0,1\n# exploded: 1,2\n2C?\n??B\n"
+	if text != want {
+		t.Fatalf("Notation() = %q, want %q", text, want)
+	}
+	parsed, err := ParseBoard(text)
+	if err != nil {
+		t.Fatalf("ParseBoard() error = %v", err)
+	}
+	if parsed.Mines != board.Mines {
+		t.Errorf("Mines = %d, want %d", parsed.Mines, board.Mines)
+	}
+	if got := parsed.Notation(); got != text {
+		t.Errorf("round trip = %q, want %q", got, text)
+	}
+	if got := parsed.VisibleNotation(); got != "# max-cell-mines: 3\n2F?\n??X\n" {
+		t.Errorf("VisibleNotation() = %q", got)
+	}
+	if got := parsed.GetCell(Position{Row: 1, Col: 1}).Adjacent; got != 5 {
+		t.Errorf("Adjacent of (1,1) = %d, want 5", got)
+	}
+
+	for _, bad := range []string{"# flagged: 0,1\nB?\n", "# marked: x\nB?\n", "# exploded: 0,5\nB?\n"} {
+		if _, err := ParseBoard("# max-cell-mines: 2\n" + bad); err == nil {
+			t.Errorf("ParseBoard(%q) should fail", bad)
+		}
+	}
+}
+
+func TestNotationSymbols_CoverNotation(t *testing.T) {
+	// 文字表記に出るすべての記号が一覧にあり、VisibleNotation に出る記号は Hidden でない
+	board, err := ParseBoard("# neighborhood: radius2\n# max-cell-mines: 2\n" +
+		"*M&*B....?\n**a**....F\n**!**...1*\n......X...\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	covered := func(ch rune, visibleOnly bool) bool {
+		for _, s := range NotationSymbols {
+			if visibleOnly && s.Hidden {
+				continue
+			}
+			from, to, _ := strings.Cut(s.Symbol, "-")
+			if to == "" {
+				to = from
+			}
+			if string(ch) >= from && string(ch) <= to {
+				return true
+			}
+		}
+		return false
+	}
+	tests := []struct {
+		name        string
+		text        string
+		visibleOnly bool
+	}{
+		{"notation", board.Notation(), false},
+		{"visible notation", board.VisibleNotation(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, line := range strings.Split(strings.TrimSpace(tt.text), "\n") {
+				if strings.HasPrefix(line, "#") {
+					continue
+				}
+				for _, ch := range line {
+					if !covered(ch, tt.visibleOnly) {
+						t.Errorf("symbol %q in %q is not in NotationSymbols", ch, line)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Topology は盤面のマスのつながり方。どのマスが隣接するかを決める.
+// 盤面の数字・連鎖して開く範囲・ソルバーの推論はすべてこの隣接関係に従う.
+type Topology interface {
+	// Name は設定や盤面の文字表記で使うキー.
+	Name() string
+	// Neighbors は width x height の盤面での pos の隣接マスを返す。pos自身と重複は含まない.
+	Neighbors(pos Position, width, height int) []Position
+}
+
+var (
+	// SquareTopology は端で途切れる正方形のマス目（周囲8マス）.
+	SquareTopology Topology = squareTopology{}
+	// TorusTopology は上下左右の端がつながったマス目（周囲8マス）.
+	TorusTopology Topology = torusTopology{}
+	// HexTopology は奇数行を半マス右にずらした六角形のマス目（周囲6マス）.
+	HexTopology Topology = hexTopology{}
+	// TriangleTopology は上向きと下向きの三角形を交互に並べたマス目（頂点を共有する12マス）.
+	// (row+col) が偶数のマスが上向き.
+	TriangleTopology Topology = triangleTopology{}
+)
+
+// Topologies は組み込みのトポロジーの一覧.
+var Topologies = []Topology{SquareTopology, TorusTopology, HexTopology, TriangleTopology}
+
+// ParseTopology は名前からトポロジーを取得する。空文字は正方形のマス目.
+func ParseTopology(name string) (Topology, error) {
+	if name == "" {
+		return SquareTopology, nil
+	}
+	for _, t := range Topologies {
+		if strings.EqualFold(t.Name(), name) {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown topology %q", name)
+}
+
+// IsUpTriangle は三角形のマス目で pos が上向きの三角形かどうか.
+func IsUpTriangle(pos Position) bool {
+	return (pos.Row+pos.Col)%2 == 0
+}
+
+type squareTopology struct{}
+
+func (squareTopology) Name() string { return "square" }
+
+func (squareTopology) Neighbors(pos Position, width, height int) []Position {
+	return offsetNeighbors(pos, width, height, false, squareOffsets)
+}
+
+type torusTopology struct{}
+
+func (torusTopology) Name() string { return "torus" }
+
+func (torusTopology) Neighbors(pos Position, width, height int) []Position {
+	return offsetNeighbors(pos, width, height, true, squareOffsets)
+}
+
+type hexTopology struct{}
+
+func (hexTopology) Name() string { return "hex" }
+
+func (hexTopology) Neighbors(pos Position, width, height int) []Position {
+	offsets := hexEvenOffsets
+	if pos.Row%2 != 0 {
+		offsets = hexOddOffsets
+	}
+	return offsetNeighbors(pos, width, height, false, offsets)
+}
+
+type triangleTopology struct{}
+
+func (triangleTopology) Name() string { return "triangle" }
+
+func (triangleTopology) Neighbors(pos Position, width, height int) []Position {
+	offsets := triangleDownOffsets
+	if IsUpTriangle(pos) {
+		offsets = triangleUpOffsets
+	}
+	return offsetNeighbors(pos, width, height, false, offsets)
+}
+
+var squareOffsets = []Position{
+	{-1, -1}, {-1, 0}, {-1, 1},
+	{0, -1}, {0, 1},
+	{1, -1}, {1, 0}, {1, 1},
+}
+
+// 六角形のマス目は偶数行と奇数行で斜めの隣接マスの列がずれる.
+var (
+	hexEvenOffsets = []Position{{-1, -1}, {-1, 0}, {0, -1}, {0, 1}, {1, -1}, {1, 0}}
+	hexOddOffsets  = []Position{{-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, 0}, {1, 1}}
+)
+
+// 上向きの三角形は頂点側（上の行）で3マス、底辺側（下の行）で5マスと接する。下向きはその逆.
+var (
+	triangleUpOffsets = []Position{
+		{-1, -1}, {-1, 0}, {-1, 1},
+		{0, -2}, {0, -1}, {0, 1}, {0, 2},
+		{1, -2}, {1, -1}, {1, 0}, {1, 1}, {1, 2},
+	}
+	triangleDownOffsets = []Position{
+		{-1, -2}, {-1, -1}, {-1, 0}, {-1, 1}, {-1, 2},
+		{0, -2}, {0, -1}, {0, 1}, {0, 2},
+		{1, -1}, {1, 0}, {1, 1},
+	}
+)
+
+// offsetNeighbors は pos からの相対位置を盤面内のマスに変換する.
+// wrap が true なら端を反対側につなげ、小さな盤面で同じマスに重なった分は除く.
+func offsetNeighbors(pos Position, width, height int, wrap bool, offsets []Position) []Position {
+	positions := make([]Position, 0, len(offsets))
+	for _, d := range offsets {
+		p := Position{pos.Row + d.Row, pos.Col + d.Col}
+		if wrap {
+			p = Position{(p.Row%height + height) % height, (p.Col%width + width) % width}
+			if p == pos || containsPosition(positions, p) {
+				continue
+			}
+		} else if p.Row < 0 || p.Row >= height || p.Col < 0 || p.Col >= width {
+			continue
+		}
+		positions = append(positions, p)
+	}
+	return positions
+}
+
+func containsPosition(positions []Position, pos Position) bool {
+	for _, p := range positions {
+		if p == pos {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTopology_Neighbors(t *testing.T) {
+	tests := []struct {
+		name      string
+		topology  Topology
+		pos       Position
+		wantCount int
+	}{
+		{"square center", SquareTopology, Position{2, 2}, 8},
+		{"square corner", SquareTopology, Position{0, 0}, 3},
+		{"torus corner wraps", TorusTopology, Position{0, 0}, 8},
+		{"hex even row", HexTopology, Position{2, 2}, 6},
+		{"hex odd row", HexTopology, Position{3, 2}, 6},
+		{"hex corner", HexTopology, Position{0, 0}, 2},
+		{"triangle up", TriangleTopology, Position{2, 2}, 12},
+		{"triangle down", TriangleTopology, Position{2, 3}, 12},
+		{"triangle corner", TriangleTopology, Position{0, 0}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.topology.Neighbors(tt.pos, 6, 6)
+			if len(got) != tt.wantCount {
+				t.Errorf("Neighbors(%v) = %v, want %d positions", tt.pos, got, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestTopology_NeighborsAreSymmetric(t *testing.T) {
+	// aがbの隣ならbもaの隣でないと、数字と推論が食い違う
+	for _, topology := range Topologies {
+		t.Run(topology.Name(), func(t *testing.T) {
+			for _, size := range [][2]int{{5, 4}, {2, 2}, {1, 3}} {
+				width, height := size[0], size[1]
+				for row := 0; row < height; row++ {
+					for col := 0; col < width; col++ {
+						pos := Position{row, col}
+						for _, adj := range topology.Neighbors(pos, width, height) {
+							if adj == pos {
+								t.Errorf("%dx%d: %v is its own neighbor", width, height, pos)
+							}
+							if !containsPosition(topology.Neighbors(adj, width, height), pos) {
+								t.Errorf("%dx%d: %v is next to %v but not vice versa", width, height, adj, pos)
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTopology_TorusSmallBoardHasNoDuplicates(t *testing.T) {
+	// 2x2のトーラスでは上下左右が同じマスに重なる
+	got := TorusTopology.Neighbors(Position{0, 0}, 2, 2)
+	if len(got) != 3 {
+		t.Errorf("Neighbors() = %v, want the 3 other cells", got)
+	}
+}
+
+func TestParseTopology(t *testing.T) {
+	for _, topology := range Topologies {
+		if got, err := ParseTopology(topology.Name()); err != nil || got != topology {
+			t.Errorf("ParseTopology(%q) = %v, %v", topology.Name(), got, err)
+		}
+	}
+	if got, err := ParseTopology(""); err != nil || got != SquareTopology {
+		t.Errorf("ParseTopology(\"\") = %v, %v, want square", got, err)
+	}
+	if _, err := ParseTopology("sphere"); err == nil {
+		t.Error("ParseTopology(\"sphere\") should fail")
+	}
+}
+
+func TestBoard_InitializeWithRand_Topology(t *testing.T) {
+	for _, topology := range Topologies {
+		t.Run(topology.Name(), func(t *testing.T) {
+			board := NewBoardWithTopology(8, 8, 40, topology)
+			first := Position{0, 0}
+			board.InitializeWithRand(first, rand.New(rand.NewSource(1)))
+
+			// 最初のクリックの隣接マスはトポロジーに従って安全になる
+			for _, adj := range append(board.GetAdjacentPositions(first), first) {
+				if board.Cells[adj.Row][adj.Col].IsMine {
+					t.Errorf("%v next to the first click is a mine", adj)
+				}
+			}
+			for i := range board.Cells {
+				for j, cell := range board.Cells[i] {
+					if cell.IsMine {
+						continue
+					}
+					if want := board.countAdjacentMines(Position{i, j}); cell.Adjacent != want {
+						t.Errorf("cell (%d,%d) Adjacent = %d, want %d", i, j, cell.Adjacent, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBoard_InitializeWithRand_TooManyMines(t *testing.T) {
+	// 三角形のマス目は最初のクリックの周囲が12マスあるので、置ける地雷が減る
+	board := NewBoardWithTopology(5, 5, 24, TriangleTopology)
+	board.InitializeWithRand(Position{2, 2}, rand.New(rand.NewSource(1)))
+	if board.Mines != 25-13 {
+		t.Errorf("Mines = %d, want %d", board.Mines, 25-13)
+	}
+}
+
+func TestParseBoard_Topology(t *testing.T) {
+	text := "# topology: torus\n1??\n???\n??*\n"
+	board, err := ParseBoard(text)
+	if err != nil {
+		t.Fatalf("ParseBoard() error = %v", err)
+	}
+	if board.GetTopology() != TorusTopology {
+		t.Errorf("topology = %v, want torus", board.GetTopology().Name())
+	}
+	// トーラスでは(0,0)と(2,2)が隣り合う
+	if c := board.Cells[0][1]; c.Adjacent != 1 {
+		t.Errorf("cell (0,1) Adjacent = %d, want 1", c.Adjacent)
+	}
+	if got := board.Notation(); got != text {
+		t.Errorf("Notation() = %q, want %q", got, text)
+	}
+	if clone := board.Clone(); clone.GetTopology() != TorusTopology {
+		t.Error("Clone() should keep the topology")
+	}
+
+	if _, err := ParseBoard("# topology: sphere\n??\n"); err == nil {
+		t.Error("ParseBoard() with an unknown topology should fail")
+	}
+}
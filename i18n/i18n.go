@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Lang はUIの表示言語.
+type Lang string
+
+const (
+	English  Lang = "en"
+	Japanese Lang = "ja"
+)
+
+// Languages は対応している言語の一覧.
+var Languages = []Lang{English, Japanese}
+
+var catalogs = map[Lang]map[string]string{
+	English:  english,
+	Japanese: japanese,
+}
+
+// Catalog は1つの言語のメッセージ集.
+type Catalog struct {
+	lang     Lang
+	messages map[string]string
+}
+
+// New は指定された言語のカタログを作成。未対応の言語なら英語を使う.
+func New(lang Lang) *Catalog {
+	messages, ok := catalogs[lang]
+	if !ok {
+		lang = English
+		messages = english
+	}
+	return &Catalog{lang: lang, messages: messages}
+}
+
+// Lang はカタログの言語を返す.
+func (c *Catalog) Lang() Lang {
+	return c.lang
+}
+
+// T はキーに対応するメッセージを返す.
+// 引数があればfmt.Sprintfで埋め込み、キーが見つからなければ英語、それもなければキーそのものを返す.
+func (c *Catalog) T(key string, args ...any) string {
+	msg, ok := c.messages[key]
+	if !ok {
+		msg, ok = english[key]
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Parse は言語コードを解釈する（"ja", "ja_JP.UTF-8", "en-US" など）.
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(code)
+	for _, lang := range Languages {
+		if code == string(lang) ||
+			strings.HasPrefix(code, string(lang)+"_") ||
+			strings.HasPrefix(code, string(lang)+"-") ||
+			strings.HasPrefix(code, string(lang)+".") {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Detect は設定値、LC_ALL、LC_MESSAGES、LANGの順に表示言語を決める.
+// どれからも決まらなければ英語.
+func Detect(configured string) Lang {
+	candidates := []string{
+		configured,
+		os.Getenv("LC_ALL"),
+		os.Getenv("LC_MESSAGES"),
+		os.Getenv("LANG"),
+	}
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		if lang, ok := Parse(c); ok {
+			return lang
+		}
+	}
+	return English
+}
@@ -0,0 +1,80 @@
+package i18n
+
+import "testing"
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for lang, messages := range catalogs {
+		for key := range english {
+			if _, ok := messages[key]; !ok {
+				t.Errorf("%s catalog is missing key %q", lang, key)
+			}
+		}
+		for key := range messages {
+			if _, ok := english[key]; !ok {
+				t.Errorf("%s catalog has extra key %q", lang, key)
+			}
+		}
+	}
+}
+
+func TestCatalog_T(t *testing.T) {
+	ja := New(Japanese)
+
+	if got := ja.T("difficulty.beginner"); got != "初級" {
+		t.Errorf("T(difficulty.beginner) = %q, want 初級", got)
+	}
+	if got := New(English).T("header", 3, 1, 5, "Expert", "default"); got != "Mines: 3  Time: 01:05  Difficulty: Expert  Theme: default" {
+		t.Errorf("T(header) = %q", got)
+	}
+	// 未知のキーはキーそのものを返す
+	if got := ja.T("no.such.key"); got != "no.such.key" {
+		t.Errorf("T(no.such.key) = %q", got)
+	}
+}
+
+func TestNew_UnknownLanguageFallsBackToEnglish(t *testing.T) {
+	if got := New("xx").Lang(); got != English {
+		t.Errorf("Lang() = %q, want %q", got, English)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		code string
+		want Lang
+		ok   bool
+	}{
+		{"ja", Japanese, true},
+		{"ja_JP.UTF-8", Japanese, true},
+		{"en-US", English, true},
+		{"C.UTF-8", "", false},
+		{"jav", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := Parse(tt.code)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Parse(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "ja_JP.UTF-8")
+
+	if got := Detect(""); got != Japanese {
+		t.Errorf("Detect() = %q, want %q from LANG", got, Japanese)
+	}
+	if got := Detect("en"); got != English {
+		t.Errorf("Detect(en) = %q, want configured %q", got, English)
+	}
+
+	t.Setenv("LANG", "C")
+	if got := Detect(""); got != English {
+		t.Errorf("Detect() = %q, want fallback %q", got, English)
+	}
+}
@@ -0,0 +1,157 @@
+package i18n
+
+var english = map[string]string{
+	"title":  "AI Minesweeper - the minesweeper the AI spoils for you",
+	"header": "Mines: %d  Time: %02d:%02d  Difficulty: %s  Theme: %s",
+
+	"header.rating":    "Rating: %s, %d guesses",
+	"rule.none":        "no logic",
+	"rule.trivial":     "trivial",
+	"rule.subset":      "subset",
+	"rule.global":      "global count",
+	"rule.enumeration": "enumeration",
+
+	"header.neighborhood":  "Numbers count: %s",
+	"neighborhood.knight":  "knight moves",
+	"neighborhood.cross":   "up/down/left/right",
+	"neighborhood.radius2": "2 cells around",
+
+	"header.lives": "Lives: %d",
+
+	"infinite.header": "Score: %d  Position: (%d,%d)  Seed: %d  Density: %.0f%%  Theme: %s",
+	"infinite.lost":   "💥 Game over! You cleared %d cells before stepping on a mine.",
+
+	"difficulty.beginner":     "Beginner",
+	"difficulty.intermediate": "Intermediate",
+	"difficulty.expert":       "Expert",
+	"difficulty.custom":       "Custom",
+
+	"status.won":            "🎉 Congratulations! You cleared the board!",
+	"status.lost":           "💥 Game over! You stepped on a mine!",
+	"status.thinking":       "🤖 The AI is thinking...",
+	"status.your_turn":      "Your turn! Make your fateful choice...",
+	"status.hint":           "AI suggests (%d,%d): %.0f%% chance of a mine",
+	"status.auto":           "[auto]",
+	"status.contradiction":  "⚠ These flags contradict the numbers: %s",
+	"status.snapshot":       "📷 Saved the board image to %s",
+	"status.snapshot_error": "⚠ Could not save the board image: %v",
+
+	"lobby":         "Lobby: %s",
+	"lobby.playing": "%s (%s %d%%)",
+	"lobby.won":     "%s (cleared)",
+	"lobby.lost":    "%s (hit a mine)",
+
+	"race.title":   "Scoreboard (same board for everyone)",
+	"race.row":     "%d. %s  cells: %d (%d%%)  flags: %d  mines hit: %d  guesses: %d",
+	"race.winner":  "🏆 winner",
+	"race.cleared": "cleared",
+	"race.out":     "out",
+	"race.waiting": "Waiting for the others to finish this round. Press [%s] again when they are done.",
+
+	"coop":     "Playing together: %s",
+	"coop.you": "you",
+
+	"spectate.watching": "👀 Watching a broadcast game (read only)",
+	"spectate.ended":    "The broadcast has ended.",
+	"spectate.deduced":  "AI deduced: safe %s  mines %s",
+
+	"help.title":      "Key bindings",
+	"help.move":       "Move cursor",
+	"help.difficulty": "Change difficulty",
+
+	"key.space": "space",
+
+	"action.up":           "Move up",
+	"action.down":         "Move down",
+	"action.left":         "Move left",
+	"action.right":        "Move right",
+	"action.reveal":       "Reveal cell",
+	"action.flag":         "Toggle flag",
+	"action.new_game":     "New game",
+	"action.theme":        "Switch theme",
+	"action.beginner":     "Beginner",
+	"action.intermediate": "Intermediate",
+	"action.expert":       "Expert",
+	"action.auto":         "Toggle auto play",
+	"action.snapshot":     "Save board image",
+	"action.help":         "Help",
+	"action.quit":         "Quit",
+}
+
+var japanese = map[string]string{
+	"title":  "AIマインスイーパー - AIにネタバレされるマインスイーパー",
+	"header": "地雷: %d  時間: %02d:%02d  難易度: %s  テーマ: %s",
+
+	"header.rating":    "難しさ: %s・推測%d回",
+	"rule.none":        "推論不要",
+	"rule.trivial":     "初歩",
+	"rule.subset":      "包含",
+	"rule.global":      "残り地雷数",
+	"rule.enumeration": "全列挙",
+
+	"header.neighborhood":  "数える範囲: %s",
+	"neighborhood.knight":  "ナイトの動き",
+	"neighborhood.cross":   "上下左右",
+	"neighborhood.radius2": "周囲2マス",
+
+	"header.lives": "ライフ: %d",
+
+	"infinite.header": "スコア: %d  位置: (%d,%d)  シード: %d  密度: %.0f%%  テーマ: %s",
+	"infinite.lost":   "💥 ゲームオーバー！地雷を踏むまでに%dマス開きました。",
+
+	"difficulty.beginner":     "初級",
+	"difficulty.intermediate": "中級",
+	"difficulty.expert":       "上級",
+	"difficulty.custom":       "カスタム",
+
+	"status.won":            "🎉 おめでとうございます！クリアしました！",
+	"status.lost":           "💥 ゲームオーバー！地雷を踏みました！",
+	"status.thinking":       "🤖 AIが考え中...",
+	"status.your_turn":      "あなたの番です！運命の選択を...",
+	"status.hint":           "AIのおすすめ: (%d,%d) 地雷確率 %.0f%%",
+	"status.auto":           "[自動プレイ]",
+	"status.contradiction":  "⚠ 数字と矛盾する旗があります: %s",
+	"status.snapshot":       "📷 盤面の画像を %s に保存しました",
+	"status.snapshot_error": "⚠ 盤面の画像を保存できませんでした: %v",
+
+	"lobby":         "ロビー: %s",
+	"lobby.playing": "%s (%s %d%%)",
+	"lobby.won":     "%s (クリア)",
+	"lobby.lost":    "%s (地雷を踏んだ)",
+
+	"race.title":   "スコアボード（全員が同じ盤面）",
+	"race.row":     "%d. %s  開いたマス: %d (%d%%)  旗: %d  踏んだ地雷: %d  推測: %d",
+	"race.winner":  "🏆 勝者",
+	"race.cleared": "クリア",
+	"race.out":     "脱落",
+	"race.waiting": "ほかのプレイヤーがこのラウンドを終えるのを待っています。終わったらもう一度 [%s] を押してください。",
+
+	"coop":     "一緒に遊んでいるプレイヤー: %s",
+	"coop.you": "あなた",
+
+	"spectate.watching": "👀 配信中のゲームを観戦しています（操作はできません）",
+	"spectate.ended":    "配信が終わりました。",
+	"spectate.deduced":  "AIが確定させたマス: 安全 %s  地雷 %s",
+
+	"help.title":      "キー操作",
+	"help.move":       "カーソル移動",
+	"help.difficulty": "難易度変更",
+
+	"key.space": "スペース",
+
+	"action.up":           "上へ移動",
+	"action.down":         "下へ移動",
+	"action.left":         "左へ移動",
+	"action.right":        "右へ移動",
+	"action.reveal":       "マスを開く",
+	"action.flag":         "旗を立てる",
+	"action.new_game":     "新しいゲーム",
+	"action.theme":        "テーマ切替",
+	"action.beginner":     "初級",
+	"action.intermediate": "中級",
+	"action.expert":       "上級",
+	"action.auto":         "自動プレイ切替",
+	"action.snapshot":     "盤面の画像を保存",
+	"action.help":         "ヘルプ",
+	"action.quit":         "終了",
+}
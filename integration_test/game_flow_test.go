@@ -73,9 +73,9 @@ func playBeginnerGame(t *testing.T, g *game.Game) {
 		}
 	}
 
-	// 地雷セルにフラグを立てる
+	// 地雷セルに印を付ける
 	for _, pos := range result.MineCells {
-		g.ToggleFlag(pos)
+		g.MarkMine(pos)
 	}
 
 	// ゲーム状態の確認
@@ -114,7 +114,7 @@ func playIntermediateGame(t *testing.T, g *game.Game) {
 			if len(unrevealedPositions) > 0 {
 				// 最初の未開放セルをクリック（テストなので決定的に）
 				for _, pos := range unrevealedPositions {
-					if !g.Board.GetCell(pos).IsFlagged {
+					if cell := g.Board.GetCell(pos); !cell.IsFlagged && !cell.IsMarked {
 						g.Click(pos)
 						break
 					}
@@ -128,7 +128,7 @@ func playIntermediateGame(t *testing.T, g *game.Game) {
 				}
 			}
 			for _, pos := range result.MineCells {
-				g.ToggleFlag(pos)
+				g.MarkMine(pos)
 			}
 		}
 	}
@@ -201,9 +201,9 @@ func TestGameWithAISolver(t *testing.T) {
 			}
 		}
 
-		// 地雷にフラグ
+		// 地雷に印を付ける
 		for _, pos := range result.MineCells {
-			g.ToggleFlag(pos)
+			g.MarkMine(pos)
 		}
 
 		// 勝利条件のチェック
@@ -90,11 +90,9 @@ func TestSolverCompleteGame(t *testing.T) {
 						}
 					}
 
-					// 地雷にフラグ
+					// 地雷に印を付ける
 					for _, pos := range result.MineCells {
-						if !g.Board.GetCell(pos).IsFlagged {
-							g.ToggleFlag(pos)
-						}
+						g.MarkMine(pos)
 					}
 				} else {
 					// 推論できない場合は終了
@@ -167,7 +165,7 @@ func TestSolverPerformance(t *testing.T) {
 				}
 
 				for _, pos := range result.MineCells {
-					g.ToggleFlag(pos)
+					g.MarkMine(pos)
 				}
 			}
 
@@ -211,11 +209,9 @@ func TestSolverWithAIAssist(t *testing.T) {
 					}
 				}
 
-				// 自動的に地雷にフラグを立てる
+				// 自動的に地雷に印を付ける
 				for _, pos := range result.MineCells {
-					if !g.Board.GetCell(pos).IsFlagged {
-						g.ToggleFlag(pos)
-					}
+					g.MarkMine(pos)
 				}
 
 				// AI支援の動作をログ
@@ -361,7 +357,7 @@ func TestInteractiveGameplay(t *testing.T) {
 						}
 					}
 					for _, pos := range result.MineCells {
-						g.ToggleFlag(pos)
+						g.MarkMine(pos)
 					}
 					return true
 				}
@@ -376,7 +372,7 @@ func TestInteractiveGameplay(t *testing.T) {
 				for i := 0; i < g.Board.Height; i++ {
 					for j := 0; j < g.Board.Width; j++ {
 						cell := g.Board.Cells[i][j]
-						if !cell.IsRevealed && !cell.IsFlagged {
+						if !cell.IsRevealed && !cell.IsFlagged && !cell.IsMarked {
 							g.Click(game.Position{Row: i, Col: j})
 							return true
 						}
@@ -1,18 +1,11 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
-	tea "github.com/charmbracelet/bubbletea"
-
-	"github.com/r-horie/ai-minesweeper/tui"
+	"github.com/r-horie/ai-minesweeper/cli"
 )
 
 func main() {
-	p := tea.NewProgram(tui.NewModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
-	}
+	os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
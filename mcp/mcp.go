@@ -0,0 +1,171 @@
+// Package mcp はLLMのエージェントがマインスイーパーをプレイするためのModel Context Protocolのサーバー.
+//
+// 標準入出力で1行に1つのJSON-RPC 2.0のメッセージをやり取りし、ゲームの操作をツールとして公開する.
+// 対応するメソッドは initialize / ping / tools/list / tools/call で、ほかの通知は読み捨てる.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"slices"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// ProtocolVersion はクライアントが対応していない版を求めたときに返すプロトコルの版.
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions は対応しているプロトコルの版。クライアントが求めた版がこの中にあればそれを使う.
+var supportedVersions = []string{"2024-11-05", "2025-03-26", ProtocolVersion}
+
+// JSON-RPCのエラーコード.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// jsonrpcVersion はJSON-RPCの版.
+const jsonrpcVersion = "2.0"
+
+// maxMessageBytes は1つのメッセージの大きさの上限.
+const maxMessageBytes = 1 << 20
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server は1つのゲームを持ち、ツールの呼び出しでそのゲームを進める.
+type Server struct {
+	game *game.Game
+}
+
+// NewServer は指定した難易度とルールで最初のゲームを始めたサーバーを作成する.
+func NewServer(difficulty game.Difficulty, options game.Options) *Server {
+	return &Server{game: game.NewGameWithOptions(difficulty, options)}
+}
+
+// Run は r からメッセージを読み、応答を w に書く。入力の終わりで戻る.
+func (s *Server) Run(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageBytes)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		resp, ok := s.handle(scanner.Bytes())
+		if !ok {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle はメッセージを1つ処理して応答を返す。通知には応答しないので false を返す.
+func (s *Server) handle(data []byte) (response, bool) {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), &rpcError{codeParseError, err.Error()}), true
+	}
+	if req.JSONRPC != jsonrpcVersion || req.Method == "" {
+		return errorResponse(idOrNull(req.ID), &rpcError{codeInvalidRequest, "not a JSON-RPC 2.0 request"}), true
+	}
+	if len(req.ID) == 0 {
+		return response{}, false
+	}
+
+	result, err := s.call(req.Method, req.Params)
+	if err != nil {
+		return errorResponse(req.ID, err), true
+	}
+	return response{JSONRPC: jsonrpcVersion, ID: req.ID, Result: result}, true
+}
+
+// call はメソッドを実行して結果を返す.
+func (s *Server) call(method string, params json.RawMessage) (any, *rpcError) {
+	switch method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		version := ProtocolVersion
+		if slices.Contains(supportedVersions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "ai-minesweeper", "version": buildVersion()},
+			"instructions":    instructions,
+		}, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.callTool(p.Name, p.Arguments)
+	}
+	return nil, &rpcError{codeMethodNotFound, fmt.Sprintf("method %q not found", method)}
+}
+
+// buildVersion はビルドしたモジュールの版。go install でなければ "(devel)" になる.
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+func unmarshalParams(params json.RawMessage, v any) *rpcError {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{codeInvalidParams, err.Error()}
+	}
+	return nil
+}
+
+func errorResponse(id json.RawMessage, err *rpcError) response {
+	return response{JSONRPC: jsonrpcVersion, ID: id, Error: err}
+}
+
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
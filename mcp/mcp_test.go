@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// run はメッセージを1行ずつ送り、応答を順に返す.
+func run(t *testing.T, s *Server, messages ...string) []map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.Run(strings.NewReader(strings.Join(messages, "\n")), &out); err != nil {
+		t.Fatal(err)
+	}
+	var responses []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]any
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// toolText はツールの呼び出し結果の本文.
+func toolText(t *testing.T, resp map[string]any) (string, bool) {
+	t.Helper()
+	result, ok := resp["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("response has no result: %v", resp)
+	}
+	content := result["content"].([]any)[0].(map[string]any)
+	return content["text"].(string), result["isError"].(bool)
+}
+
+func TestServer_Protocol(t *testing.T) {
+	s := NewServer(game.Beginner, game.Options{Seed: 42})
+	got := run(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":"p","method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`not json`,
+	)
+	// 通知には応答しない
+	if len(got) != 5 {
+		t.Fatalf("got %d responses, want 5: %v", len(got), got)
+	}
+
+	initResult := got[0]["result"].(map[string]any)
+	if initResult["protocolVersion"] != "2024-11-05" {
+		t.Errorf("protocolVersion = %v, want the client's version", initResult["protocolVersion"])
+	}
+	if tools := got[1]["result"].(map[string]any)["tools"].([]any); len(tools) != 5 {
+		t.Errorf("tools/list returned %d tools, want 5", len(tools))
+	}
+	if got[2]["id"] != "p" || got[2]["result"] == nil {
+		t.Errorf("ping response = %v", got[2])
+	}
+	if code := got[3]["error"].(map[string]any)["code"]; code != float64(codeMethodNotFound) {
+		t.Errorf("unknown method error code = %v", code)
+	}
+	if code := got[4]["error"].(map[string]any)["code"]; code != float64(codeParseError) || got[4]["id"] != nil {
+		t.Errorf("parse error response = %v", got[4])
+	}
+}
+
+func TestServer_Tools(t *testing.T) {
+	s := NewServer(game.Beginner, game.Options{Seed: 42})
+	got := run(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_hint"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"reveal","arguments":{"row":4,"col":4}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_hint","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"reveal","arguments":{"row":4,"col":4}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"flag","arguments":{"row":20,"col":0}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"new_game","arguments":{"difficulty":"expert"}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"undo"}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"reveal","arguments":{"x":1}}}`,
+		`{"jsonrpc":"2.0","id":9,"method":"tools/call","params":{"name":"new_game","arguments":{"width":100000,"height":100000}}}`,
+	)
+
+	if text, _ := toolText(t, got[0]); !strings.Contains(text, "center (4,4)") {
+		t.Errorf("hint before the first click = %q", text)
+	}
+	text, isError := toolText(t, got[1])
+	if isError || !strings.Contains(text, "State: playing") || !strings.Contains(text, "0 ???1.....") {
+		t.Errorf("reveal result = %q", text)
+	}
+	if text, _ := toolText(t, got[2]); !strings.Contains(text, "Certainly") {
+		t.Errorf("hint after the first click = %q", text)
+	}
+	// ゲームの操作として誤った呼び出しはツールのエラーになる
+	for _, i := range []int{3, 4} {
+		if text, isError := toolText(t, got[i]); !isError {
+			t.Errorf("response %d should be a tool error, got %q", i+1, text)
+		}
+	}
+	// 凡例は盤面の文字表記に出る記号をすべて説明する
+	for _, symbol := range []string{"? hidden", "F flag", "M mine", "& flag", "1-9", "a-z", "X exploded"} {
+		if !strings.Contains(text, symbol) {
+			t.Errorf("legend should explain %q: %q", symbol, text)
+		}
+	}
+	if text, _ := toolText(t, got[5]); !strings.Contains(text, "16 rows x 30 columns, 99 mines") {
+		t.Errorf("new_game result = %q", text)
+	}
+	if text, isError := toolText(t, got[8]); !isError {
+		t.Errorf("a huge new_game should be a tool error, got %q", text)
+	}
+	// 存在しないツールや読めない引数はJSON-RPCのエラーになる
+	for _, i := range []int{6, 7} {
+		if got[i]["error"] == nil {
+			t.Errorf("response %d should be an error: %v", i+1, got[i])
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+// instructions はエージェントに伝えるサーバーの使い方.
+const instructions = "Play minesweeper. Call get_board to see the board, reveal cells you are sure are safe, " +
+	"flag cells you are sure are mines, and use get_hint only when you want the built-in solver's opinion. " +
+	"Rows and columns are numbered from 0, starting at the top-left corner."
+
+// legend は見えている盤面の記号の説明。盤面の文字表記の記号の一覧から作る.
+var legend = visibleLegend()
+
+func visibleLegend() string {
+	var symbols []string
+	for _, s := range game.NotationSymbols {
+		if !s.Hidden {
+			symbols = append(symbols, s.Symbol+" "+s.Description)
+		}
+	}
+	return "Legend: " + strings.Join(symbols, ", ") + "."
+}
+
+// ツールの名前.
+const (
+	toolNewGame  = "new_game"
+	toolReveal   = "reveal"
+	toolFlag     = "flag"
+	toolGetBoard = "get_board"
+	toolGetHint  = "get_hint"
+)
+
+// tool はツールの定義.
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// toolResult はツールの呼び出し結果。ゲームの操作として誤った呼び出しは IsError で伝える.
+type toolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// positionSchema は位置を受け取るツールの入力.
+var positionSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"row": map[string]any{"type": "integer", "description": "Row, from 0 at the top"},
+		"col": map[string]any{"type": "integer", "description": "Column, from 0 at the left"},
+	},
+	"required": []string{"row", "col"},
+}
+
+// emptySchema は引数のないツールの入力.
+var emptySchema = map[string]any{"type": "object", "properties": map[string]any{}}
+
+var tools = []tool{
+	{
+		Name:        toolNewGame,
+		Description: "Start a new game. Give a difficulty, or width, height and mines for a custom board.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"difficulty": map[string]any{"type": "string", "enum": []string{"beginner", "intermediate", "expert"}},
+				"width":      map[string]any{"type": "integer"},
+				"height":     map[string]any{"type": "integer"},
+				"mines":      map[string]any{"type": "integer"},
+				"seed":       map[string]any{"type": "integer", "description": "Seed for the mine layout, 0 for random"},
+			},
+		},
+	},
+	{
+		Name:        toolReveal,
+		Description: "Reveal a cell. The first reveal is always safe. Revealing a mine ends the game.",
+		InputSchema: positionSchema,
+	},
+	{
+		Name:        toolFlag,
+		Description: "Toggle a flag on a hidden cell you believe is a mine.",
+		InputSchema: positionSchema,
+	},
+	{
+		Name:        toolGetBoard,
+		Description: "Show the board and the game state.",
+		InputSchema: emptySchema,
+	},
+	{
+		Name:        toolGetHint,
+		Description: "Ask the built-in rule solver for cells that are certainly safe or mines, or the safest guess.",
+		InputSchema: emptySchema,
+	},
+}
+
+// newGameArgs は new_game の引数.
+type newGameArgs struct {
+	Difficulty string `json:"difficulty"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Mines      *int   `json:"mines"`
+	Seed       int64  `json:"seed"`
+}
+
+// callTool はツールを実行する。存在しないツールや読めない引数はJSON-RPCのエラーにする.
+func (s *Server) callTool(name string, arguments json.RawMessage) (any, *rpcError) {
+	var text string
+	var err error
+	switch name {
+	case toolNewGame:
+		var args newGameArgs
+		if rpcErr := decodeArgs(arguments, &args); rpcErr != nil {
+			return nil, rpcErr
+		}
+		text, err = s.newGame(args)
+	case toolReveal, toolFlag:
+		var pos game.Position
+		if rpcErr := decodeArgs(arguments, &pos); rpcErr != nil {
+			return nil, rpcErr
+		}
+		text, err = s.move(name, pos)
+	case toolGetBoard:
+		text = s.board()
+	case toolGetHint:
+		text = s.hint()
+	default:
+		return nil, &rpcError{codeInvalidParams, fmt.Sprintf("unknown tool %q", name)}
+	}
+	if err != nil {
+		return textResult(err.Error(), true), nil
+	}
+	return textResult(text, false), nil
+}
+
+func textResult(text string, isError bool) toolResult {
+	return toolResult{Content: []textContent{{Type: "text", Text: text}}, IsError: isError}
+}
+
+func decodeArgs(arguments json.RawMessage, v any) *rpcError {
+	if len(arguments) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(arguments))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &rpcError{codeInvalidParams, "invalid arguments: " + err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) newGame(args newGameArgs) (string, error) {
+	difficulty := game.Beginner
+	var err error
+	switch {
+	case args.Width != 0 || args.Height != 0:
+		mines := args.Width * args.Height * 15 / 100
+		if args.Mines != nil {
+			mines = *args.Mines
+		}
+		difficulty, err = game.NewLimitedDifficulty(args.Width, args.Height, mines)
+	case args.Difficulty != "":
+		difficulty, err = game.ParseDifficulty(args.Difficulty)
+	}
+	if err != nil {
+		return "", err
+	}
+	options := s.game.Options
+	options.Seed = args.Seed
+	if err := options.CheckMines(difficulty); err != nil {
+		return "", err
+	}
+	s.game = game.NewGameWithOptions(difficulty, options)
+	return "New game started.\n\n" + s.board(), nil
+}
+
+// move は reveal か flag を実行し、結果の説明と盤面を返す.
+func (s *Server) move(name string, pos game.Position) (string, error) {
+	g := s.game
+	if !g.Board.IsValidPosition(pos) {
+		return "", fmt.Errorf("(%d,%d) is outside the %dx%d board", pos.Row, pos.Col, g.Board.Height, g.Board.Width)
+	}
+	if g.State != game.Playing {
+		return "", fmt.Errorf("the game is over; call new_game to play again")
+	}
+
+	var summary string
+	if name == toolFlag {
+		g.ToggleFlag(pos)
+		summary = fmt.Sprintf("Toggled the flag at (%d,%d).", pos.Row, pos.Col)
+	} else {
+		if g.Board.GetCell(pos).IsRevealed {
+			return "", fmt.Errorf("(%d,%d) is already revealed", pos.Row, pos.Col)
+		}
+		revealed := g.Click(pos)
+		switch g.State {
+		case game.Lost:
+			summary = fmt.Sprintf("(%d,%d) was a mine. Game over.", pos.Row, pos.Col)
+		case game.Won:
+			summary = "All safe cells are revealed. You won!"
+		default:
+			summary = fmt.Sprintf("Revealed %d cells.", len(revealed))
+		}
+	}
+	return summary + "\n\n" + s.board(), nil
+}
+
+// board はゲームの状態と、行と列の番号を添えた盤面の文字表記を返す.
+func (s *Server) board() string {
+	g := s.game
+	b := g.Board
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "State: %s\n", g.State)
+	fmt.Fprintf(&sb, "Board: %d rows x %d columns, %d mines, %d not flagged\n", b.Height, b.Width, b.Mines,
+		g.GetRemainingMines())
+	sb.WriteString(legend + "\n\n")
+
+	rowWidth := len(fmt.Sprint(b.Height - 1))
+	if b.Width > 10 {
+		sb.WriteString(strings.Repeat(" ", rowWidth+1))
+		for col := 0; col < b.Width; col++ {
+			sb.WriteString(digitOrSpace(col / 10))
+		}
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(strings.Repeat(" ", rowWidth+1))
+	for col := 0; col < b.Width; col++ {
+		fmt.Fprint(&sb, col%10)
+	}
+	sb.WriteByte('\n')
+
+	rows := strings.Split(strings.TrimRight(b.VisibleNotation(), "\n"), "\n")
+	for i, row := range rows[len(rows)-b.Height:] {
+		fmt.Fprintf(&sb, "%*d %s\n", rowWidth, i, row)
+	}
+	return sb.String()
+}
+
+// digitOrSpace は列の番号の十の位。0なら空白にする.
+func digitOrSpace(n int) string {
+	if n == 0 {
+		return " "
+	}
+	return fmt.Sprint(n % 10)
+}
+
+// hint はルールのソルバーの意見を返す。盤面は変えない.
+func (s *Server) hint() string {
+	g := s.game
+	switch {
+	case g.State != game.Playing:
+		return "The game is over; call new_game to play again."
+	case g.FirstClick:
+		c := game.Position{Row: g.Board.Height / 2, Col: g.Board.Width / 2}
+		return fmt.Sprintf("Nothing is revealed yet. The first reveal is always safe; the center (%d,%d) is a good start.",
+			c.Row, c.Col)
+	}
+
+	sv := solver.NewSolver(g.Board)
+	result, rule := sv.SolveWith(solver.RuleEnumeration)
+	if result.CanProgress {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Found with the %s rule.\n", rule)
+		if len(result.SafeCells) > 0 {
+			fmt.Fprintf(&sb, "Certainly safe: %s\n", formatPositions(result.SafeCells))
+		}
+		if len(result.MineCells) > 0 {
+			fmt.Fprintf(&sb, "Certainly mines: %s\n", formatPositions(result.MineCells))
+		}
+		return sb.String()
+	}
+	if guess, ok := sv.Guess(solver.GuessLowestProbability); ok {
+		return fmt.Sprintf("No cell is certain. The safest guess is (%d,%d) with a %.0f%% chance of a mine.",
+			guess.Position.Row, guess.Position.Col, 100*guess.Probability)
+	}
+	return "No cell is certain and there is nothing left to guess."
+}
+
+func formatPositions(positions []game.Position) string {
+	parts := make([]string, len(positions))
+	for i, p := range positions {
+		parts[i] = fmt.Sprintf("(%d,%d)", p.Row, p.Col)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,22 @@
+package render
+
+// PNGの文字は外部のフォントに頼らず、数字と'%'と'?'だけの小さな点の字形で描く.
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+)
+
+var glyphs = map[byte][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'%': {"#.#", "..#", ".#.", "#..", "#.#"},
+	'?': {"###", "..#", ".##", "...", ".#."},
+}
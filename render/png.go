@@ -0,0 +1,134 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// PNG は盤面をPNGにして w に書き込む。図形の縁は1ピクセルを4点で調べて滑らかにする.
+func PNG(w io.Writer, board *game.Board, opts Options) error {
+	return png.Encode(w, rasterize(buildScene(board, opts)))
+}
+
+// rasterize は図形の一覧を画像に塗る.
+func rasterize(sc scene) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, int(math.Ceil(sc.width)), int(math.Ceil(sc.height))))
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := sc.background
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	for _, s := range sc.shapes {
+		if s.fill.A > 0 {
+			fillArea(img, s.fill, bounds(s, 0), s.region(0))
+		}
+		if s.stroke.A > 0 {
+			// PNGでは破線にせず、実線の枠にする
+			d := s.strokeWidth / 2
+			outer, inner := s.region(d), s.region(-d)
+			fillArea(img, s.stroke, bounds(s, d), func(p point) bool { return outer(p) && !inner(p) })
+		}
+	}
+	for _, l := range sc.labels {
+		drawLabel(img, l)
+	}
+	return img
+}
+
+// samples は1ピクセルの中で図形に含まれるかを調べる点.
+var samples = []point{{0.25, 0.25}, {0.75, 0.25}, {0.25, 0.75}, {0.75, 0.75}}
+
+// fillArea は r の中で inside に含まれる部分を、含まれる割合に応じて c で塗る.
+func fillArea(img *image.NRGBA, c color.NRGBA, r image.Rectangle, inside func(point) bool) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			hits := 0
+			for _, s := range samples {
+				if inside(point{float64(x) + s.X, float64(y) + s.Y}) {
+					hits++
+				}
+			}
+			if hits > 0 {
+				blend(img, x, y, c, float64(hits)/float64(len(samples)))
+			}
+		}
+	}
+}
+
+// blend は (x, y) のピクセルに c を coverage の割合で重ねる.
+func blend(img *image.NRGBA, x, y int, c color.NRGBA, coverage float64) {
+	a := coverage * float64(c.A) / 0xff
+	i := img.PixOffset(x, y)
+	px := img.Pix[i : i+4 : i+4]
+	mix := func(dst, src uint8) uint8 {
+		return uint8(math.Round(float64(dst)*(1-a) + float64(src)*a))
+	}
+	px[0], px[1], px[2] = mix(px[0], c.R), mix(px[1], c.G), mix(px[2], c.B)
+	px[3] = uint8(math.Round(float64(px[3])*(1-a) + 0xff*a))
+}
+
+// region は図形を d だけ広げた（d が負なら縮めた）形に点が含まれるかを判定する関数を返す.
+func (s shape) region(d float64) func(point) bool {
+	if s.points == nil {
+		r := s.radius + d
+		return func(p point) bool { return r > 0 && math.Hypot(p.X-s.center.X, p.Y-s.center.Y) <= r }
+	}
+	points := inset(s.points, s.center, -d)
+	return func(p point) bool { return inPolygon(points, p) }
+}
+
+// inPolygon は p が多角形の内側にあるかどうかを、右へ伸ばした半直線が辺と交わる回数で判定する.
+func inPolygon(points []point, p point) bool {
+	inside := false
+	for i, a := range points {
+		b := points[(i+len(points)-1)%len(points)]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// bounds は図形を d だけ広げた形を囲むピクセルの範囲.
+func bounds(s shape, d float64) image.Rectangle {
+	if s.points == nil {
+		r := s.radius + d
+		return image.Rect(int(s.center.X-r)-1, int(s.center.Y-r)-1, int(s.center.X+r)+2, int(s.center.Y+r)+2)
+	}
+	points := inset(s.points, s.center, -d)
+	minX, minY, maxX, maxY := points[0].X, points[0].Y, points[0].X, points[0].Y
+	for _, p := range points {
+		minX, minY = min(minX, p.X), min(minY, p.Y)
+		maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+	}
+	return image.Rect(int(minX)-1, int(minY)-1, int(maxX)+2, int(maxY)+2)
+}
+
+// drawLabel は文字列を点の並びの字形で描く。字形の1点は数字の高さに合わせた整数ピクセルの正方形.
+func drawLabel(img *image.NRGBA, l label) {
+	scale := max(1, int(math.Round(l.size/glyphHeight)))
+	width := len(l.text)*(glyphWidth+1)*scale - scale
+	x0 := int(math.Round(l.at.X - float64(width)/2))
+	y0 := int(math.Round(l.at.Y - float64(glyphHeight*scale)/2))
+	for i, ch := range []byte(l.text) {
+		glyph, ok := glyphs[ch]
+		if !ok {
+			continue
+		}
+		left := x0 + i*(glyphWidth+1)*scale
+		for row, line := range glyph {
+			for col := range line {
+				if line[col] != '#' {
+					continue
+				}
+				r := image.Rect(left+col*scale, y0+row*scale, left+(col+1)*scale, y0+(row+1)*scale)
+				fillArea(img, l.color, r, func(point) bool { return true })
+			}
+		}
+	}
+}
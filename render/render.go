@@ -0,0 +1,91 @@
+// Package render は盤面を画像（SVGとPNG）に描く.
+//
+// 不具合の報告やドキュメントに、端末の色やフォントに左右されない正確な局面を添付するためのもの.
+// マスのつながり方（正方形・六角形・三角形）に合わせてマスの形を変え、外側に行と列の番号を描く.
+// ソルバーの地雷確率や確定させたマスなどを重ねて描くこともできる.
+package render
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// Format は画像の形式.
+type Format string
+
+const (
+	// FormatSVG は拡大しても崩れないSVG。盤面の文字表記も埋め込む.
+	FormatSVG Format = "svg"
+	// FormatPNG はどこにでも貼り付けられるPNG.
+	FormatPNG Format = "png"
+)
+
+// Formats はすべての画像の形式.
+var Formats = []Format{FormatSVG, FormatPNG}
+
+// ParseFormat は名前（svg / png）から画像の形式を取得する.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(name, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown image format %q (want svg or png)", name)
+}
+
+// FormatOf はファイル名の拡張子から画像の形式を判断する.
+func FormatOf(path string) (Format, error) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "" {
+		return "", fmt.Errorf("cannot tell the image format of %q without an extension (.svg or .png)", path)
+	}
+	return ParseFormat(ext)
+}
+
+// DefaultCellSize はマスの幅の既定値（ピクセル）.
+const DefaultCellSize = 32
+
+// minCellSize は数字が読める最小のマスの幅.
+const minCellSize = 12
+
+// Options は画像の大きさと、盤面に重ねて描く情報。ゼロ値なら既定の大きさで盤面だけを描く.
+type Options struct {
+	// CellSize はマスの幅（ピクセル）。0なら DefaultCellSize.
+	CellSize int
+	// Probabilities は未開放のマスの地雷確率。マスの中に百分率で描く.
+	Probabilities map[game.Position]float64
+	// Safe と Mines はソルバーが安全・地雷と確定させたマス。緑と赤の枠で囲む.
+	Safe  []game.Position
+	Mines []game.Position
+	// Guess は推測で開くことを勧めるマス。橙の枠で囲む（SVGでは破線）.
+	Guess *game.Position
+	// Cursor はプレイヤーのカーソル。青の枠で囲む.
+	Cursor *game.Position
+	// ShowMines が true なら、未開放の地雷も薄く描く（盤面ファイルの'*'）.
+	ShowMines bool
+}
+
+func (o Options) cellSize() float64 {
+	switch {
+	case o.CellSize == 0:
+		return DefaultCellSize
+	case o.CellSize < minCellSize:
+		return minCellSize
+	}
+	return float64(o.CellSize)
+}
+
+// Write は盤面を指定した形式の画像にして w に書き込む.
+func Write(w io.Writer, format Format, board *game.Board, opts Options) error {
+	switch format {
+	case FormatSVG:
+		return SVG(w, board, opts)
+	case FormatPNG:
+		return PNG(w, board, opts)
+	}
+	return fmt.Errorf("unknown image format %q", format)
+}
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    Format
+		wantErr bool
+	}{
+		{"board.svg", FormatSVG, false},
+		{"out/Board.PNG", FormatPNG, false},
+		{"board.jpg", "", true},
+		{"board", "", true},
+	}
+	for _, tt := range tests {
+		got, err := FormatOf(tt.path)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("FormatOf(%q) = %q, %v, want %q (error: %v)", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// testBoard は数字・旗・AIの印・未開放の地雷を含む盤面.
+func testBoard(t *testing.T) *game.Board {
+	t.Helper()
+	board, err := game.ParseBoard("1F?\n1M*\n11?\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return board
+}
+
+func TestSVG(t *testing.T) {
+	board := testBoard(t)
+	guess := game.Position{Row: 2, Col: 2}
+	var buf bytes.Buffer
+	err := SVG(&buf, board, Options{
+		Probabilities: map[game.Position]float64{{Row: 0, Col: 2}: 0.25, {Row: 2, Col: 2}: 0.5},
+		Guess:         &guess,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 整形式のXMLで、見えている盤面の文字表記を含む
+	var desc string
+	dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, buf.String())
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "desc" {
+			if err := dec.DecodeElement(&desc, &start); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if desc != board.VisibleNotation() {
+		t.Errorf("desc = %q, want the visible notation %q", desc, board.VisibleNotation())
+	}
+
+	svg := buf.String()
+	for _, want := range []string{">25%</text>", ">50%</text>", `stroke="#ff8800"`, "stroke-dasharray"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG should contain %q", want)
+		}
+	}
+	if got := strings.Count(svg, "<polygon"); got < board.Width*board.Height {
+		t.Errorf("SVG has %d polygons, want at least one per cell", got)
+	}
+}
+
+func TestSVG_ShowMines(t *testing.T) {
+	board := testBoard(t)
+	tests := []struct {
+		name      string
+		showMines bool
+		want      string
+	}{
+		{"hidden", false, "11?"},
+		{"shown", true, "1M*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := SVG(&buf, board, Options{ShowMines: tt.showMines}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("SVG should embed %q in the notation", tt.want)
+			}
+			// 未開放の地雷は薄い色で描く
+			if got := strings.Contains(buf.String(), `fill-opacity`); got != tt.showMines {
+				t.Errorf("translucent mine drawn = %v, want %v", got, tt.showMines)
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	for _, topology := range game.Topologies {
+		t.Run(topology.Name(), func(t *testing.T) {
+			board := testBoard(t)
+			board.Topology = topology
+			cursor := game.Position{Row: 1, Col: 1}
+			var buf bytes.Buffer
+			if err := PNG(&buf, board, Options{CellSize: 20, Cursor: &cursor}); err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			geo := geometry{topology: topology, size: 20, origin: point{20, 20}}
+			w, h := geo.boardSize(board.Width, board.Height)
+			want := image.Point{int(math.Ceil(w + 25)), int(math.Ceil(h + 25))}
+			if got := img.Bounds().Size(); got != want {
+				t.Errorf("size = %v, want %v", got, want)
+			}
+			// 開いたマスの中心は開いたマスの色、カーソルのマスの枠はカーソルの色
+			if got := pixel(img, geo, game.Position{Row: 2, Col: 0}); got != openColor && got != numberColors[0] {
+				t.Errorf("open cell color = %v, want %v", got, openColor)
+			}
+			if !hasColor(img, cursorColor) {
+				t.Error("PNG should draw the cursor frame")
+			}
+		})
+	}
+}
+
+// pixel はマスの中心の色.
+func pixel(img image.Image, geo geometry, pos game.Position) color.NRGBA {
+	_, c := geo.cell(pos)
+	return color.NRGBAModel.Convert(img.At(int(c.X), int(c.Y))).(color.NRGBA)
+}
+
+func hasColor(img image.Image, want color.NRGBA) bool {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA) == want {
+				return true
+			}
+		}
+	}
+	return false
+}
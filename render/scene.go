@@ -0,0 +1,365 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// SVGとPNGは同じ図形の一覧（scene）から描くので、どちらの画像も同じ見た目になる.
+
+type point struct {
+	X, Y float64
+}
+
+// shape は塗りと枠のある多角形か円.
+type shape struct {
+	// points は多角形の頂点。nilなら center と radius の円.
+	points []point
+	center point
+	radius float64
+	fill   color.NRGBA
+	stroke color.NRGBA
+	// strokeWidth は枠の太さ。枠は図形の輪郭を中心に内外へ半分ずつ描く.
+	strokeWidth float64
+	dashed      bool
+}
+
+// label は中央揃えの文字列。使える文字は数字と'%'と'?'だけ.
+type label struct {
+	at   point
+	text string
+	// size は数字の高さ.
+	size  float64
+	color color.NRGBA
+}
+
+type scene struct {
+	width, height float64
+	background    color.NRGBA
+	shapes        []shape
+	labels        []label
+	// notation はSVGに埋め込む盤面の文字表記.
+	notation string
+}
+
+var (
+	backgroundColor = color.NRGBA{0xf4, 0xf4, 0xf4, 0xff}
+	hiddenColor     = color.NRGBA{0xb8, 0xb8, 0xb8, 0xff}
+	openColor       = color.NRGBA{0xec, 0xec, 0xec, 0xff}
+	explodedColor   = color.NRGBA{0xe0, 0x55, 0x55, 0xff}
+	borderColor     = color.NRGBA{0x88, 0x88, 0x88, 0xff}
+	inkColor        = color.NRGBA{0x22, 0x22, 0x22, 0xff}
+	axisColor       = color.NRGBA{0x66, 0x66, 0x66, 0xff}
+	flagColor       = color.NRGBA{0xdd, 0x22, 0x22, 0xff}
+	// markColor はAIが地雷と確定させた印。プレイヤーの旗と区別する.
+	markColor = color.NRGBA{0x88, 0x44, 0xcc, 0xff}
+	// hiddenMineColor は ShowMines で描く未開放の地雷.
+	hiddenMineColor = color.NRGBA{0x22, 0x22, 0x22, 0x60}
+	safeColor       = color.NRGBA{0x22, 0xaa, 0x22, 0xff}
+	mineColor       = color.NRGBA{0xcc, 0x22, 0x22, 0xff}
+	guessColor      = color.NRGBA{0xff, 0x88, 0x00, 0xff}
+	cursorColor     = color.NRGBA{0x22, 0x66, 0xff, 0xff}
+)
+
+// numberColors は1〜8の数字の色。9以上は最後の色.
+var numberColors = []color.NRGBA{
+	{0x00, 0x00, 0xff, 0xff},
+	{0x00, 0x80, 0x00, 0xff},
+	{0xdd, 0x00, 0x00, 0xff},
+	{0x00, 0x00, 0x80, 0xff},
+	{0x80, 0x00, 0x00, 0xff},
+	{0x00, 0x80, 0x80, 0xff},
+	{0x00, 0x00, 0x00, 0xff},
+	{0x66, 0x66, 0x66, 0xff},
+}
+
+// geometry はマスのつながり方に合わせたマスの形と位置.
+type geometry struct {
+	topology game.Topology
+	// size はマスの幅。三角形では底辺の長さ.
+	size float64
+	// origin は盤面の左上。その上と左には行と列の番号を描く.
+	origin point
+}
+
+// hexRadius は幅 size の六角形（頂点が上下）の外接円の半径.
+func (g geometry) hexRadius() float64 {
+	return g.size / math.Sqrt(3)
+}
+
+// triangleHeight は底辺 size の正三角形の高さ.
+func (g geometry) triangleHeight() float64 {
+	return g.size * math.Sqrt(3) / 2
+}
+
+// boardSize は盤面の幅と高さ.
+func (g geometry) boardSize(width, height int) (float64, float64) {
+	switch g.topology {
+	case game.HexTopology:
+		r := g.hexRadius()
+		w := float64(width) * g.size
+		if height > 1 {
+			w += g.size / 2
+		}
+		return w, float64(height-1)*1.5*r + 2*r
+	case game.TriangleTopology:
+		return float64(width+1) * g.size / 2, float64(height) * g.triangleHeight()
+	}
+	return float64(width) * g.size, float64(height) * g.size
+}
+
+// cell はマスの頂点と中心を返す.
+func (g geometry) cell(pos game.Position) ([]point, point) {
+	o, s := g.origin, g.size
+	switch g.topology {
+	case game.HexTopology:
+		r := g.hexRadius()
+		c := point{o.X + float64(pos.Col)*s + s/2, o.Y + float64(pos.Row)*1.5*r + r}
+		if pos.Row%2 == 1 {
+			c.X += s / 2
+		}
+		points := make([]point, 6)
+		for i := range points {
+			angle := math.Pi / 180 * float64(60*i-90)
+			points[i] = point{c.X + r*math.Cos(angle), c.Y + r*math.Sin(angle)}
+		}
+		return points, c
+	case game.TriangleTopology:
+		h := g.triangleHeight()
+		x, y := o.X+float64(pos.Col)*s/2, o.Y+float64(pos.Row)*h
+		if game.IsUpTriangle(pos) {
+			return []point{{x, y + h}, {x + s/2, y}, {x + s, y + h}}, point{x + s/2, y + 2*h/3}
+		}
+		return []point{{x, y}, {x + s, y}, {x + s/2, y + h}}, point{x + s/2, y + h/3}
+	}
+	x, y := o.X+float64(pos.Col)*s, o.Y+float64(pos.Row)*s
+	return []point{{x, y}, {x + s, y}, {x + s, y + s}, {x, y + s}}, point{x + s/2, y + s/2}
+}
+
+// buildScene は盤面と重ねる情報を図形の一覧にする.
+func buildScene(board *game.Board, opts Options) scene {
+	s := opts.cellSize()
+	geo := geometry{topology: board.GetTopology(), size: s, origin: point{s, s}}
+	w, h := geo.boardSize(board.Width, board.Height)
+	sc := scene{
+		width:      w + s*1.25,
+		height:     h + s*1.25,
+		background: backgroundColor,
+		notation:   board.Notation(),
+	}
+	if !opts.ShowMines {
+		sc.notation = board.VisibleNotation()
+	}
+	sc.addAxes(geo, board)
+
+	for row := 0; row < board.Height; row++ {
+		for col := 0; col < board.Width; col++ {
+			pos := game.Position{Row: row, Col: col}
+			sc.addCell(geo, pos, board.Cells[row][col], opts)
+		}
+	}
+
+	// 重ねる情報の枠は、隣のマスに隠れないよう最後に描く
+	frames := []struct {
+		positions []game.Position
+		color     color.NRGBA
+		dashed    bool
+	}{
+		{opts.Safe, safeColor, false},
+		{opts.Mines, mineColor, false},
+		{optional(opts.Guess), guessColor, true},
+		{optional(opts.Cursor), cursorColor, false},
+	}
+	for _, f := range frames {
+		for _, pos := range f.positions {
+			if board.IsValidPosition(pos) {
+				sc.addFrame(geo, pos, f.color, f.dashed)
+			}
+		}
+	}
+	return sc
+}
+
+func optional(pos *game.Position) []game.Position {
+	if pos == nil {
+		return nil
+	}
+	return []game.Position{*pos}
+}
+
+// addAxes は盤面の上に列の番号、左に行の番号を描く.
+func (sc *scene) addAxes(geo geometry, board *game.Board) {
+	size := geo.size * 0.3
+	if geo.topology == game.TriangleTopology {
+		// 三角形の列は半マスずつずれて狭いので、番号を小さくする
+		size = geo.size * 0.2
+	}
+	for col := 0; col < board.Width; col++ {
+		_, center := geo.cell(game.Position{Row: 0, Col: col})
+		sc.labels = append(sc.labels, label{
+			at: point{center.X, geo.size / 2}, text: strconv.Itoa(col), size: size, color: axisColor,
+		})
+	}
+	for row := 0; row < board.Height; row++ {
+		points, _ := geo.cell(game.Position{Row: row, Col: 0})
+		top, bottom := points[0].Y, points[0].Y
+		for _, p := range points {
+			top, bottom = min(top, p.Y), max(bottom, p.Y)
+		}
+		sc.labels = append(sc.labels, label{
+			at: point{geo.size / 2, (top + bottom) / 2}, text: strconv.Itoa(row), size: geo.size * 0.3, color: axisColor,
+		})
+	}
+}
+
+// addCell は1つのマスと、その中の数字や旗を描く.
+func (sc *scene) addCell(geo geometry, pos game.Position, cell *game.Cell, opts Options) {
+	points, center := geo.cell(pos)
+	// u は図形の大きさの基準。マスの内接円の半径なので、どの形のマスにも収まる
+	u := inradius(points, center)
+
+	sc.shapes = append(sc.shapes, shape{
+		points: points, center: center, fill: cellColor(cell), stroke: borderColor, strokeWidth: 1,
+	})
+
+	switch {
+	case cell.IsRevealed && cell.IsMine:
+		sc.addMine(center, u, inkColor)
+		sc.addCount(center, u, cell.MineCount())
+	case cell.IsRevealed && cell.Adjacent > 0:
+		sc.labels = append(sc.labels, label{
+			at: center, text: strconv.Itoa(cell.Adjacent), size: u * 0.9,
+			color: numberColors[min(cell.Adjacent, len(numberColors))-1],
+		})
+	case cell.IsRevealed:
+	case cell.IsFlagged:
+		sc.addFlag(center, u, flagColor)
+		sc.addCount(center, u, cell.FlagCount())
+	case cell.IsMarked:
+		sc.addFlag(center, u, markColor)
+		sc.addCount(center, u, cell.MarkCount())
+	case opts.ShowMines && cell.IsMine:
+		sc.addMine(center, u, hiddenMineColor)
+		sc.addCount(center, u, cell.MineCount())
+	case cell.IsQuestioned:
+		sc.labels = append(sc.labels, label{at: center, text: "?", size: u * 0.9, color: inkColor})
+	default:
+		if p, ok := opts.Probabilities[pos]; ok {
+			text := percent(p)
+			// "100%" の4文字もマスに収まるよう、長い文字列は小さくする
+			size := u * 0.5 * min(1, 3/float64(len(text)))
+			sc.labels = append(sc.labels, label{at: center, text: text, size: size, color: inkColor})
+		}
+	}
+}
+
+// cellColor はマスの地の色.
+func cellColor(cell *game.Cell) color.NRGBA {
+	switch {
+	case cell.IsRevealed && cell.IsMine:
+		return explodedColor
+	case cell.IsRevealed:
+		return openColor
+	}
+	return hiddenColor
+}
+
+// percent は確率を整数の百分率にする。確定していない確率が0%や100%に見えないよう端を丸めない.
+func percent(p float64) string {
+	n := int(math.Round(p * 100))
+	switch {
+	case p > 0 && n == 0:
+		n = 1
+	case p < 1 && n == 100:
+		n = 99
+	}
+	return fmt.Sprintf("%d%%", n)
+}
+
+// addCount は1マスに複数ある地雷や旗の数を右下に小さく描く.
+func (sc *scene) addCount(center point, u float64, count int) {
+	if count <= 1 {
+		return
+	}
+	sc.labels = append(sc.labels, label{
+		at: point{center.X + u*0.6, center.Y + u*0.6}, text: strconv.Itoa(count), size: u * 0.45, color: inkColor,
+	})
+}
+
+// addMine はトゲのある丸い地雷を描く.
+func (sc *scene) addMine(c point, u float64, ink color.NRGBA) {
+	sc.shapes = append(sc.shapes,
+		shape{points: rect(c.X-u*0.7, c.Y-u*0.07, c.X+u*0.7, c.Y+u*0.07), center: c, fill: ink},
+		shape{points: rect(c.X-u*0.07, c.Y-u*0.7, c.X+u*0.07, c.Y+u*0.7), center: c, fill: ink},
+		shape{center: c, radius: u * 0.45, fill: ink},
+	)
+}
+
+// addFlag は台と旗竿のある三角の旗を描く.
+func (sc *scene) addFlag(c point, u float64, ink color.NRGBA) {
+	pole := rect(c.X+u*0.05, c.Y-u*0.65, c.X+u*0.18, c.Y+u*0.5)
+	base := rect(c.X-u*0.4, c.Y+u*0.45, c.X+u*0.6, c.Y+u*0.6)
+	cloth := []point{{c.X + u*0.1, c.Y - u*0.65}, {c.X - u*0.6, c.Y - u*0.35}, {c.X + u*0.1, c.Y - u*0.05}}
+	sc.shapes = append(sc.shapes,
+		shape{points: pole, center: c, fill: inkColor},
+		shape{points: base, center: c, fill: inkColor},
+		shape{points: cloth, center: centroid(cloth), fill: ink},
+	)
+}
+
+// addFrame はマスの内側に沿った枠を描く.
+func (sc *scene) addFrame(geo geometry, pos game.Position, ink color.NRGBA, dashed bool) {
+	points, center := geo.cell(pos)
+	width := max(2, geo.size/12)
+	sc.shapes = append(sc.shapes, shape{
+		points: inset(points, center, width/2+1), center: center, stroke: ink, strokeWidth: width, dashed: dashed,
+	})
+}
+
+func rect(x0, y0, x1, y1 float64) []point {
+	return []point{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+}
+
+func centroid(points []point) point {
+	var c point
+	for _, p := range points {
+		c.X += p.X
+		c.Y += p.Y
+	}
+	return point{c.X / float64(len(points)), c.Y / float64(len(points))}
+}
+
+// inradius は凸多角形の中心から最も近い辺までの距離.
+func inradius(points []point, center point) float64 {
+	r := math.Inf(1)
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		length := math.Hypot(b.X-a.X, b.Y-a.Y)
+		if length == 0 {
+			continue
+		}
+		cross := (b.X-a.X)*(center.Y-a.Y) - (b.Y-a.Y)*(center.X-a.X)
+		r = min(r, math.Abs(cross)/length)
+	}
+	return r
+}
+
+// inset は凸多角形を中心に向かって d だけ縮める（d が負なら広げる）.
+// 正多角形と長方形以外では辺ごとの幅がわずかに変わるが、マスと図形の形ならほとんど分からない.
+func inset(points []point, center point, d float64) []point {
+	r := inradius(points, center)
+	if r <= 0 {
+		return slices.Clone(points)
+	}
+	scale := max(0, (r-d)/r)
+	out := make([]point, len(points))
+	for i, p := range points {
+		out[i] = point{center.X + (p.X-center.X)*scale, center.Y + (p.Y-center.Y)*scale}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// 色を指定するSVGの属性.
+const (
+	fillAttr   = "fill"
+	strokeAttr = "stroke"
+)
+
+// svgFontScale は数字の高さからフォントの大きさへの倍率（等幅フォントの数字の高さは1emのおよそ0.72倍）.
+const svgFontScale = 1 / 0.72
+
+// SVG は盤面をSVGにして w に書き込む.
+// 盤面の文字表記を desc 要素に埋め込むので、画像から solve などで同じ局面を読み直せる.
+func SVG(w io.Writer, board *game.Board, opts Options) error {
+	sc := buildScene(board, opts)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(sc.width), num(sc.height), num(sc.width), num(sc.height))
+	fmt.Fprint(bw, "<desc>")
+	if err := xml.EscapeText(bw, []byte(sc.notation)); err != nil {
+		return err
+	}
+	fmt.Fprint(bw, "</desc>\n")
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%"%s/>`+"\n", paint(fillAttr, sc.background))
+
+	for _, s := range sc.shapes {
+		attrs := paint(fillAttr, s.fill) + paint(strokeAttr, s.stroke)
+		if s.stroke.A > 0 {
+			attrs += fmt.Sprintf(` stroke-width="%s" stroke-linejoin="round"`, num(s.strokeWidth))
+			if s.dashed {
+				attrs += fmt.Sprintf(` stroke-dasharray="%s"`, num(s.strokeWidth*2))
+			}
+		}
+		if s.points == nil {
+			fmt.Fprintf(bw, `<circle cx="%s" cy="%s" r="%s"%s/>`+"\n", num(s.center.X), num(s.center.Y), num(s.radius), attrs)
+			continue
+		}
+		coords := make([]string, len(s.points))
+		for i, p := range s.points {
+			coords[i] = num(p.X) + "," + num(p.Y)
+		}
+		fmt.Fprintf(bw, `<polygon points="%s"%s/>`+"\n", strings.Join(coords, " "), attrs)
+	}
+
+	for _, l := range sc.labels {
+		fmt.Fprintf(bw, `<text x="%s" y="%s" font-size="%s" font-family="monospace" font-weight="bold" `+
+			`text-anchor="middle" dominant-baseline="central"%s>`,
+			num(l.at.X), num(l.at.Y), num(l.size*svgFontScale), paint(fillAttr, l.color))
+		if err := xml.EscapeText(bw, []byte(l.text)); err != nil {
+			return err
+		}
+		fmt.Fprint(bw, "</text>\n")
+	}
+	fmt.Fprint(bw, "</svg>\n")
+	return bw.Flush()
+}
+
+// paint は塗りや枠の色の属性。透明なら "none".
+func paint(attr string, c color.NRGBA) string {
+	if c.A == 0 {
+		return fmt.Sprintf(` %s="none"`, attr)
+	}
+	s := fmt.Sprintf(` %s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A < 0xff {
+		s += fmt.Sprintf(` %s-opacity="%s"`, attr, num(float64(c.A)/0xff))
+	}
+	return s
+}
+
+// num は座標を小数第2位までの短い文字列にする.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
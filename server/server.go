@@ -0,0 +1,234 @@
+// Package server は外部のボットがHTTPとJSONでゲームをプレイするためのサーバー.
+//
+// ボットはゲームを作成してセッションIDを受け取り、そのIDでマスを開く・旗を切り替える・
+// 周囲をまとめて開く（コード）・状態を問い合わせる。応答にはプレイヤーに見えている情報だけを含める.
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// MaxGames は同時に保持できるゲームの数。超えたら新しいゲームは作らない.
+const MaxGames = 1000
+
+// maxBodyBytes はリクエストの本文の大きさの上限.
+const maxBodyBytes = 1 << 16
+
+// Server はゲームのセッションを保持してHTTPのリクエストに応じる.
+type Server struct {
+	mu    sync.Mutex
+	games map[string]*game.Game
+	mux   *http.ServeMux
+}
+
+// CreateRequest はゲームを作成するリクエスト.
+// Width と Height を指定するとカスタム難易度になり、Mines を省略するとマス数の約15%になる.
+type CreateRequest struct {
+	Difficulty string `json:"difficulty"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Mines      *int   `json:"mines"`
+	// Seed は地雷配置のシード。0ならランダム.
+	Seed int64 `json:"seed"`
+}
+
+// State はプレイヤーに見えているゲームの状態。地雷の位置とシードは含めない.
+type State struct {
+	ID         string `json:"id"`
+	State      string `json:"state"`
+	Difficulty string `json:"difficulty"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Mines      int    `json:"mines"`
+	// RemainingMines は地雷の数から旗の数を引いたもの.
+	RemainingMines int `json:"remaining_mines"`
+	// Board は1行1要素の盤面の文字表記（'?' 未開放、'F' 旗、'.' 空白、数字、'X' 開いた地雷）.
+	Board []string `json:"board"`
+	// Revealed はこのリクエストで新たに開いたマス.
+	Revealed []game.Position `json:"revealed,omitempty"`
+}
+
+// errorResponse はエラーの応答.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// errNotFound は存在しないセッションIDを指定されたときのエラー.
+var errNotFound = errors.New("game not found")
+
+// New はゲームを1つも持たないサーバーを作成する.
+func New() *Server {
+	s := &Server{games: map[string]*game.Game{}, mux: http.NewServeMux()}
+	s.mux.HandleFunc("POST /games", s.handleCreate)
+	s.mux.HandleFunc("GET /games/{id}", s.handleState)
+	s.mux.HandleFunc("DELETE /games/{id}", s.handleDelete)
+	s.mux.HandleFunc("POST /games/{id}/reveal", s.handleMove(func(g *game.Game, pos game.Position) []game.Position {
+		return g.Click(pos)
+	}))
+	s.mux.HandleFunc("POST /games/{id}/flag", s.handleMove(func(g *game.Game, pos game.Position) []game.Position {
+		g.ToggleFlag(pos)
+		return nil
+	}))
+	s.mux.HandleFunc("POST /games/{id}/chord", s.handleMove((*game.Game).Chord))
+	return s
+}
+
+// ServeHTTP はリクエストをパスとメソッドに応じたハンドラーに振り分ける.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	// 本文を省略すると初級のランダムな盤面になる
+	if err := decode(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	difficulty, err := req.difficulty()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.games) >= MaxGames {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("too many games (max %d)", MaxGames))
+		return
+	}
+	id, err := newID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	g := game.NewGameWithOptions(difficulty, game.Options{Seed: req.Seed})
+	s.games[id] = g
+	writeJSON(w, http.StatusCreated, state(id, g, nil))
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id, g, ok := s.lookup(w, r); ok {
+		writeJSON(w, http.StatusOK, state(id, g, nil))
+	}
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id, _, ok := s.lookup(w, r); ok {
+		delete(s.games, id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleMove は本文で位置（{"row":0,"col":0}）を受け取り、ゲームに手を打って状態を返すハンドラーを作る.
+func (s *Server) handleMove(move func(*game.Game, game.Position) []game.Position) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var pos game.Position
+		if err := decode(r, &pos); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		id, g, ok := s.lookup(w, r)
+		if !ok {
+			return
+		}
+		if !g.Board.IsValidPosition(pos) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("position (%d,%d) is outside the board", pos.Row, pos.Col))
+			return
+		}
+		writeJSON(w, http.StatusOK, state(id, g, move(g, pos)))
+	}
+}
+
+// lookup はパスのセッションIDのゲームを返す。見つからなければ404を書き込んで false を返す.
+// 呼び出し側で s.mu をロックしておく.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (string, *game.Game, bool) {
+	id := r.PathValue("id")
+	g, ok := s.games[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, errNotFound)
+	}
+	return id, g, ok
+}
+
+// difficulty はリクエストから難易度を決める。難易度も大きさも省略されていれば初級.
+func (req CreateRequest) difficulty() (game.Difficulty, error) {
+	if req.Width == 0 && req.Height == 0 {
+		if req.Difficulty == "" {
+			return game.Beginner, nil
+		}
+		return game.ParseDifficulty(req.Difficulty)
+	}
+	mines := req.Width * req.Height * 15 / 100
+	if req.Mines != nil {
+		mines = *req.Mines
+	}
+	return game.NewLimitedDifficulty(req.Width, req.Height, mines)
+}
+
+// state はプレイヤーに見えているゲームの状態を作る.
+func state(id string, g *game.Game, revealed []game.Position) State {
+	rows := make([]string, 0, g.Board.Height)
+	for _, line := range strings.Split(strings.TrimRight(g.Board.VisibleNotation(), "\n"), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			rows = append(rows, line)
+		}
+	}
+	return State{
+		ID:             id,
+		State:          g.State.String(),
+		Difficulty:     g.Difficulty.Name,
+		Width:          g.Board.Width,
+		Height:         g.Board.Height,
+		Mines:          g.Board.Mines,
+		RemainingMines: g.GetRemainingMines(),
+		Board:          rows,
+		Revealed:       revealed,
+	}
+}
+
+// newID は推測されにくいセッションIDを作る.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// 書き込みに失敗するのはクライアントが切断したときなので無視する
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// do はサーバーにリクエストを送り、ステータスコードと応答のJSONを返す.
+func do(t *testing.T, s *Server, method, path, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func TestServer_PlayGame(t *testing.T) {
+	s := New()
+	var created State
+	if code := do(t, s, http.MethodPost, "/games", `{"difficulty":"beginner","seed":42}`, &created); code != http.StatusCreated {
+		t.Fatalf("create status = %d", code)
+	}
+	if created.ID == "" || created.State != "playing" || created.Width != 9 || len(created.Board) != 9 {
+		t.Fatalf("created = %+v", created)
+	}
+
+	var revealed State
+	path := "/games/" + created.ID
+	if code := do(t, s, http.MethodPost, path+"/reveal", `{"row":4,"col":4}`, &revealed); code != http.StatusOK {
+		t.Fatalf("reveal status = %d", code)
+	}
+	if len(revealed.Revealed) == 0 {
+		t.Error("reveal should report the opened cells")
+	}
+	// 応答には未開放の地雷の位置を含めない
+	for _, row := range revealed.Board {
+		if strings.ContainsAny(row, "*X") {
+			t.Errorf("board leaks mines: %q", row)
+		}
+	}
+
+	var flagged State
+	do(t, s, http.MethodPost, path+"/flag", `{"row":0,"col":0}`, &flagged)
+	if flagged.Board[0][0] != 'F' && revealed.Board[0][0] == '?' {
+		t.Errorf("row 0 = %q, want a flag at (0,0)", flagged.Board[0])
+	}
+
+	var got State
+	if code := do(t, s, http.MethodGet, path, "", &got); code != http.StatusOK || got.ID != created.ID {
+		t.Errorf("state status = %d, id %q", code, got.ID)
+	}
+	if code := do(t, s, http.MethodDelete, path, "", nil); code != http.StatusNoContent {
+		t.Errorf("delete status = %d", code)
+	}
+	if code := do(t, s, http.MethodGet, path, "", nil); code != http.StatusNotFound {
+		t.Errorf("state after delete status = %d, want 404", code)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	s := New()
+	var created State
+	do(t, s, http.MethodPost, "/games", "", &created)
+	if created.Difficulty != "beginner" {
+		t.Errorf("empty body should create a beginner game, got %q", created.Difficulty)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"不明な難易度", http.MethodPost, "/games", `{"difficulty":"nightmare"}`, http.StatusBadRequest},
+		{"地雷が多すぎる", http.MethodPost, "/games", `{"width":3,"height":3,"mines":5}`, http.StatusBadRequest},
+		{"盤面が大きすぎる", http.MethodPost, "/games", `{"width":100000,"height":100000}`, http.StatusBadRequest},
+		{"不明なフィールド", http.MethodPost, "/games", `{"level":1}`, http.StatusBadRequest},
+		{"不明なセッション", http.MethodPost, "/games/nope/reveal", `{"row":0,"col":0}`, http.StatusNotFound},
+		{"盤面の外", http.MethodPost, "/games/" + created.ID + "/chord", `{"row":9,"col":0}`, http.StatusBadRequest},
+		{"位置がない", http.MethodPost, "/games/" + created.ID + "/reveal", "", http.StatusBadRequest},
+		{"メソッドが違う", http.MethodGet, "/games/" + created.ID + "/reveal", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp errorResponse
+			var out any = &resp
+			if tt.want == http.StatusMethodNotAllowed {
+				// ルーターの応答はJSONではない
+				out = nil
+			}
+			code := do(t, s, tt.method, tt.path, tt.body, out)
+			if code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", code, tt.want, resp.Error)
+			}
+		})
+	}
+}
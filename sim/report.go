@@ -0,0 +1,154 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// ProgressBuckets は負けた時点の進行度を10%刻みで分けた区間数.
+const ProgressBuckets = 10
+
+// Report はシミュレーションの集計結果.
+type Report struct {
+	Difficulty  game.Difficulty `json:"difficulty"`
+	Strategy    string          `json:"strategy"`
+	GuessPolicy string          `json:"guess_policy"`
+	Seed        int64           `json:"seed"`
+
+	Games int `json:"games"`
+	Wins  int `json:"wins"`
+	// WinsWithoutGuess は一度も推測せずに勝ったゲーム数.
+	WinsWithoutGuess int `json:"wins_without_guess"`
+	Losses           int `json:"losses"`
+	// Stuck は推測方針が手を選べずに打ち切ったゲーム数.
+	Stuck int `json:"stuck"`
+
+	WinRate    float64       `json:"win_rate"`
+	AvgGuesses float64       `json:"avg_guesses"`
+	AvgCleared float64       `json:"avg_cleared"`
+	AvgTime    time.Duration `json:"avg_time_ns"`
+	WallTime   time.Duration `json:"wall_time_ns"`
+
+	// LossByProgress は負けた時点で開いていた安全なマスの割合の分布（10%刻み）.
+	LossByProgress [ProgressBuckets]int `json:"loss_by_progress"`
+	// LossByRegion は踏んだ地雷の位置の分布（corner / edge / interior）.
+	LossByRegion map[string]int `json:"loss_by_region"`
+	// LossByGuess は何回目の推測で負けたかの分布（0は推測以外）.
+	LossByGuess map[int]int `json:"loss_by_guess"`
+}
+
+func newReport(cfg Config, results []GameResult) Report {
+	r := Report{
+		Difficulty:   cfg.Difficulty,
+		Strategy:     cfg.Strategy,
+		GuessPolicy:  cfg.GuessPolicy,
+		Seed:         cfg.Seed,
+		Games:        len(results),
+		LossByRegion: map[string]int{},
+		LossByGuess:  map[int]int{},
+	}
+
+	var guesses int
+	var cleared float64
+	var total time.Duration
+	for _, res := range results {
+		guesses += res.Guesses
+		cleared += res.Cleared
+		total += res.Duration
+
+		switch {
+		case res.Won:
+			r.Wins++
+			if res.Guesses == 0 {
+				r.WinsWithoutGuess++
+			}
+		case res.Stuck:
+			r.Stuck++
+		default:
+			r.Losses++
+			bucket := min(int(res.Cleared*ProgressBuckets), ProgressBuckets-1)
+			r.LossByProgress[bucket]++
+			r.LossByRegion[region(cfg.Difficulty, res.LostAt)]++
+			r.LossByGuess[res.Guesses]++
+		}
+	}
+
+	if r.Games > 0 {
+		n := float64(r.Games)
+		r.WinRate = float64(r.Wins) / n
+		r.AvgGuesses = float64(guesses) / n
+		r.AvgCleared = cleared / n
+		r.AvgTime = total / time.Duration(r.Games)
+	}
+	return r
+}
+
+// region は位置が盤面の角・辺・内側のどこにあるかを返す.
+func region(d game.Difficulty, pos game.Position) string {
+	onRowEdge := pos.Row == 0 || pos.Row == d.Height-1
+	onColEdge := pos.Col == 0 || pos.Col == d.Width-1
+	switch {
+	case onRowEdge && onColEdge:
+		return "corner"
+	case onRowEdge || onColEdge:
+		return "edge"
+	}
+	return "interior"
+}
+
+// WriteJSON は集計結果をJSONで書き出す.
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText は集計結果を人が読む形式で書き出す.
+func (r Report) WriteText(w io.Writer) error {
+	d := r.Difficulty
+	lines := []string{
+		fmt.Sprintf("difficulty: %s %dx%d mines: %d", d.Name, d.Width, d.Height, d.Mines),
+		fmt.Sprintf("strategy: %s  guess: %s  seed: %d", r.Strategy, r.GuessPolicy, r.Seed),
+		fmt.Sprintf("games: %d", r.Games),
+		fmt.Sprintf("wins: %d (%.1f%%)  without guessing: %d", r.Wins, 100*r.WinRate, r.WinsWithoutGuess),
+		fmt.Sprintf("losses: %d  stuck: %d", r.Losses, r.Stuck),
+		fmt.Sprintf("average guesses: %.2f", r.AvgGuesses),
+		fmt.Sprintf("average cleared: %.1f%%", 100*r.AvgCleared),
+		fmt.Sprintf("average time: %s  (wall: %s)", r.AvgTime, r.WallTime.Round(time.Millisecond)),
+	}
+
+	if r.Losses > 0 {
+		lines = append(lines, "", "losses by progress:")
+		for i, n := range r.LossByProgress {
+			lines = append(lines, fmt.Sprintf("  %3d-%3d%%  %5d  %s", i*10, (i+1)*10, n, bar(n, r.Losses)))
+		}
+		lines = append(lines, "", "losses by region:")
+		for _, name := range []string{"corner", "edge", "interior"} {
+			n := r.LossByRegion[name]
+			lines = append(lines, fmt.Sprintf("  %-9s %5d  %s", name, n, bar(n, r.Losses)))
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bar(n, total int) string {
+	const width = 40
+	if total == 0 {
+		return ""
+	}
+	s := make([]byte, n*width/total)
+	for i := range s {
+		s[i] = '#'
+	}
+	return string(s)
+}
@@ -0,0 +1,223 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+// Strategy はゲームごとに使い続けるソルバーから確定できる手を求める.
+type Strategy func(s *solver.Solver) solver.SolverResult
+
+// GuessPolicy は確定できる手がないときに開くマスを選ぶ。選べなければfalseを返す.
+type GuessPolicy func(g *game.Game, rng *rand.Rand) (game.Position, bool)
+
+var strategies = map[string]Strategy{
+	"basic": (*solver.Solver).Solve,
+	// advanced は包含・残り地雷数・全列挙の規則も使う.
+	"advanced": func(s *solver.Solver) solver.SolverResult {
+		result, _ := s.SolveWith(solver.RuleEnumeration)
+		return result
+	},
+}
+
+var guessPolicies = map[string]GuessPolicy{
+	// none は推測せず、行き詰まった時点でゲームを打ち切る.
+	"none": func(*game.Game, *rand.Rand) (game.Position, bool) {
+		return game.Position{}, false
+	},
+	"random": randomGuess,
+}
+
+func init() {
+	for _, policy := range solver.GuessPolicies {
+		guessPolicies[string(policy)] = solverGuess(policy)
+	}
+}
+
+// solverGuess はソルバーの推測方針をGuessPolicyとして使えるようにする.
+func solverGuess(policy solver.GuessPolicy) GuessPolicy {
+	return func(g *game.Game, _ *rand.Rand) (game.Position, bool) {
+		guess, ok := solver.NewSolver(g.Board).Guess(policy)
+		return guess.Position, ok
+	}
+}
+
+// Strategies は使用できる戦略名の一覧.
+func Strategies() []string {
+	return sortedKeys(strategies)
+}
+
+// GuessPolicies は使用できる推測方針名の一覧.
+func GuessPolicies() []string {
+	return sortedKeys(guessPolicies)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Config はシミュレーションの設定.
+type Config struct {
+	Difficulty game.Difficulty
+	// Options はマスのつながり方などのゲームの設定。シードはゲームごとに Seed から決める.
+	Options game.Options
+	Games   int
+	// Seed は最初のゲームのシード。i番目のゲームはSeed+iを使う.
+	Seed        int64
+	Strategy    string
+	GuessPolicy string
+	// Workers は並列に動かすゲーム数。0ならCPU数.
+	Workers int
+}
+
+// GameResult は1ゲーム分の結果.
+type GameResult struct {
+	Seed     int64
+	Won      bool
+	Stuck    bool
+	Guesses  int
+	Duration time.Duration
+	// LostAt は踏んだ地雷の位置（負けた場合のみ有効）.
+	LostAt game.Position
+	// Cleared は終了時点で開いた安全なマスの割合.
+	Cleared float64
+}
+
+// Lost は地雷を踏んで負けたかどうか.
+func (r GameResult) Lost() bool {
+	return !r.Won && !r.Stuck
+}
+
+// Run は設定に従って多数のゲームを並列に解かせ、集計結果を返す.
+func Run(ctx context.Context, cfg Config) (Report, error) {
+	strategy, ok := strategies[cfg.Strategy]
+	if !ok {
+		return Report{}, fmt.Errorf("unknown strategy %q (available: %v)", cfg.Strategy, Strategies())
+	}
+	guess, ok := guessPolicies[cfg.GuessPolicy]
+	if !ok {
+		return Report{}, fmt.Errorf("unknown guess policy %q (available: %v)", cfg.GuessPolicy, GuessPolicies())
+	}
+	if cfg.Games <= 0 {
+		return Report{}, fmt.Errorf("number of games must be positive: %d", cfg.Games)
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]GameResult, cfg.Games)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = PlayGame(cfg.Difficulty, cfg.Options, cfg.Seed+int64(i), strategy, guess)
+			}
+		}()
+	}
+
+	start := time.Now()
+	var err error
+feed:
+	for i := 0; i < cfg.Games; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := newReport(cfg, results)
+	report.WallTime = time.Since(start)
+	return report, nil
+}
+
+// PlayGame は1ゲームを最後まで解かせる.
+// 最初のクリックは盤面の中央で、推測には数えない.
+func PlayGame(difficulty game.Difficulty, options game.Options, seed int64, strategy Strategy, guess GuessPolicy) GameResult {
+	options.Seed = seed
+	g := game.NewGameWithOptions(difficulty, options)
+	rng := rand.New(rand.NewSource(seed)) //nolint:gosec // 推測の乱数にはmath/randで十分
+	result := GameResult{Seed: seed}
+
+	start := time.Now()
+	last := game.Position{Row: difficulty.Height / 2, Col: difficulty.Width / 2}
+	g.Click(last)
+	s := solver.NewSolver(g.Board)
+
+	for g.State == game.Playing {
+		r := strategy(s)
+		var changed []game.Position
+		for _, pos := range r.MineCells {
+			g.MarkMines(pos, r.MineCount(pos))
+			changed = append(changed, pos)
+		}
+		for _, pos := range r.SafeCells {
+			if g.State != game.Playing {
+				break
+			}
+			last = pos
+			changed = append(changed, g.Click(pos)...)
+		}
+		if r.CanProgress {
+			s.Update(changed)
+			continue
+		}
+
+		pos, ok := guess(g, rng)
+		if !ok {
+			result.Stuck = true
+			break
+		}
+		result.Guesses++
+		last = pos
+		s.Update(g.Click(pos))
+	}
+
+	result.Duration = time.Since(start)
+	result.Won = g.State == game.Won
+	if g.State == game.Lost {
+		result.LostAt = last
+	}
+	safeCells := difficulty.Width*difficulty.Height - difficulty.Mines
+	if safeCells > 0 {
+		result.Cleared = float64(safeCells-g.Board.CountUnrevealedSafeCells()) / float64(safeCells)
+	}
+	return result
+}
+
+// randomGuess は旗もAIの印もない未開放のマスから無作為に選ぶ.
+func randomGuess(g *game.Game, rng *rand.Rand) (game.Position, bool) {
+	var candidates []game.Position
+	for _, pos := range g.Board.GetAllUnrevealedPositions() {
+		if cell := g.Board.GetCell(pos); !cell.IsFlagged && !cell.IsMarked {
+			candidates = append(candidates, pos)
+		}
+	}
+	if len(candidates) == 0 {
+		return game.Position{}, false
+	}
+	return candidates[rng.Intn(len(candidates))], true
+}
@@ -0,0 +1,192 @@
+package sim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestRun_Deterministic(t *testing.T) {
+	cfg := Config{
+		Difficulty:  game.Beginner,
+		Games:       50,
+		Seed:        1,
+		Strategy:    "basic",
+		GuessPolicy: "random",
+		Workers:     4,
+	}
+
+	r1, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	cfg.Workers = 1
+	r2, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if r1.Wins != r2.Wins || r1.Losses != r2.Losses || r1.AvgGuesses != r2.AvgGuesses {
+		t.Errorf("results differ between worker counts: %+v vs %+v", r1, r2)
+	}
+	if r1.Wins+r1.Losses+r1.Stuck != cfg.Games {
+		t.Errorf("wins+losses+stuck = %d, want %d", r1.Wins+r1.Losses+r1.Stuck, cfg.Games)
+	}
+
+	lossTotal := 0
+	for _, n := range r1.LossByProgress {
+		lossTotal += n
+	}
+	if lossTotal != r1.Losses {
+		t.Errorf("LossByProgress total = %d, want %d", lossTotal, r1.Losses)
+	}
+}
+
+func TestRun_Options(t *testing.T) {
+	// 同じシードでも、マスのつながり方や数字の数え方、1マスの地雷の最大数が違えば別の盤面を解くので結果が変わる
+	run := func(options game.Options) Report {
+		t.Helper()
+		report, err := Run(context.Background(), Config{
+			Difficulty:  game.Beginner,
+			Options:     options,
+			Games:       50,
+			Seed:        5,
+			Strategy:    "basic",
+			GuessPolicy: "none",
+		})
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return report
+	}
+
+	square := run(game.Options{})
+	tests := []struct {
+		name    string
+		options game.Options
+	}{
+		{"hex", game.Options{Topology: game.HexTopology}},
+		{"torus", game.Options{Topology: game.TorusTopology}},
+		{"knight", game.Options{Neighborhood: game.NeighborhoodKnight}},
+		{"max cell mines", game.Options{MaxCellMines: 3}},
+	}
+	for _, tt := range tests {
+		got := run(tt.options)
+		if got.Wins == square.Wins && got.AvgCleared == square.AvgCleared {
+			t.Errorf("%s: wins %d, cleared %v, want results different from the square board",
+				tt.name, got.Wins, got.AvgCleared)
+		}
+	}
+}
+
+func TestRun_NoGuessNeverLoses(t *testing.T) {
+	for _, strategy := range Strategies() {
+		t.Run(strategy, func(t *testing.T) {
+			report, err := Run(context.Background(), Config{
+				Difficulty:  game.Intermediate,
+				Games:       20,
+				Seed:        7,
+				Strategy:    strategy,
+				GuessPolicy: "none",
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			// 確定した手だけなら地雷は踏まない
+			if report.Losses != 0 {
+				t.Errorf("Losses = %d, want 0", report.Losses)
+			}
+			if report.AvgGuesses != 0 {
+				t.Errorf("AvgGuesses = %v, want 0", report.AvgGuesses)
+			}
+			if report.Wins != report.WinsWithoutGuess {
+				t.Errorf("Wins = %d, WinsWithoutGuess = %d", report.Wins, report.WinsWithoutGuess)
+			}
+		})
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	base := Config{Difficulty: game.Beginner, Games: 1, Strategy: "basic", GuessPolicy: "random"}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"unknown strategy", func(c *Config) { c.Strategy = "magic" }},
+		{"unknown guess policy", func(c *Config) { c.GuessPolicy = "psychic" }},
+		{"no games", func(c *Config) { c.Games = 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			tt.modify(&cfg)
+			if _, err := Run(context.Background(), cfg); err == nil {
+				t.Error("Run() should fail")
+			}
+		})
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Run(ctx, Config{Difficulty: game.Expert, Games: 10000, Strategy: "basic", GuessPolicy: "random", Workers: 1})
+	if err == nil {
+		t.Error("Run() should report cancellation")
+	}
+}
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		pos  game.Position
+		want string
+	}{
+		{game.Position{Row: 0, Col: 0}, "corner"},
+		{game.Position{Row: 8, Col: 8}, "corner"},
+		{game.Position{Row: 0, Col: 4}, "edge"},
+		{game.Position{Row: 4, Col: 8}, "edge"},
+		{game.Position{Row: 4, Col: 4}, "interior"},
+	}
+
+	for _, tt := range tests {
+		if got := region(game.Beginner, tt.pos); got != tt.want {
+			t.Errorf("region(%v) = %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	report, err := Run(context.Background(), Config{
+		Difficulty: game.Beginner, Games: 10, Seed: 3, Strategy: "basic", GuessPolicy: "random",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "games: 10") {
+		t.Errorf("WriteText() = %q", text.String())
+	}
+
+	var js bytes.Buffer
+	if err := report.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON() produced invalid json: %v", err)
+	}
+	if decoded["games"].(float64) != 10 {
+		t.Errorf("games = %v, want 10", decoded["games"])
+	}
+}
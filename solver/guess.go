@@ -0,0 +1,218 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// GuessPolicy は確定できる手がないときに開くマスを選ぶ方針.
+type GuessPolicy string
+
+const (
+	// GuessLowestProbability は地雷確率が最も低いマスを選ぶ.
+	GuessLowestProbability GuessPolicy = "lowest"
+	// GuessOpening は確率がほぼ最低のマスの中から、周囲も安全で連鎖的に開く見込みが最も高いマスを選ぶ.
+	GuessOpening GuessPolicy = "opening"
+	// GuessCorner は確率がほぼ最低のマスの中から角、辺の順に優先して選ぶ.
+	GuessCorner GuessPolicy = "corner"
+	// GuessLookahead は確率がほぼ最低のマスについて一手先まで読み、
+	// 次に確定手が得られるか安全に推測できる見込みが最も高いマスを選ぶ.
+	GuessLookahead GuessPolicy = "lookahead"
+)
+
+// GuessPolicies は使用できる推測方針の一覧.
+var GuessPolicies = []GuessPolicy{
+	GuessLowestProbability,
+	GuessOpening,
+	GuessCorner,
+	GuessLookahead,
+}
+
+// ParseGuessPolicy は名前から推測方針を取得.
+func ParseGuessPolicy(name string) (GuessPolicy, error) {
+	for _, p := range GuessPolicies {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown guess policy %q", name)
+}
+
+const (
+	// probabilityTolerance は「ほぼ同じ確率」とみなす差.
+	probabilityTolerance = 0.02
+	// lookaheadCandidates は先読みで評価する候補の数.
+	lookaheadCandidates = 8
+)
+
+// Guess は推測で開くマスと、その地雷確率.
+type Guess struct {
+	Position    game.Position
+	Probability float64
+}
+
+// Guess は方針に従って推測で開くマスを選ぶ。候補がなければfalse.
+func (s *Solver) Guess(policy GuessPolicy) (Guess, bool) {
+	a := s.analyze()
+	candidates := guessCandidates(s.board, a.probs)
+	if len(candidates) == 0 {
+		return Guess{}, false
+	}
+
+	// 確実に安全なマスがあればどの方針でもそれを選ぶ
+	if a.probs[candidates[0]] == 0 {
+		return Guess{Position: candidates[0], Probability: 0}, true
+	}
+
+	var best game.Position
+	switch policy {
+	case GuessOpening:
+		best = s.bestOpening(candidates, a.probs)
+	case GuessCorner:
+		best = s.bestCorner(candidates, a.probs)
+	case GuessLookahead:
+		best = s.bestLookahead(candidates, a)
+	default:
+		best = candidates[0]
+	}
+	return Guess{Position: best, Probability: a.probs[best]}, true
+}
+
+// guessCandidates は旗の立っていない未開放のマスを地雷確率の低い順（同じなら盤面の順）に並べる.
+func guessCandidates(board *game.Board, probs map[game.Position]float64) []game.Position {
+	var candidates []game.Position
+	for pos, p := range probs {
+		if p < 1 && !board.GetCell(pos).IsFlagged {
+			candidates = append(candidates, pos)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		pi, pj := probs[candidates[i]], probs[candidates[j]]
+		if pi != pj {
+			return pi < pj
+		}
+		if candidates[i].Row != candidates[j].Row {
+			return candidates[i].Row < candidates[j].Row
+		}
+		return candidates[i].Col < candidates[j].Col
+	})
+	return candidates
+}
+
+// nearBest は最低確率からprobabilityTolerance以内の候補を返す.
+func nearBest(candidates []game.Position, probs map[game.Position]float64) []game.Position {
+	limit := probs[candidates[0]] + probabilityTolerance
+	for i, pos := range candidates {
+		if probs[pos] > limit {
+			return candidates[:i]
+		}
+	}
+	return candidates
+}
+
+// bestOpening は確率がほぼ最低のマスの中から、「そのマスが安全」かつ「周囲の未開放のマスもすべて安全」な
+// 確率が最も高いマスを選ぶ。周囲のマスは独立とみなして近似する.
+func (s *Solver) bestOpening(candidates []game.Position, probs map[game.Position]float64) game.Position {
+	best := candidates[0]
+	bestScore := -1.0
+	for _, pos := range nearBest(candidates, probs) {
+		score := 1 - probs[pos]
+		for _, adj := range s.board.GetAdjacentPositions(pos) {
+			if p, ok := probs[adj]; ok {
+				score *= 1 - p
+			} else if s.isKnownMine(adj) {
+				score = 0
+			}
+		}
+		if score > bestScore {
+			best, bestScore = pos, score
+		}
+	}
+	return best
+}
+
+// bestCorner は確率がほぼ最低のマスの中から、隣接マスの少ない（角、辺）マスを選ぶ.
+func (s *Solver) bestCorner(candidates []game.Position, probs map[game.Position]float64) game.Position {
+	near := nearBest(candidates, probs)
+	best := near[0]
+	bestNeighbors := math.MaxInt
+	for _, pos := range near {
+		n := len(s.board.GetAdjacentPositions(pos))
+		if n < bestNeighbors {
+			best, bestNeighbors = pos, n
+		}
+	}
+	return best
+}
+
+// bestLookahead は確率の低い候補について、開いたときに出る数字ごとの盤面を仮に作って評価する.
+// 数字が出る確率は、その数字を置いた盤面と矛盾しない配置の数の比から厳密に求める.
+// 開いた後に確定手があれば成功、なければ次の推測で生き残る確率を成功の見込みとする.
+func (s *Solver) bestLookahead(candidates []game.Position, current analysis) game.Position {
+	if !current.consistent {
+		return candidates[0]
+	}
+
+	near := nearBest(candidates, current.probs)
+	near = near[:min(len(near), lookaheadCandidates)]
+	best := candidates[0]
+	bestScore := -1.0
+	for _, pos := range near {
+		if score := s.lookaheadScore(pos, current); score > bestScore {
+			best, bestScore = pos, score
+		}
+	}
+	return best
+}
+
+// lookaheadScore はposを開いたときに出うる数字ごとの盤面で生き残る見込みを、数字の出る確率で重み付けして足す.
+// 1マスに複数の地雷を置けるルールでは、数字は隣接マスの数より大きくなりうる.
+func (s *Solver) lookaheadScore(pos game.Position, current analysis) float64 {
+	score := 0.0
+	maxValue := s.board.CellCapacity() * len(s.board.GetAdjacentPositions(pos))
+	for v := 0; v <= maxValue; v++ {
+		board := s.board.Clone()
+		cell := board.GetCell(pos)
+		cell.IsMine = false
+		cell.Mines = 0
+		cell.IsRevealed = true
+		cell.SetAdjacent(v)
+
+		next := NewSolverWithOptions(board, Options{FlagPolicy: s.flagPolicy}).analyze()
+		if !next.consistent {
+			continue
+		}
+		weight := math.Exp(next.logWeight - current.logWeight)
+		score += weight * survival(board, next.probs)
+	}
+	return score
+}
+
+// survival は確定した安全なマスがあれば1、なければ最も安全なマスを推測して生き残る確率.
+// 残りがすべて地雷と確定していれば、もう開くマスがない（勝ち）ので1.
+func survival(board *game.Board, probs map[game.Position]float64) float64 {
+	lowest := 1.0
+	for pos, p := range probs {
+		if board.GetCell(pos).IsRevealed {
+			continue
+		}
+		if p == 0 {
+			return 1
+		}
+		lowest = math.Min(lowest, p)
+	}
+	if lowest >= 1 {
+		return 1
+	}
+	return 1 - lowest
+}
+
+// IsGuess は見えている数字と盤面全体の地雷数だけでは、posを安全と確定できないかどうかを返す.
+// プレイヤーの旗は信用しない。レースでプレイヤーが推測で開いた回数を数えるのに使う.
+func IsGuess(board *game.Board, pos game.Position) bool {
+	p, ok := NewSolver(board).Probabilities()[pos]
+	return !ok || p > certainty
+}
@@ -0,0 +1,179 @@
+package solver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func mustParse(t *testing.T, text string) *game.Board {
+	t.Helper()
+	board, err := game.ParseBoard(text)
+	if err != nil {
+		t.Fatalf("ParseBoard() error = %v", err)
+	}
+	return board
+}
+
+func TestSolver_Probabilities(t *testing.T) {
+	tests := []struct {
+		name   string
+		board  string
+		unsure bool // trueなら全体の地雷数を使わない
+		want   map[game.Position]float64
+	}{
+		{
+			name:  "fifty-fifty",
+			board: "1?\n1*",
+			want: map[game.Position]float64{
+				{Row: 0, Col: 1}: 0.5,
+				{Row: 1, Col: 1}: 0.5,
+			},
+		},
+		{
+			name:  "definite mine",
+			board: "1*",
+			want: map[game.Position]float64{
+				{Row: 0, Col: 1}: 1,
+			},
+		},
+		{
+			// 地雷は1つだけなので、境界の外のマスは安全
+			name:  "global mine count",
+			board: "1*??\n1???",
+			want: map[game.Position]float64{
+				{Row: 0, Col: 1}: 0.5,
+				{Row: 1, Col: 1}: 0.5,
+				{Row: 0, Col: 2}: 0,
+				{Row: 1, Col: 3}: 0,
+			},
+		},
+		{
+			name:   "unknown mine count",
+			board:  "1*??\n1???",
+			unsure: true,
+			want: map[game.Position]float64{
+				{Row: 0, Col: 1}: 0.5,
+				{Row: 0, Col: 2}: unknownDensity,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := mustParse(t, tt.board)
+			if tt.unsure {
+				board.Mines = 0
+			}
+			probs := NewSolver(board).Probabilities()
+			for pos, want := range tt.want {
+				got, ok := probs[pos]
+				if !ok {
+					t.Errorf("no probability for %v", pos)
+					continue
+				}
+				if math.Abs(got-want) > 1e-9 {
+					t.Errorf("probability of %v = %v, want %v", pos, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSolver_Guess_PrefersSafeCell(t *testing.T) {
+	board := mustParse(t, "1*??\n1???")
+
+	for _, policy := range GuessPolicies {
+		guess, ok := NewSolver(board).Guess(policy)
+		if !ok {
+			t.Fatalf("%s: Guess() returned false", policy)
+		}
+		if guess.Probability != 0 || guess.Position.Col < 2 {
+			t.Errorf("%s: Guess() = %+v, want a safe interior cell", policy, guess)
+		}
+	}
+}
+
+func TestSolver_Guess_NoCandidates(t *testing.T) {
+	board := mustParse(t, "1*")
+
+	if guess, ok := NewSolver(board).Guess(GuessLowestProbability); ok {
+		t.Errorf("Guess() = %+v, want no guess", guess)
+	}
+}
+
+func TestIsGuess(t *testing.T) {
+	// 左の列の1から、地雷は(0,1)か(1,1)のどちらか。全体で1個なので右の2列は安全
+	board := mustParse(t, "1*??\n1???")
+
+	tests := []struct {
+		pos  game.Position
+		want bool
+	}{
+		{game.Position{Row: 0, Col: 2}, false},
+		{game.Position{Row: 1, Col: 3}, false},
+		{game.Position{Row: 1, Col: 1}, true},
+		{game.Position{Row: 0, Col: 1}, true},
+	}
+	for _, tt := range tests {
+		if got := IsGuess(board, tt.pos); got != tt.want {
+			t.Errorf("IsGuess(%v) = %v, want %v", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestSolver_Guess_Policies(t *testing.T) {
+	// 実際のゲームの途中で、どの方針も未開放で旗のないマスを選ぶ
+	g := game.NewGameWithOptions(game.Intermediate, game.Options{Seed: 7})
+	g.Click(game.Position{Row: 8, Col: 8})
+
+	for _, policy := range GuessPolicies {
+		guess, ok := NewSolver(g.Board).Guess(policy)
+		if !ok {
+			t.Fatalf("%s: Guess() returned false", policy)
+		}
+		cell := g.Board.GetCell(guess.Position)
+		if cell == nil || cell.IsRevealed || cell.IsFlagged {
+			t.Errorf("%s: Guess() chose invalid cell %v", policy, guess.Position)
+		}
+		if guess.Probability < 0 || guess.Probability >= 1 {
+			t.Errorf("%s: probability = %v, want [0, 1)", policy, guess.Probability)
+		}
+	}
+}
+
+func TestSolver_LookaheadScore_MultiMine(t *testing.T) {
+	// 1マスに2個まで置けるので、(0,0)を開いて出る数字は隣接マスの数より大きくなりうる.
+	// どちらの盤面でも開いた後は推測なしで解けるので、見込みは(0,0)が安全な確率に等しい
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"only a number above the neighbors", "# max-cell-mines: 2\n?B\n"},
+		{"numbers up to twice the neighbors", "# max-cell-mines: 2\n?B?\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSolver(mustParse(t, tt.text))
+			current := s.analyze()
+			pos := game.Position{Row: 0, Col: 0}
+			want := 1 - current.probs[pos]
+			if got := s.lookaheadScore(pos, current); math.Abs(got-want) > 1e-9 {
+				t.Errorf("lookaheadScore() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseGuessPolicy(t *testing.T) {
+	for _, policy := range GuessPolicies {
+		got, err := ParseGuessPolicy(string(policy))
+		if err != nil || got != policy {
+			t.Errorf("ParseGuessPolicy(%q) = %q, %v", policy, got, err)
+		}
+	}
+	if _, err := ParseGuessPolicy("psychic"); err == nil {
+		t.Error("ParseGuessPolicy(\"psychic\") should fail")
+	}
+}
@@ -0,0 +1,551 @@
+package solver
+
+import (
+	"math"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// enumerationBudget は1つの連結成分の列挙で訪問するノード数の上限.
+// 超えた成分は局所的な比率による近似で確率を求める.
+const enumerationBudget = 200000
+
+// unknownDensity は盤面全体の地雷数が分からない場合に、制約のないマスに使う地雷確率.
+const unknownDensity = 0.2
+
+// analysis は盤面から求めたマスごとの地雷確率.
+// 1マスに複数の地雷を置くルールでは、マスに地雷が1個以上ある確率を求める.
+type analysis struct {
+	// probs は未開放で地雷と確定していないすべてのマスの地雷確率.
+	probs map[game.Position]float64
+	// counts は地雷があり、その数まで確定したマスの地雷の数.
+	counts map[game.Position]int
+	// logWeight は見えている情報と矛盾しない地雷配置の数の対数.
+	logWeight float64
+	// consistent は矛盾しない配置が1つ以上あるかどうか.
+	consistent bool
+	// exact は全成分を厳密に列挙できたかどうか.
+	exact bool
+}
+
+type probConstraint struct {
+	vars  []int
+	mines int
+}
+
+// component は制約でつながった境界のマスの集まり.
+type component struct {
+	vars []int
+	// dist[k] は地雷がk個となる解の数（exp(logScale)で割った値）.
+	dist []float64
+	// cellCount[k][i] は地雷がk個の解のうち、vars[i]が地雷である解の数.
+	cellCount [][]float64
+	// cellSum[k][i] と cellSquare[k][i] は地雷がk個の解についての、vars[i]の地雷の数とその2乗の和.
+	// 地雷の数が確定しているかを分散から調べるのに使う.
+	cellSum    [][]float64
+	cellSquare [][]float64
+	logScale   float64
+}
+
+// Probabilities は未開放で地雷と確定していないマスごとの地雷確率を返す.
+// 盤面全体の地雷数（Board.Mines）が分かっていれば、残りの地雷数による重み付けも行う.
+func (s *Solver) Probabilities() map[game.Position]float64 {
+	return s.analyze().probs
+}
+
+func (s *Solver) analyze() analysis {
+	s.refreshFlags()
+	a := s.analyzeWith(s.board.Mines > 0)
+	if !a.consistent && s.board.Mines > 0 {
+		// 全体の地雷数と矛盾している場合は、それを無視して局所的な確率だけを返す
+		a = s.analyzeWith(false)
+		a.consistent = false
+		a.logWeight = math.Inf(-1)
+	}
+	return a
+}
+
+// frontier は開いた数字から作った制約と、制約の対象となる境界のマス.
+type frontier struct {
+	// vars は制約に含まれる未開放のマス。制約からはインデックスで参照する.
+	vars        []game.Position
+	varIndex    map[game.Position]int
+	constraints []probConstraint
+	// unknown は未開放で地雷と確定していないすべてのマス.
+	unknown    []game.Position
+	knownMines int
+	// capacity は1マスに置ける地雷の最大数.
+	capacity int
+	// consistent はどの制約も満たせる可能性があるかどうか.
+	consistent bool
+}
+
+// buildFrontier は盤面から制約を作る。地雷と確定したマスは制約の地雷数から差し引く.
+func (s *Solver) buildFrontier() frontier {
+	b := s.board
+	f := frontier{varIndex: map[game.Position]int{}, capacity: b.CellCapacity(), consistent: true}
+
+	for row := 0; row < b.Height; row++ {
+		for col := 0; col < b.Width; col++ {
+			pos := game.Position{Row: row, Col: col}
+			switch {
+			case s.isKnownMine(pos):
+				f.knownMines += s.knownMines(pos)
+			case !b.GetCell(pos).IsRevealed:
+				f.unknown = append(f.unknown, pos)
+			}
+		}
+	}
+
+	for row := 0; row < b.Height; row++ {
+		for col := 0; col < b.Width; col++ {
+			pos := game.Position{Row: row, Col: col}
+			cell := b.GetCell(pos)
+			if !cell.IsRevealed || cell.IsMine {
+				continue
+			}
+
+			c := probConstraint{mines: cell.Adjacent}
+			for _, adj := range b.GetAdjacentPositions(pos) {
+				if s.isKnownMine(adj) {
+					c.mines -= s.knownMines(adj)
+					continue
+				}
+				if b.GetCell(adj).IsRevealed {
+					continue
+				}
+				idx, ok := f.varIndex[adj]
+				if !ok {
+					idx = len(f.vars)
+					f.varIndex[adj] = idx
+					f.vars = append(f.vars, adj)
+				}
+				c.vars = append(c.vars, idx)
+			}
+			if c.mines < 0 || c.mines > f.capacity*len(c.vars) {
+				f.consistent = false
+			}
+			if len(c.vars) > 0 {
+				f.constraints = append(f.constraints, c)
+			}
+		}
+	}
+	return f
+}
+
+func (s *Solver) analyzeWith(useGlobal bool) analysis { //nolint:gocyclo // 成分の結合と重み付けを一度に行う
+	b := s.board
+	f := s.buildFrontier()
+	a := analysis{
+		probs:      map[game.Position]float64{},
+		counts:     map[game.Position]int{},
+		consistent: f.consistent,
+		exact:      true,
+	}
+
+	components := splitComponents(len(f.vars), f.constraints)
+	for i := range components {
+		if !components[i].enumerate(f.constraints, f.capacity) {
+			components[i].approximate(f.constraints, f.capacity)
+			a.exact = false
+		}
+	}
+
+	interior := len(f.unknown) - len(f.vars)
+	remaining := b.Mines - f.knownMines
+	ways := newInteriorWays(interior, f.capacity, remaining)
+
+	var weightFn func(frontierMines int) float64
+	var maxLog float64
+	if useGlobal {
+		maxLog = math.Inf(-1)
+		for m := 0; m <= f.capacity*len(f.vars); m++ {
+			maxLog = math.Max(maxLog, ways.log(remaining-m))
+		}
+		weightFn = func(frontierMines int) float64 {
+			return math.Exp(ways.log(remaining-frontierMines) - maxLog)
+		}
+	} else {
+		weightFn = func(int) float64 { return 1 }
+	}
+
+	prefix, suffix := convolutions(components)
+	total := prefix[len(components)]
+	z := 0.0
+	expectedInterior := 0.0
+	interiorHit := 0.0
+	for m, n := range total {
+		w := n * weightFn(m)
+		z += w
+		expectedInterior += w * float64(remaining-m)
+		if w > 0 && interior > 0 {
+			interiorHit += w * ways.mineProb(remaining-m)
+		}
+	}
+
+	if z == 0 || math.IsInf(maxLog, -1) {
+		a.consistent = false
+		a.logWeight = math.Inf(-1)
+		for _, pos := range f.unknown {
+			a.probs[pos] = unknownDensity
+		}
+		return a
+	}
+
+	a.logWeight = math.Log(z) + maxLog
+	for _, c := range components {
+		a.logWeight += c.logScale
+	}
+
+	mean := map[game.Position]float64{}
+	square := map[game.Position]float64{}
+	for i, c := range components {
+		rest := convolve(prefix[i], suffix[i+1])
+		for k := range c.dist {
+			wk := 0.0
+			for m, n := range rest {
+				wk += n * weightFn(k+m)
+			}
+			for j, v := range c.vars {
+				pos := f.vars[v]
+				a.probs[pos] += c.cellCount[k][j] * wk / z
+				mean[pos] += c.cellSum[k][j] * wk / z
+				square[pos] += c.cellSquare[k][j] * wk / z
+			}
+		}
+	}
+
+	interiorProb := unknownDensity
+	interiorMean := 0.0
+	if useGlobal && interior > 0 {
+		interiorProb = interiorHit / z
+		interiorMean = expectedInterior / z / float64(interior)
+	}
+	for _, pos := range f.unknown {
+		if _, ok := f.varIndex[pos]; !ok {
+			a.probs[pos] = interiorProb
+			// 内部のマスは地雷の数の平均が上限に等しいときだけ、すべて上限まで埋まっていると分かる
+			mean[pos] = interiorMean
+			square[pos] = interiorMean * float64(f.capacity)
+		}
+	}
+	for pos, p := range a.probs {
+		a.probs[pos] = math.Min(1, math.Max(0, p))
+		if n, ok := certainCount(a.probs[pos], mean[pos], square[pos]); ok {
+			a.counts[pos] = n
+		}
+	}
+	return a
+}
+
+// certainCount は地雷確率と地雷の数の平均・2乗の平均から、地雷の数が確定していればその数を返す.
+func certainCount(p, mean, square float64) (int, bool) {
+	if p <= 1-certainty || square-mean*mean > certainty {
+		return 0, false
+	}
+	return max(int(math.Round(mean)), 1), true
+}
+
+// splitComponents は制約を共有するマスごとに連結成分へ分ける.
+func splitComponents(n int, constraints []probConstraint) []component {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+	for _, c := range constraints {
+		for _, v := range c.vars[1:] {
+			parent[find(v)] = find(c.vars[0])
+		}
+	}
+
+	byRoot := map[int]int{}
+	var components []component
+	for v := 0; v < n; v++ {
+		root := find(v)
+		idx, ok := byRoot[root]
+		if !ok {
+			idx = len(components)
+			byRoot[root] = idx
+			components = append(components, component{})
+		}
+		components[idx].vars = append(components[idx].vars, v)
+	}
+	return components
+}
+
+// enumerate は成分内のすべての地雷配置をバックトラックで数える。予算を超えたらfalse.
+// 1マスに置ける地雷はcapacity個までで、1マスごとに0からcapacityまでの値を試す.
+func (c *component) enumerate(constraints []probConstraint, capacity int) bool { //nolint:gocyclo // バックトラックの枝刈り
+	local := map[int]int{}
+	for i, v := range c.vars {
+		local[v] = i
+	}
+	var related []probConstraint
+	varConstraints := make([][]int, len(c.vars))
+	for _, pc := range constraints {
+		if _, ok := local[pc.vars[0]]; !ok {
+			continue
+		}
+		lc := probConstraint{mines: pc.mines}
+		for _, v := range pc.vars {
+			lc.vars = append(lc.vars, local[v])
+			varConstraints[local[v]] = append(varConstraints[local[v]], len(related))
+		}
+		related = append(related, lc)
+	}
+
+	n := len(c.vars)
+	c.reset(capacity)
+
+	assigned := make([]int, len(related))
+	open := make([]int, len(related))
+	for i, rc := range related {
+		open[i] = len(rc.vars)
+	}
+	value := make([]int, n)
+	nodes := 0
+
+	var search func(i, mines int) bool
+	search = func(i, mines int) bool {
+		nodes++
+		if nodes > enumerationBudget {
+			return false
+		}
+		if i == n {
+			c.dist[mines]++
+			for j, v := range value {
+				if v > 0 {
+					c.cellCount[mines][j]++
+					c.cellSum[mines][j] += float64(v)
+					c.cellSquare[mines][j] += float64(v * v)
+				}
+			}
+			return true
+		}
+		for v := 0; v <= capacity; v++ {
+			ok := true
+			for _, ci := range varConstraints[i] {
+				open[ci]--
+				assigned[ci] += v
+				if assigned[ci] > related[ci].mines || assigned[ci]+capacity*open[ci] < related[ci].mines {
+					ok = false
+				}
+			}
+			value[i] = v
+			if ok && !search(i+1, mines+v) {
+				return false
+			}
+			for _, ci := range varConstraints[i] {
+				open[ci]++
+				assigned[ci] -= v
+			}
+		}
+		value[i] = 0
+		return true
+	}
+
+	if !search(0, 0) {
+		return false
+	}
+	c.normalize()
+	return true
+}
+
+// approximate は列挙できない大きな成分について、制約ごとの地雷の割合から確率を見積もる.
+func (c *component) approximate(constraints []probConstraint, capacity int) {
+	n := len(c.vars)
+	local := map[int]int{}
+	for i, v := range c.vars {
+		local[v] = i
+	}
+	sum := make([]float64, n)
+	count := make([]int, n)
+	for _, pc := range constraints {
+		if _, ok := local[pc.vars[0]]; !ok {
+			continue
+		}
+		ratio := float64(pc.mines) / float64(len(pc.vars))
+		for _, v := range pc.vars {
+			sum[local[v]] += ratio
+			count[local[v]]++
+		}
+	}
+
+	expected := 0.0
+	means := make([]float64, n)
+	for i := range means {
+		means[i] = sum[i] / float64(count[i])
+		expected += means[i]
+	}
+	k := min(int(math.Round(expected)), capacity*n)
+
+	c.reset(capacity)
+	c.dist[k] = 1
+	for i, m := range means {
+		// 地雷の数の平均を確率の代わりに使う。分散は確定と判断されないよう大きめにしておく
+		c.cellCount[k][i] = math.Min(1, m)
+		c.cellSum[k][i] = m
+		c.cellSquare[k][i] = m*m + 1
+	}
+	c.logScale = 0
+}
+
+// reset は地雷の数ごとの解の数の表を、成分のマスがすべて上限まで埋まる場合の分まで0で作り直す.
+func (c *component) reset(capacity int) {
+	n := len(c.vars)
+	size := capacity*n + 1
+	c.dist = make([]float64, size)
+	c.cellCount = make([][]float64, size)
+	c.cellSum = make([][]float64, size)
+	c.cellSquare = make([][]float64, size)
+	for k := range c.dist {
+		c.cellCount[k] = make([]float64, n)
+		c.cellSum[k] = make([]float64, n)
+		c.cellSquare[k] = make([]float64, n)
+	}
+}
+
+// normalize は解の数を最大値で割り、桁あふれを防ぐ.
+func (c *component) normalize() {
+	maxCount := 0.0
+	for _, n := range c.dist {
+		maxCount = math.Max(maxCount, n)
+	}
+	if maxCount == 0 {
+		return
+	}
+	for k := range c.dist {
+		c.dist[k] /= maxCount
+		for j := range c.cellCount[k] {
+			c.cellCount[k][j] /= maxCount
+			c.cellSum[k][j] /= maxCount
+			c.cellSquare[k][j] /= maxCount
+		}
+	}
+	c.logScale = math.Log(maxCount)
+}
+
+// convolutions は成分の地雷数分布の前方・後方からの畳み込みを求める.
+// prefix[i] は成分0..i-1、suffix[i] は成分i..の畳み込み.
+func convolutions(components []component) (prefix, suffix [][]float64) {
+	n := len(components)
+	prefix = make([][]float64, n+1)
+	suffix = make([][]float64, n+1)
+	prefix[0] = []float64{1}
+	suffix[n] = []float64{1}
+	for i := 0; i < n; i++ {
+		prefix[i+1] = convolve(prefix[i], components[i].dist)
+	}
+	for i := n - 1; i >= 0; i-- {
+		suffix[i] = convolve(components[i].dist, suffix[i+1])
+	}
+	return prefix, suffix
+}
+
+func convolve(a, b []float64) []float64 {
+	out := make([]float64, len(a)+len(b)-1)
+	for i, x := range a {
+		if x == 0 {
+			continue
+		}
+		for j, y := range b {
+			out[i+j] += x * y
+		}
+	}
+	return out
+}
+
+// logBinomial はnCkの自然対数。kが範囲外なら-Inf.
+func logBinomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return math.Inf(-1)
+	}
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// interiorWays は制約のない内部のマスへ地雷を置く方法の数.
+// 1マスに複数の地雷を置くルールでは、重なり方の違う配置をそれぞれ1通りと数える.
+type interiorWays struct {
+	cells    int
+	capacity int
+	// all[k] と rest[k] は内部のマスとそれより1マス少ない場合に、k個の地雷を置く方法の数の対数.
+	all  []float64
+	rest []float64
+}
+
+func newInteriorWays(cells, capacity, maxMines int) interiorWays {
+	w := interiorWays{cells: cells, capacity: capacity}
+	if capacity > 1 && maxMines >= 0 {
+		w.rest = logBoundedCompositions(max(cells-1, 0), capacity, maxMines)
+		w.all = logBoundedCompositions(cells, capacity, maxMines)
+	}
+	return w
+}
+
+// log は内部のマスにk個の地雷を置く方法の数の対数。置けなければ-Inf.
+func (w interiorWays) log(k int) float64 {
+	if w.capacity <= 1 {
+		return logBinomial(w.cells, k)
+	}
+	if k < 0 || k >= len(w.all) {
+		return math.Inf(-1)
+	}
+	return w.all[k]
+}
+
+// mineProb は内部にk個の地雷があるときに、内部の1マスに地雷が1個以上ある確率.
+func (w interiorWays) mineProb(k int) float64 {
+	if w.capacity <= 1 {
+		return float64(k) / float64(w.cells)
+	}
+	if k < 0 || k >= len(w.all) || math.IsInf(w.all[k], -1) {
+		return 0
+	}
+	return 1 - math.Exp(w.rest[k]-w.all[k])
+}
+
+// logBoundedCompositions はn個のマスに1マスcapacity個までで地雷を置く方法の数の対数を、
+// 地雷の数0からmaxKまでについて返す。桁あふれしないよう対数のまま足し合わせる.
+func logBoundedCompositions(n, capacity, maxK int) []float64 {
+	ways := make([]float64, maxK+1)
+	for k := range ways {
+		ways[k] = math.Inf(-1)
+	}
+	ways[0] = 0
+	for range n {
+		next := make([]float64, maxK+1)
+		for k := range next {
+			acc := math.Inf(-1)
+			for j := 0; j <= capacity && j <= k; j++ {
+				acc = logAddExp(acc, ways[k-j])
+			}
+			next[k] = acc
+		}
+		ways = next
+	}
+	return ways
+}
+
+// logAddExp はlog(exp(a)+exp(b))を求める.
+func logAddExp(a, b float64) float64 {
+	if math.IsInf(a, -1) {
+		return b
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log1p(math.Exp(b-a))
+}
@@ -0,0 +1,69 @@
+package solver
+
+import (
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// Rating は盤面の難しさの評価.
+type Rating struct {
+	// Hardest は解き切るのに必要だった最も難しい規則.
+	Hardest Rule
+	// Guesses は論理だけでは進めず、推測が必要になった回数.
+	Guesses int
+}
+
+// Score は難しさを1つの数値で表す。推測1回はどの規則よりも難しいとみなす.
+func (r Rating) Score() int {
+	return int(r.Hardest) + int(RuleEnumeration)*r.Guesses
+}
+
+// Rate は地雷を配置済みの盤面をfirstから開き直し、最後まで論理で解き進めて評価する.
+// 行き詰まった場合は、地雷確率が最も低いマスのうち実際には安全なマスを推測で開いたものとして数える.
+// 元の盤面は変更しない.
+func Rate(board *game.Board, first game.Position) Rating {
+	b := board.Clone()
+	for _, row := range b.Cells {
+		for _, cell := range row {
+			cell.IsRevealed = false
+			cell.IsFlagged = false
+			cell.Flags = 0
+			cell.IsMarked = false
+			cell.Marks = 0
+		}
+	}
+	b.RevealCell(first)
+
+	var rating Rating
+	for b.CountUnrevealedSafeCells() > 0 {
+		s := NewSolver(b)
+		result, rule := s.SolveWith(RuleEnumeration)
+		if result.CanProgress {
+			rating.Hardest = max(rating.Hardest, rule)
+			for _, pos := range result.MineCells {
+				b.GetCell(pos).MarkMines(result.MineCount(pos))
+			}
+			for _, pos := range result.SafeCells {
+				b.RevealCell(pos)
+			}
+			continue
+		}
+
+		pos, ok := s.safestGuess()
+		if !ok {
+			break
+		}
+		rating.Guesses++
+		b.RevealCell(pos)
+	}
+	return rating
+}
+
+// safestGuess は地雷確率の低い順に、実際には地雷でない最初のマスを選ぶ.
+func (s *Solver) safestGuess() (game.Position, bool) {
+	for _, pos := range guessCandidates(s.board, s.analyze().probs) {
+		if !s.board.GetCell(pos).IsMine {
+			return pos, true
+		}
+	}
+	return game.Position{}, false
+}
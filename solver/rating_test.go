@@ -0,0 +1,61 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		board string
+		first game.Position
+		want  Rating
+	}{
+		{
+			name:  "opened by the first click",
+			board: "..\n..",
+			first: game.Position{Row: 0, Col: 0},
+			want:  Rating{Hardest: RuleNone},
+		},
+		{
+			name:  "trivial",
+			board: "**?\n???\n...",
+			first: game.Position{Row: 2, Col: 0},
+			want:  Rating{Hardest: RuleTrivial},
+		},
+		{
+			// 上の2マスのどちらが地雷かは推測するしかない
+			name:  "forced guess",
+			board: "*?\n??\n..",
+			first: game.Position{Row: 2, Col: 0},
+			want:  Rating{Hardest: RuleNone, Guesses: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := mustParse(t, tt.board)
+			if got := Rate(board, tt.first); got != tt.want {
+				t.Errorf("Rate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRate_DoesNotModifyBoard(t *testing.T) {
+	g := game.NewGameWithOptions(game.Intermediate, game.Options{Seed: 3})
+	first := game.Position{Row: 8, Col: 8}
+	g.Click(first)
+	before := g.Board.Notation()
+
+	rating := Rate(g.Board, first)
+
+	if after := g.Board.Notation(); after != before {
+		t.Errorf("Rate() modified the board:\n%s\nwant:\n%s", after, before)
+	}
+	if rating.Score() < int(rating.Hardest) {
+		t.Errorf("Score() = %d, want at least %d", rating.Score(), rating.Hardest)
+	}
+}
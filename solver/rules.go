@@ -0,0 +1,184 @@
+package solver
+
+import (
+	"sort"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// Rule はマスを確定させる推論規則。値が大きいほど人間にとって難しい.
+type Rule int
+
+const (
+	// RuleNone はどの規則でもマスを確定できなかったことを表す.
+	RuleNone Rule = iota
+	// RuleTrivial は1つの数字と周囲のマスの数だけで分かる規則.
+	RuleTrivial
+	// RuleSubset は一方の数字の周囲が他方の周囲に含まれるときの差から分かる規則.
+	RuleSubset
+	// RuleGlobal は盤面全体の残りの地雷数から分かる規則.
+	RuleGlobal
+	// RuleEnumeration は境界の地雷配置をすべて列挙して分かる規則.
+	RuleEnumeration
+)
+
+var ruleNames = map[Rule]string{
+	RuleNone:        "none",
+	RuleTrivial:     "trivial",
+	RuleSubset:      "subset",
+	RuleGlobal:      "global",
+	RuleEnumeration: "enumeration",
+}
+
+func (r Rule) String() string {
+	return ruleNames[r]
+}
+
+// SolveWith は易しい順にmaxRuleまでの規則を試し、最初に手が見つかった規則の結果とその規則を返す.
+// 手が見つからなければRuleNoneを返す.
+func (s *Solver) SolveWith(maxRule Rule) (SolverResult, Rule) {
+	steps := []struct {
+		rule  Rule
+		solve func() SolverResult
+	}{
+		{RuleTrivial, s.Solve},
+		{RuleSubset, s.solveSubset},
+		{RuleGlobal, s.solveGlobal},
+		{RuleEnumeration, s.solveEnumeration},
+	}
+	for _, step := range steps {
+		if step.rule > maxRule {
+			break
+		}
+		result := step.solve()
+		// 旗を信じない場合は旗の立ったマスも導かれる。地雷なら印を付けるが、旗のあるマスは開かない
+		counts := result.MineCounts
+		result = newResult(s.unflagged(result.SafeCells), result.MineCells)
+		result.MineCounts = counts
+		if result.CanProgress {
+			return result, step.rule
+		}
+	}
+	return newResult(nil, nil), RuleNone
+}
+
+// solveSubset は周囲のマスが包含関係にある2つの数字の差から確定できるマスを求める.
+// 差の地雷がすべての差のマスを上限まで埋める場合は、それらのマスを地雷とする.
+func (s *Solver) solveSubset() SolverResult {
+	f := s.buildFrontier()
+	varConstraints := make([][]int, len(f.vars))
+	for i, c := range f.constraints {
+		for _, v := range c.vars {
+			varConstraints[v] = append(varConstraints[v], i)
+		}
+	}
+
+	safes := map[int]bool{}
+	mines := map[int]bool{}
+	for _, a := range f.constraints {
+		inA := map[int]bool{}
+		for _, v := range a.vars {
+			inA[v] = true
+		}
+		// aを含む制約はaのマスをすべて含むので、先頭のマスを含む制約だけを調べればよい
+		for _, bi := range varConstraints[a.vars[0]] {
+			b := f.constraints[bi]
+			var diff []int
+			contained := 0
+			for _, v := range b.vars {
+				if inA[v] {
+					contained++
+				} else {
+					diff = append(diff, v)
+				}
+			}
+			if contained != len(a.vars) || len(diff) == 0 {
+				continue
+			}
+			switch b.mines - a.mines {
+			case 0:
+				for _, v := range diff {
+					safes[v] = true
+				}
+			case f.capacity * len(diff):
+				for _, v := range diff {
+					mines[v] = true
+				}
+			}
+		}
+	}
+	return newResult(f.positions(safes), f.positions(mines)).withCounts(f.full)
+}
+
+// solveGlobal は残りの地雷数が0、または未開放のマスをすべて上限まで埋める数と等しい場合にすべてのマスを確定させる.
+func (s *Solver) solveGlobal() SolverResult {
+	if s.board.Mines == 0 {
+		return newResult(nil, nil)
+	}
+	f := s.buildFrontier()
+	switch remaining := s.board.Mines - f.knownMines; {
+	case remaining == 0:
+		return newResult(f.unknown, nil)
+	case remaining == f.capacity*len(f.unknown):
+		return newResult(nil, f.unknown).withCounts(f.full)
+	}
+	return newResult(nil, nil)
+}
+
+// solveEnumeration は地雷確率が0になるマスと、地雷の数まで確定したマスを確定させる.
+// 近似を含む場合や盤面が矛盾している場合は何も確定させない.
+func (s *Solver) solveEnumeration() SolverResult {
+	a := s.analyze()
+	if !a.consistent || !a.exact {
+		return newResult(nil, nil)
+	}
+	var safes []game.Position
+	for pos, p := range a.probs {
+		if p < certainty {
+			safes = append(safes, pos)
+		}
+	}
+	sortPositions(safes)
+	result := newResult(safes, sortedPositions(a.counts))
+	return result.withCounts(func(pos game.Position) int { return a.counts[pos] })
+}
+
+// certainty は確率が0または1とみなす誤差.
+const certainty = 1e-9
+
+// full は上限まで地雷のあるマスの地雷の数.
+func (f frontier) full(game.Position) int {
+	return f.capacity
+}
+
+// positions はインデックスの集合を盤面の順に並んだ位置に変換する.
+func (f frontier) positions(set map[int]bool) []game.Position {
+	positions := map[game.Position]bool{}
+	for v := range set {
+		positions[f.vars[v]] = true
+	}
+	return sortedPositions(positions)
+}
+
+func newResult(safes, mines []game.Position) SolverResult {
+	if safes == nil {
+		safes = []game.Position{}
+	}
+	if mines == nil {
+		mines = []game.Position{}
+	}
+	return SolverResult{
+		SafeCells:   safes,
+		MineCells:   mines,
+		CanProgress: len(safes) > 0 || len(mines) > 0,
+	}
+}
+
+func sortPositions(positions []game.Position) {
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].Row != positions[j].Row {
+			return positions[i].Row < positions[j].Row
+		}
+		return positions[i].Col < positions[j].Col
+	})
+}
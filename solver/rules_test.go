@@ -0,0 +1,100 @@
+package solver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestSolver_SolveWith(t *testing.T) {
+	tests := []struct {
+		name      string
+		board     string
+		noGlobal  bool // trueなら全体の地雷数を使わない
+		maxRule   Rule
+		wantRule  Rule
+		wantSafes []game.Position
+		wantMines []game.Position
+	}{
+		{
+			name:      "trivial",
+			board:     "1*\n11",
+			maxRule:   RuleEnumeration,
+			wantRule:  RuleTrivial,
+			wantSafes: []game.Position{},
+			wantMines: []game.Position{{Row: 0, Col: 1}},
+		},
+		{
+			// 1-2-1: 端の1の周囲は中央の2の周囲に含まれる
+			name:      "subset",
+			board:     "*?*\n121\n...",
+			maxRule:   RuleEnumeration,
+			wantRule:  RuleSubset,
+			wantSafes: []game.Position{},
+			wantMines: []game.Position{{Row: 0, Col: 0}, {Row: 0, Col: 2}},
+		},
+		{
+			name:     "subset not allowed",
+			board:    "*?*\n121\n...",
+			maxRule:  RuleTrivial,
+			wantRule: RuleNone,
+		},
+		{
+			// 地雷は開いた1つだけなので、残りのマスは安全
+			name:      "global",
+			board:     "X?",
+			maxRule:   RuleEnumeration,
+			wantRule:  RuleGlobal,
+			wantSafes: []game.Position{{Row: 0, Col: 1}},
+			wantMines: []game.Position{},
+		},
+		{
+			name:      "enumeration",
+			board:     "?1*??\n12?**\n*1?**",
+			noGlobal:  true,
+			maxRule:   RuleEnumeration,
+			wantRule:  RuleEnumeration,
+			wantSafes: []game.Position{{Row: 1, Col: 2}},
+			wantMines: []game.Position{},
+		},
+		{
+			name:     "fifty-fifty",
+			board:    "1?\n1*",
+			maxRule:  RuleEnumeration,
+			wantRule: RuleNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := mustParse(t, tt.board)
+			if tt.noGlobal {
+				board.Mines = 0
+			}
+
+			result, rule := NewSolver(board).SolveWith(tt.maxRule)
+			if rule != tt.wantRule {
+				t.Fatalf("rule = %v, want %v", rule, tt.wantRule)
+			}
+			if rule == RuleNone {
+				if result.CanProgress {
+					t.Errorf("CanProgress = true, want false: %+v", result)
+				}
+				return
+			}
+			if !reflect.DeepEqual(result.SafeCells, tt.wantSafes) {
+				t.Errorf("SafeCells = %v, want %v", result.SafeCells, tt.wantSafes)
+			}
+			if !reflect.DeepEqual(result.MineCells, tt.wantMines) {
+				t.Errorf("MineCells = %v, want %v", result.MineCells, tt.wantMines)
+			}
+		})
+	}
+}
+
+func TestRule_String(t *testing.T) {
+	if got := RuleSubset.String(); got != "subset" {
+		t.Errorf("RuleSubset.String() = %q, want %q", got, "subset")
+	}
+}
@@ -8,122 +8,227 @@ type SolverResult struct {
 	SafeCells   []game.Position
 	MineCells   []game.Position
 	CanProgress bool
+	// MineCounts は1マスに複数の地雷を置くルールで、2個以上の地雷があると確定したマスの地雷の数.
+	MineCounts map[game.Position]int
 }
 
+// MineCount はMineCellsのマスにある地雷の数.
+func (r SolverResult) MineCount(pos game.Position) int {
+	if n, ok := r.MineCounts[pos]; ok {
+		return n
+	}
+	return 1
+}
+
+// withCounts は地雷のマスの地雷の数を結果に加える。1個のマスは記録しない.
+func (r SolverResult) withCounts(count func(pos game.Position) int) SolverResult {
+	for _, pos := range r.MineCells {
+		if n := count(pos); n > 1 {
+			if r.MineCounts == nil {
+				r.MineCounts = map[game.Position]int{}
+			}
+			r.MineCounts[pos] = n
+		}
+	}
+	return r
+}
+
+// Solver は盤面から確定できる手を求める.
+// 推論に使うのは開いたマスとAI自身の印（Cell.IsMarked）で、プレイヤーの旗は標準では信じない.
+// 同じ盤面に対して繰り返し使う場合は、変化したマスをUpdateで伝えると、Solveはその周囲だけを調べ直す.
 type Solver struct {
 	board *game.Board
+	// scanned は盤面全体を一度調べたかどうか.
+	scanned bool
+	// dirty は次のSolveで調べ直す数字のマス.
+	dirty map[game.Position]bool
+	// safes と mines は見つかったが、まだ盤面に反映されていない手。mines の値はマスの地雷の数.
+	safes map[game.Position]bool
+	mines map[game.Position]int
+
+	flagPolicy FlagPolicy
+	// distrusted はFlagsDistrustのときに無視する、数字と矛盾する旗.
+	distrusted map[game.Position]bool
+	// contradictions は前回のValidateの結果。validatedがfalseならその後に盤面が変わっている.
+	contradictions []Contradiction
+	validated      bool
+}
+
+// Options はソルバーの設定.
+type Options struct {
+	// FlagPolicy はプレイヤーの旗の扱い。空ならFlagsIgnore.
+	FlagPolicy FlagPolicy
 }
 
 func NewSolver(board *game.Board) *Solver {
+	return NewSolverWithOptions(board, Options{})
+}
+
+// NewSolverWithOptions は旗の扱いなどを指定してソルバーを作成.
+func NewSolverWithOptions(board *game.Board, opts Options) *Solver {
+	policy := opts.FlagPolicy
+	if policy == "" {
+		policy = FlagsIgnore
+	}
 	return &Solver{
-		board: board,
+		board:      board,
+		dirty:      map[game.Position]bool{},
+		safes:      map[game.Position]bool{},
+		mines:      map[game.Position]int{},
+		flagPolicy: policy,
+		distrusted: map[game.Position]bool{},
 	}
 }
 
+// Solve は現在の盤面で、1つの数字から直接確定できるマスを返す.
+// 初回は盤面全体を調べ、以降はUpdateで伝えられたマスの周囲の数字だけを調べ直す.
 func (s *Solver) Solve() SolverResult {
-	result := SolverResult{
-		SafeCells:   []game.Position{},
-		MineCells:   []game.Position{},
-		CanProgress: false,
+	s.refreshFlags()
+	if !s.scanned {
+		s.scanned = true
+		s.eachCell(s.check)
+	} else {
+		for pos := range s.dirty {
+			s.check(pos)
+		}
 	}
+	clear(s.dirty)
 
-	// 一度だけ実行して、現在の盤面から直接推論できるマスのみを返す
-	mines := s.findDefiniteMines()
-	result.MineCells = mines
-
-	safes := s.findDefiniteSafeCells()
-	result.SafeCells = safes
+	// 前回までに見つけた手のうち、すでに盤面に反映されたものを除く
+	for pos := range s.safes {
+		if cell := s.board.GetCell(pos); cell.IsRevealed || cell.IsFlagged {
+			delete(s.safes, pos)
+		}
+	}
+	for pos := range s.mines {
+		if s.board.GetCell(pos).IsRevealed || s.isKnownMine(pos) {
+			delete(s.mines, pos)
+		}
+	}
 
-	result.CanProgress = len(result.SafeCells) > 0 || len(result.MineCells) > 0
-	return result
+	result := newResult(sortedPositions(s.safes), sortedPositions(s.mines))
+	return result.withCounts(func(pos game.Position) int { return s.mines[pos] })
 }
 
-func (s *Solver) findDefiniteMines() []game.Position {
-	mines := []game.Position{}
-
-	for row := 0; row < s.board.Height; row++ {
-		for col := 0; col < s.board.Width; col++ {
-			pos := game.Position{Row: row, Col: col}
-			cell := s.board.GetCell(pos)
-
-			if cell == nil || !cell.IsRevealed || cell.IsMine {
-				continue
-			}
+// Update は前回のSolveの後に開いた、または旗を立て外ししたマスを伝える.
+func (s *Solver) Update(changed []game.Position) {
+	if len(changed) > 0 {
+		s.validated = false
+	}
+	for _, pos := range changed {
+		s.markDirty(pos)
+		cell := s.board.GetCell(pos)
+		if cell != nil && !cell.IsRevealed && !cell.IsFlagged && !cell.IsMarked {
+			// 旗が外された。その旗を地雷として導いた手は隣接を2回たどった範囲にあるので、導き直す
+			s.invalidateAround(pos)
+		}
+	}
+}
 
-			if cell.Adjacent == 0 {
-				continue
-			}
+// markDirty はposとその周囲の数字を調べ直す対象にする.
+func (s *Solver) markDirty(pos game.Position) {
+	s.dirty[pos] = true
+	for _, adj := range s.board.GetAdjacentPositions(pos) {
+		s.dirty[adj] = true
+	}
+}
 
-			unrevealed, flagged := s.getUnrevealedAndFlaggedCounts(pos)
-
-			if unrevealed == cell.Adjacent-flagged && unrevealed > 0 {
-				for _, adjPos := range s.board.GetAdjacentPositions(pos) {
-					adjCell := s.board.GetCell(adjPos)
-					if adjCell != nil && !adjCell.IsRevealed && !adjCell.IsFlagged {
-						if !containsPosition(mines, adjPos) {
-							mines = append(mines, adjPos)
-						}
-					}
-				}
-			}
+// invalidateAround はposから隣接を2回たどって届くマスの手を捨てる.
+func (s *Solver) invalidateAround(pos game.Position) {
+	around := map[game.Position]bool{pos: true}
+	for _, adj := range s.board.GetAdjacentPositions(pos) {
+		around[adj] = true
+		for _, p := range s.board.GetAdjacentPositions(adj) {
+			around[p] = true
+		}
+	}
+	for p := range around {
+		if _, ok := s.mines[p]; ok || s.safes[p] {
+			delete(s.safes, p)
+			delete(s.mines, p)
+			s.markDirty(p)
 		}
 	}
-
-	return mines
 }
 
-func (s *Solver) findDefiniteSafeCells() []game.Position { //nolint:gocyclo // アルゴリズムの性質上複雑
-	safes := []game.Position{}
+// check は1つの数字のマスから確定できる周囲のマスを記録する.
+func (s *Solver) check(pos game.Position) {
+	s.collectMines(pos, s.mines)
+	s.collectSafes(pos, s.safes)
+}
 
+// eachCell は盤面のすべてのマスについて盤面の順にfnを呼ぶ.
+func (s *Solver) eachCell(fn func(pos game.Position)) {
 	for row := 0; row < s.board.Height; row++ {
 		for col := 0; col < s.board.Width; col++ {
-			pos := game.Position{Row: row, Col: col}
-			cell := s.board.GetCell(pos)
+			fn(game.Position{Row: row, Col: col})
+		}
+	}
+}
 
-			if cell == nil || !cell.IsRevealed || cell.IsMine {
-				continue
-			}
+func (s *Solver) findDefiniteMines() []game.Position {
+	mines := map[game.Position]int{}
+	s.eachCell(func(pos game.Position) { s.collectMines(pos, mines) })
+	return sortedPositions(mines)
+}
 
-			if cell.Adjacent == 0 {
-				// 空のセルの周囲はすべて安全
-				for _, adjPos := range s.board.GetAdjacentPositions(pos) {
-					adjCell := s.board.GetCell(adjPos)
-					if adjCell != nil && !adjCell.IsRevealed && !adjCell.IsFlagged {
-						if !containsPosition(safes, adjPos) {
-							safes = append(safes, adjPos)
-						}
-					}
-				}
-				continue
-			}
+func (s *Solver) findDefiniteSafeCells() []game.Position {
+	safes := map[game.Position]bool{}
+	s.eachCell(func(pos game.Position) { s.collectSafes(pos, safes) })
+	return sortedPositions(safes)
+}
 
-			unrevealed, _ := s.getUnrevealedAndFlaggedCounts(pos)
-			mineCount := s.getKnownMineCount(pos)
-
-			if mineCount == cell.Adjacent && unrevealed > 0 {
-				for _, adjPos := range s.board.GetAdjacentPositions(pos) {
-					adjCell := s.board.GetCell(adjPos)
-					if adjCell != nil && !adjCell.IsRevealed && !adjCell.IsFlagged && !s.isKnownMine(adjPos) {
-						if !containsPosition(safes, adjPos) {
-							safes = append(safes, adjPos)
-						}
-					}
-				}
-			}
+// collectMines は数字の残りの地雷が、まだ地雷と分かっていない周囲のマスをすべて埋める場合に、
+// それらのマスを地雷として記録する。1マスに複数の地雷を置くルールでは、どのマスも上限まで地雷がある.
+func (s *Solver) collectMines(pos game.Position, mines map[game.Position]int) {
+	cell := s.board.GetCell(pos)
+	if cell == nil || !cell.IsRevealed || cell.IsMine || cell.Adjacent == 0 {
+		return
+	}
+
+	capacity := s.board.CellCapacity()
+	unknown, known := s.getUnknownAndKnownCounts(pos)
+	if unknown == 0 || unknown*capacity != cell.Adjacent-known {
+		return
+	}
+	for _, adjPos := range s.board.GetAdjacentPositions(pos) {
+		adjCell := s.board.GetCell(adjPos)
+		if adjCell != nil && !adjCell.IsRevealed && !s.isKnownMine(adjPos) {
+			mines[adjPos] = capacity
 		}
 	}
+}
+
+// collectSafes は数字の周囲の地雷がすべて分かっている場合に、残りのマスを安全として記録する.
+func (s *Solver) collectSafes(pos game.Position, safes map[game.Position]bool) {
+	cell := s.board.GetCell(pos)
+	if cell == nil || !cell.IsRevealed || cell.IsMine {
+		return
+	}
 
-	return safes
+	// 空のセルの周囲はすべて安全
+	if cell.Adjacent != 0 && s.getKnownMineCount(pos) != cell.Adjacent {
+		return
+	}
+	for _, adjPos := range s.board.GetAdjacentPositions(pos) {
+		adjCell := s.board.GetCell(adjPos)
+		if adjCell != nil && !adjCell.IsRevealed && !adjCell.IsFlagged && !s.isKnownMine(adjPos) {
+			safes[adjPos] = true
+		}
+	}
 }
 
-func (s *Solver) getUnrevealedAndFlaggedCounts(pos game.Position) (unrevealed, flagged int) {
+// getUnknownAndKnownCounts は周囲の未開放で地雷と分かっていないマスの数と、
+// 地雷と分かっている（AIの印か信じる旗か開いた）地雷の数を返す.
+func (s *Solver) getUnknownAndKnownCounts(pos game.Position) (unknown, known int) {
 	for _, adjPos := range s.board.GetAdjacentPositions(pos) {
 		cell := s.board.GetCell(adjPos)
-		if cell != nil && !cell.IsRevealed {
-			unrevealed++
-			if cell.IsFlagged {
-				flagged++
-			}
+		switch {
+		case cell == nil:
+		case s.isKnownMine(adjPos):
+			known += s.knownMines(adjPos)
+		case !cell.IsRevealed:
+			unknown++
 		}
 	}
 	return
@@ -132,16 +237,29 @@ func (s *Solver) getUnrevealedAndFlaggedCounts(pos game.Position) (unrevealed, f
 func (s *Solver) getKnownMineCount(pos game.Position) int {
 	count := 0
 	for _, adjPos := range s.board.GetAdjacentPositions(pos) {
-		if s.isKnownMine(adjPos) {
-			count++
-		}
+		count += s.knownMines(adjPos)
 	}
 	return count
 }
 
 func (s *Solver) isKnownMine(pos game.Position) bool {
+	return s.knownMines(pos) > 0
+}
+
+// knownMines はposにあると分かっている地雷の数。AIの印、信じる旗、開いた地雷の数を使う.
+func (s *Solver) knownMines(pos game.Position) int {
 	cell := s.board.GetCell(pos)
-	return cell != nil && (cell.IsFlagged || (cell.IsRevealed && cell.IsMine))
+	switch {
+	case cell == nil:
+		return 0
+	case cell.IsMarked:
+		return cell.MarkCount()
+	case cell.IsFlagged && s.trustsFlag(pos):
+		return cell.FlagCount()
+	case cell.IsRevealed:
+		return cell.MineCount()
+	}
+	return 0
 }
 
 func containsPosition(positions []game.Position, pos game.Position) bool {
@@ -152,3 +270,13 @@ func containsPosition(positions []game.Position, pos game.Position) bool {
 	}
 	return false
 }
+
+// sortedPositions は位置の集合を盤面の順に並べる.
+func sortedPositions[V any](set map[game.Position]V) []game.Position {
+	positions := make([]game.Position, 0, len(set))
+	for pos := range set {
+		positions = append(positions, pos)
+	}
+	sortPositions(positions)
+	return positions
+}
@@ -91,3 +91,67 @@ func BenchmarkContainsPosition(b *testing.B) {
 		containsPosition(positions, target)
 	}
 }
+
+// playLogic は盤面を確定できる手だけで進め、Solveの呼び出し回数を返す.
+// incremental が false の場合は毎回新しいソルバーで盤面全体を調べ直す.
+func playLogic(board *game.Board, incremental bool) int {
+	s := NewSolver(board)
+	calls := 0
+	for {
+		if !incremental {
+			s = NewSolver(board)
+		}
+		result := s.Solve()
+		calls++
+		if !result.CanProgress {
+			return calls
+		}
+
+		var changed []game.Position
+		for _, pos := range result.MineCells {
+			board.GetCell(pos).Mark()
+			changed = append(changed, pos)
+		}
+		for _, pos := range result.SafeCells {
+			_, revealed := board.Reveal(pos)
+			changed = append(changed, revealed...)
+		}
+		s.Update(changed)
+	}
+}
+
+func benchmarkPlayLogic(b *testing.B, difficulty game.Difficulty, incremental bool) {
+	first := game.Position{Row: difficulty.Height / 2, Col: difficulty.Width / 2}
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		g := game.NewGameWithOptions(difficulty, game.Options{Seed: 1})
+		g.Click(first)
+		b.StartTimer()
+
+		playLogic(g.Board, incremental)
+	}
+}
+
+func largeDifficulty(b *testing.B) game.Difficulty {
+	d, err := game.NewCustomDifficulty(200, 200, 6000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return d
+}
+
+func BenchmarkSolver_Expert_FullRescan(b *testing.B) {
+	benchmarkPlayLogic(b, game.Expert, false)
+}
+
+func BenchmarkSolver_Expert_Incremental(b *testing.B) {
+	benchmarkPlayLogic(b, game.Expert, true)
+}
+
+func BenchmarkSolver_Large_FullRescan(b *testing.B) {
+	benchmarkPlayLogic(b, largeDifficulty(b), false)
+}
+
+func BenchmarkSolver_Large_Incremental(b *testing.B) {
+	benchmarkPlayLogic(b, largeDifficulty(b), true)
+}
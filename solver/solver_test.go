@@ -1,6 +1,9 @@
 package solver
 
 import (
+	"fmt"
+	"math"
+	"reflect"
 	"testing"
 
 	"github.com/r-horie/ai-minesweeper/game"
@@ -99,12 +102,12 @@ func TestSolver_findDefiniteMines(t *testing.T) { //nolint:gocyclo // テスト
 				t.Logf("Expected mines: %v", tt.wantMines)
 				// 中央セルの周囲の状況を確認
 				centerPos := game.Position{Row: 1, Col: 1}
-				unrevealed, flagged := solver.getUnrevealedAndFlaggedCounts(centerPos)
-				t.Logf("Center cell: Adjacent=%d, Unrevealed=%d, Flagged=%d",
-					board.Cells[1][1].Adjacent, unrevealed, flagged)
-				t.Logf("Condition check: unrevealed(%d) == Adjacent(%d) - flagged(%d) = %d",
-					unrevealed, board.Cells[1][1].Adjacent, flagged,
-					board.Cells[1][1].Adjacent-flagged)
+				unknown, known := solver.getUnknownAndKnownCounts(centerPos)
+				t.Logf("Center cell: Adjacent=%d, Unknown=%d, Known=%d",
+					board.Cells[1][1].Adjacent, unknown, known)
+				t.Logf("Condition check: unknown(%d) == Adjacent(%d) - known(%d) = %d",
+					unknown, board.Cells[1][1].Adjacent, known,
+					board.Cells[1][1].Adjacent-known)
 				return
 			}
 
@@ -132,14 +135,14 @@ func TestSolver_findDefiniteSafeCells(t *testing.T) {
 		wantSafes  []game.Position
 	}{
 		{
-			name: "simple case - 1 with 1 flagged adjacent",
+			name: "simple case - 1 with 1 marked adjacent",
 			setupBoard: func() *game.Board {
 				board := game.NewBoard(3, 3, 1)
 				// 中央に1を配置
 				board.Cells[1][1].IsRevealed = true
 				board.Cells[1][1].SetAdjacent(1)
-				// 右にフラグ
-				board.Cells[1][2].IsFlagged = true
+				// 右にAIの印
+				board.Cells[1][2].IsMarked = true
 				// 他は未開放
 				return board
 			},
@@ -150,23 +153,23 @@ func TestSolver_findDefiniteSafeCells(t *testing.T) {
 			},
 		},
 		{
-			name: "3x3 with center 8 and all flagged",
+			name: "3x3 with center 8 and all marked",
 			setupBoard: func() *game.Board {
 				board := game.NewBoard(3, 3, 8)
 				// 中央に8を配置（周囲全て地雷）
 				board.Cells[1][1].IsRevealed = true
 				board.Cells[1][1].SetAdjacent(8)
-				// 周囲全てにフラグ
+				// 周囲全てにAIの印
 				for i := 0; i < 3; i++ {
 					for j := 0; j < 3; j++ {
 						if !(i == 1 && j == 1) {
-							board.Cells[i][j].IsFlagged = true
+							board.Cells[i][j].IsMarked = true
 						}
 					}
 				}
 				return board
 			},
-			wantSafes: []game.Position{}, // すべてフラグ済みなので安全なセルなし
+			wantSafes: []game.Position{}, // すべて印済みなので安全なセルなし
 		},
 		{
 			name: "revealed mine counts as known mine",
@@ -299,26 +302,29 @@ func TestSolver_Solve_ComplexScenarios(t *testing.T) {
 	}
 }
 
-func TestSolver_getUnrevealedAndFlaggedCounts(t *testing.T) {
+func TestSolver_getUnknownAndKnownCounts(t *testing.T) {
 	board := game.NewBoard(3, 3, 2)
+	board.MaxCellMines = 2
 	solver := NewSolver(board)
 
 	// 中央のセルの周囲を設定
 	// 上: 開いている
 	board.Cells[0][1].IsRevealed = true
-	// 右: フラグ
-	board.Cells[1][2].IsFlagged = true
+	// 右: 地雷2個のAIの印
+	board.Cells[1][2].MarkMines(2)
+	// 右下: プレイヤーの旗（地雷としては数えない）
+	board.Cells[2][2].IsFlagged = true
 	// 下: 未開放
 	// 左: 未開放
 
-	unrevealed, flagged := solver.getUnrevealedAndFlaggedCounts(game.Position{Row: 1, Col: 1})
+	unknown, known := solver.getUnknownAndKnownCounts(game.Position{Row: 1, Col: 1})
 
-	if unrevealed != 7 { // 8 - 1(開いている)
-		t.Errorf("Expected 7 unrevealed, got %d", unrevealed)
+	if unknown != 6 { // 8 - 1(開いている) - 1(AIの印)
+		t.Errorf("Expected 6 unknown, got %d", unknown)
 	}
 
-	if flagged != 1 {
-		t.Errorf("Expected 1 flagged, got %d", flagged)
+	if known != 2 {
+		t.Errorf("Expected 2 known mines, got %d", known)
 	}
 }
 
@@ -327,8 +333,8 @@ func TestSolver_getKnownMineCount(t *testing.T) {
 	solver := NewSolver(board)
 
 	// 中央のセルの周囲を設定
-	// 上: フラグ（既知の地雷）
-	board.Cells[0][1].IsFlagged = true
+	// 上: AIの印（既知の地雷）
+	board.Cells[0][1].IsMarked = true
 	// 右: 開かれた地雷
 	board.Cells[1][2].IsRevealed = true
 	board.Cells[1][2].SetMine()
@@ -354,13 +360,22 @@ func TestSolver_isKnownMine(t *testing.T) {
 		expected bool
 	}{
 		{
-			name: "flagged cell is known mine",
+			name: "marked cell is known mine",
 			setup: func(pos game.Position) {
-				board.Cells[pos.Row][pos.Col].IsFlagged = true
+				board.Cells[pos.Row][pos.Col].IsMarked = true
 			},
 			pos:      game.Position{Row: 0, Col: 0},
 			expected: true,
 		},
+		{
+			// プレイヤーの旗は間違っているかもしれないので信じない
+			name: "flagged cell is not known mine",
+			setup: func(pos game.Position) {
+				board.Cells[pos.Row][pos.Col].IsFlagged = true
+			},
+			pos:      game.Position{Row: 2, Col: 2},
+			expected: false,
+		},
 		{
 			name: "revealed mine is known mine",
 			setup: func(pos game.Position) {
@@ -445,3 +460,216 @@ func TestContainsPosition(t *testing.T) {
 		})
 	}
 }
+
+func TestSolver_Incremental_MatchesFullRescan(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3, 4, 5} {
+		first := game.Position{Row: 8, Col: 15}
+		full := game.NewGameWithOptions(game.Expert, game.Options{Seed: seed})
+		full.Click(first)
+		incremental := game.NewGameWithOptions(game.Expert, game.Options{Seed: seed})
+		incremental.Click(first)
+
+		fullCalls := playLogic(full.Board, false)
+		incrementalCalls := playLogic(incremental.Board, true)
+
+		if fullCalls != incrementalCalls {
+			t.Errorf("seed %d: Solve called %d times incrementally, want %d", seed, incrementalCalls, fullCalls)
+		}
+		if got, want := incremental.Board.Notation(), full.Board.Notation(); got != want {
+			t.Errorf("seed %d: incremental board differs from full rescan:\n%s\nwant:\n%s", seed, got, want)
+		}
+	}
+}
+
+func TestSolver_Update_FlagRemoved(t *testing.T) {
+	board, err := game.ParseBoard("1F\n??")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSolverWithOptions(board, Options{FlagPolicy: FlagsTrust})
+
+	// 旗を信じると残りの2マスは安全
+	if result := s.Solve(); len(result.SafeCells) != 2 {
+		t.Fatalf("SafeCells = %v, want 2 cells", result.SafeCells)
+	}
+
+	// 旗を外すと、その旗から導いた手は取り消される
+	board.Cells[0][1].IsFlagged = false
+	s.Update([]game.Position{{Row: 0, Col: 1}})
+	if result := s.Solve(); result.CanProgress {
+		t.Errorf("Solve() after removing the flag = %+v, want no progress", result)
+	}
+}
+
+func TestSolver_Solve_Torus(t *testing.T) {
+	// トーラスでは(0,0)の1は反対側の角の印(3,3)と隣り合うので、残りの隣接マスは安全
+	board := mustParse(t, "# topology: torus\n1???\n????\n????\n???M\n")
+
+	result := NewSolver(board).Solve()
+	want := []game.Position{
+		{Row: 0, Col: 1}, {Row: 0, Col: 3},
+		{Row: 1, Col: 0}, {Row: 1, Col: 1}, {Row: 1, Col: 3},
+		{Row: 3, Col: 0}, {Row: 3, Col: 1},
+	}
+	if !reflect.DeepEqual(result.SafeCells, want) {
+		t.Errorf("SafeCells = %v, want %v", result.SafeCells, want)
+	}
+
+	// 確率の計算でも同じ隣接関係を使う
+	s := NewSolver(board)
+	if rs, _ := s.SolveWith(RuleEnumeration); !reflect.DeepEqual(rs.SafeCells, want) {
+		t.Errorf("SolveWith() SafeCells = %v, want %v", rs.SafeCells, want)
+	}
+}
+
+func TestSolver_Variants_NeverWrong(t *testing.T) {
+	// どのトポロジーと数え方でも、ソルバーの手は実際の地雷配置と一致する
+	for _, topology := range game.Topologies {
+		for _, neighborhood := range game.Neighborhoods {
+			if neighborhood.Check(topology) != nil {
+				continue
+			}
+			t.Run(topology.Name()+"/"+string(neighborhood), func(t *testing.T) {
+				for seed := int64(1); seed <= 5; seed++ {
+					options := game.Options{Seed: seed, Topology: topology, Neighborhood: neighborhood}
+					g := game.NewGameWithOptions(game.Beginner, options)
+					g.Click(game.Position{Row: 4, Col: 4})
+					s := NewSolver(g.Board)
+
+					for g.State == game.Playing {
+						result, _ := s.SolveWith(RuleEnumeration)
+						if !result.CanProgress {
+							break
+						}
+						for _, pos := range result.SafeCells {
+							if g.Board.GetCell(pos).IsMine {
+								t.Fatalf("seed %d: %v is a mine but reported safe", seed, pos)
+							}
+							s.Update(g.Click(pos))
+						}
+						for _, pos := range result.MineCells {
+							if !g.Board.GetCell(pos).IsMine {
+								t.Fatalf("seed %d: %v is safe but reported as a mine", seed, pos)
+							}
+							g.MarkMine(pos)
+							s.Update([]game.Position{pos})
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSolver_ExplodedMineIsKnown(t *testing.T) {
+	// ライフ制で踏んだ地雷（X）は、AIの印と同じく分かっている地雷として数える
+	tests := []struct {
+		name      string
+		text      string
+		wantSafes []game.Position
+		wantMines []game.Position
+	}{
+		{"number satisfied by exploded mine", "X1?\n", []game.Position{{Row: 0, Col: 2}}, []game.Position{}},
+		{"number needs one more mine", "X2?\n", []game.Position{}, []game.Position{{Row: 0, Col: 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := game.ParseBoard(tt.text)
+			if err != nil {
+				t.Fatalf("ParseBoard() error = %v", err)
+			}
+			result := NewSolver(board).Solve()
+			if !reflect.DeepEqual(result.SafeCells, tt.wantSafes) || !reflect.DeepEqual(result.MineCells, tt.wantMines) {
+				t.Errorf("Solve() = safes %v mines %v, want safes %v mines %v",
+					result.SafeCells, result.MineCells, tt.wantSafes, tt.wantMines)
+			}
+		})
+	}
+}
+
+func TestSolver_MaxCellMines(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantMines map[game.Position]int
+		wantProb  float64
+	}{
+		{
+			// 4は両隣が2個ずつで埋まるときだけ満たせる
+			name:      "number fills both neighbors",
+			text:      "# max-cell-mines: 2\n?4?\n",
+			wantMines: map[game.Position]int{{Row: 0, Col: 0}: 2, {Row: 0, Col: 2}: 2},
+		},
+		{
+			// 2は (2,0) (1,1) (0,2) の3通りなので、どちらのマスも地雷がある確率は2/3
+			name:      "number shared between neighbors",
+			text:      "# max-cell-mines: 2\n?2?\n",
+			wantMines: map[game.Position]int{},
+			wantProb:  2.0 / 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := game.ParseBoard(tt.text)
+			if err != nil {
+				t.Fatalf("ParseBoard() error = %v", err)
+			}
+			s := NewSolver(board)
+			result, _ := s.SolveWith(RuleEnumeration)
+
+			got := map[game.Position]int{}
+			for _, pos := range result.MineCells {
+				got[pos] = result.MineCount(pos)
+			}
+			if !reflect.DeepEqual(got, tt.wantMines) {
+				t.Errorf("mines = %v, want %v", got, tt.wantMines)
+			}
+			if tt.wantProb > 0 {
+				if p := s.Probabilities()[game.Position{Row: 0, Col: 0}]; math.Abs(p-tt.wantProb) > 1e-9 {
+					t.Errorf("probability = %v, want %v", p, tt.wantProb)
+				}
+			}
+		})
+	}
+}
+
+func TestSolver_MaxCellMines_NeverWrong(t *testing.T) {
+	// 1マスに複数の地雷を置くルールでも、ソルバーの手と地雷の数は実際の配置と一致する
+	difficulty := game.Difficulty{Name: game.CustomDifficultyName, Width: 9, Height: 9, Mines: 20}
+	for _, capacity := range []int{2, 3} {
+		t.Run(fmt.Sprintf("max %d", capacity), func(t *testing.T) {
+			progressed := 0
+			for seed := int64(1); seed <= 10; seed++ {
+				g := game.NewGameWithOptions(difficulty, game.Options{Seed: seed, MaxCellMines: capacity})
+				g.Click(game.Position{Row: 4, Col: 4})
+				s := NewSolver(g.Board)
+
+				for g.State == game.Playing {
+					result, _ := s.SolveWith(RuleEnumeration)
+					if !result.CanProgress {
+						break
+					}
+					progressed++
+					for _, pos := range result.SafeCells {
+						if g.Board.GetCell(pos).IsMine {
+							t.Fatalf("seed %d: %v is a mine but reported safe", seed, pos)
+						}
+						s.Update(g.Click(pos))
+					}
+					for _, pos := range result.MineCells {
+						if got, want := result.MineCount(pos), g.Board.GetCell(pos).MineCount(); got != want {
+							t.Fatalf("seed %d: %v has %d mines but reported %d", seed, pos, want, got)
+						}
+						g.MarkMines(pos, result.MineCount(pos))
+						s.Update([]game.Position{pos})
+					}
+				}
+			}
+			if progressed == 0 {
+				t.Error("solver never made progress")
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package solver
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// FlagPolicy はプレイヤーが立てた旗をソルバーがどう扱うか.
+type FlagPolicy string
+
+const (
+	// FlagsTrust は旗をすべてAIの印と同じく地雷とみなす.
+	FlagsTrust FlagPolicy = "trust"
+	// FlagsDistrust は見えている数字と矛盾する旗だけを無視し、残りの旗は地雷とみなす.
+	FlagsDistrust FlagPolicy = "distrust"
+	// FlagsIgnore は旗をすべて無視し、未開放のマスとして扱う。ソルバーの標準.
+	FlagsIgnore FlagPolicy = "ignore"
+)
+
+// FlagPolicies は使用できる旗の扱いの一覧.
+var FlagPolicies = []FlagPolicy{FlagsTrust, FlagsDistrust, FlagsIgnore}
+
+// ParseFlagPolicy は名前から旗の扱いを取得.
+func ParseFlagPolicy(name string) (FlagPolicy, error) {
+	for _, p := range FlagPolicies {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown flag policy %q", name)
+}
+
+// ContradictionKind は矛盾の種類.
+type ContradictionKind int
+
+const (
+	// TooManyFlags は数字の周囲の旗と開いた地雷の数が数字より多い。旗は立てた数だけ地雷として数える.
+	TooManyFlags ContradictionKind = iota + 1
+	// FlagOnSafeCell は旗を無視すると安全と確定するマスに旗が立っている.
+	FlagOnSafeCell
+)
+
+func (k ContradictionKind) String() string {
+	switch k {
+	case TooManyFlags:
+		return "too_many_flags"
+	case FlagOnSafeCell:
+		return "flag_on_safe_cell"
+	}
+	return "unknown"
+}
+
+// Contradiction は見えている数字と旗の矛盾.
+type Contradiction struct {
+	Kind ContradictionKind
+	// Position は矛盾が見つかったマス。TooManyFlagsでは数字のマス、FlagOnSafeCellでは旗のマス.
+	Position game.Position
+	// Flags は矛盾の原因と考えられる旗.
+	Flags []game.Position
+}
+
+// Validate は旗が見えている数字と矛盾している箇所を盤面の順に返す.
+func Validate(board *game.Board) []Contradiction {
+	// どの矛盾も旗が原因なので、旗がなければ盤面全体の解析を省く
+	if !hasFlags(board) {
+		return nil
+	}
+
+	var found []Contradiction
+	for row := 0; row < board.Height; row++ {
+		for col := 0; col < board.Width; col++ {
+			pos := game.Position{Row: row, Col: col}
+			cell := board.GetCell(pos)
+			if !cell.IsRevealed || cell.IsMine {
+				continue
+			}
+
+			var flags []game.Position
+			mines := 0
+			for _, adj := range board.GetAdjacentPositions(pos) {
+				c := board.GetCell(adj)
+				switch {
+				case c.IsRevealed && c.IsMine:
+					mines += c.MineCount()
+				case c.IsMarked:
+					mines += c.MarkCount()
+				case !c.IsRevealed && c.IsFlagged:
+					flags = append(flags, adj)
+					mines += c.FlagCount()
+				}
+			}
+			if len(flags) > 0 && mines > cell.Adjacent {
+				found = append(found, Contradiction{Kind: TooManyFlags, Position: pos, Flags: flags})
+			}
+		}
+	}
+
+	// 周囲の数が合っていても、旗を除いた情報だけで安全と分かるマスの旗は間違い
+	a := NewSolverWithOptions(board, Options{FlagPolicy: FlagsIgnore}).analyze()
+	if a.consistent && a.exact {
+		for pos, p := range a.probs {
+			if p < certainty && board.GetCell(pos).IsFlagged {
+				found = append(found, Contradiction{Kind: FlagOnSafeCell, Position: pos, Flags: []game.Position{pos}})
+			}
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		pi, pj := found[i].Position, found[j].Position
+		if pi != pj {
+			if pi.Row != pj.Row {
+				return pi.Row < pj.Row
+			}
+			return pi.Col < pj.Col
+		}
+		return found[i].Kind < found[j].Kind
+	})
+	return found
+}
+
+// hasFlags は未開放のマスに旗が1つでもあるかどうか.
+func hasFlags(board *game.Board) bool {
+	for _, row := range board.Cells {
+		for _, cell := range row {
+			if cell.IsFlagged && !cell.IsRevealed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Contradictions はソルバーの盤面の Validate の結果を返す.
+// 結果はUpdateで盤面の変化を伝えられるまで使い回すので、手を進めるたびに呼んでも盤面全体を調べ直さない.
+func (s *Solver) Contradictions() []Contradiction {
+	if !s.validated {
+		s.contradictions = Validate(s.board)
+		s.validated = true
+	}
+	return s.contradictions
+}
+
+// ContradictingFlags は矛盾の原因と考えられる旗を盤面の順に返す.
+func ContradictingFlags(contradictions []Contradiction) []game.Position {
+	return sortedPositions(flagSet(contradictions))
+}
+
+func flagSet(contradictions []Contradiction) map[game.Position]bool {
+	set := map[game.Position]bool{}
+	for _, c := range contradictions {
+		for _, pos := range c.Flags {
+			set[pos] = true
+		}
+	}
+	return set
+}
+
+// trustsFlag は旗の立ったposを地雷とみなすかどうか.
+func (s *Solver) trustsFlag(pos game.Position) bool {
+	switch s.flagPolicy {
+	case FlagsIgnore:
+		return false
+	case FlagsDistrust:
+		return !s.distrusted[pos]
+	}
+	return true
+}
+
+// refreshFlags はFlagsDistrustのときに矛盾する旗を調べ直す.
+// 信じる旗が変わった場合は、それまでの推論を捨てて盤面全体を調べ直す.
+func (s *Solver) refreshFlags() {
+	if s.flagPolicy != FlagsDistrust {
+		return
+	}
+	distrusted := flagSet(s.Contradictions())
+	if maps.Equal(distrusted, s.distrusted) {
+		return
+	}
+	s.distrusted = distrusted
+	s.scanned = false
+	clear(s.safes)
+	clear(s.mines)
+}
+
+// unflagged は旗の立っていないマスだけを返す.
+func (s *Solver) unflagged(positions []game.Position) []game.Position {
+	result := make([]game.Position, 0, len(positions))
+	for _, pos := range positions {
+		if !s.board.GetCell(pos).IsFlagged {
+			result = append(result, pos)
+		}
+	}
+	return result
+}
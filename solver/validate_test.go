@@ -0,0 +1,141 @@
+package solver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		board string
+		want  []Contradiction
+	}{
+		{
+			name:  "consistent flag",
+			board: "1F",
+			want:  nil,
+		},
+		{
+			name:  "too many flags",
+			board: "1F\nF?",
+			want: []Contradiction{{
+				Kind:     TooManyFlags,
+				Position: game.Position{Row: 0, Col: 0},
+				Flags:    []game.Position{{Row: 0, Col: 1}, {Row: 1, Col: 0}},
+			}},
+		},
+		{
+			// 右の1から(0,2)が地雷と分かるので、左の1の周囲の旗は間違い
+			name:  "flag on a cell proven safe",
+			board: "F1*1.",
+			want: []Contradiction{{
+				Kind:     FlagOnSafeCell,
+				Position: game.Position{Row: 0, Col: 0},
+				Flags:    []game.Position{{Row: 0, Col: 0}},
+			}},
+		},
+		{
+			// 地雷は開いた1つだけなので、旗のマスは安全
+			name:  "flag beyond the global mine count",
+			board: "X?F",
+			want: []Contradiction{{
+				Kind:     FlagOnSafeCell,
+				Position: game.Position{Row: 0, Col: 2},
+				Flags:    []game.Position{{Row: 0, Col: 2}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Validate(mustParse(t, tt.board))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolver_Contradictions(t *testing.T) {
+	// (0,0)の旗は間違いで、本当の地雷は(0,2)
+	board := mustParse(t, "F1*1.")
+	s := NewSolver(board)
+	flag := game.Position{Row: 0, Col: 0}
+
+	if got := ContradictingFlags(s.Contradictions()); !reflect.DeepEqual(got, []game.Position{flag}) {
+		t.Fatalf("ContradictingFlags() = %v, want %v", got, []game.Position{flag})
+	}
+
+	// 盤面の変化を伝えられるまでは前の結果を使い回す
+	board.Cells[0][0].ToggleFlag()
+	if got := s.Contradictions(); len(got) != 1 {
+		t.Errorf("Contradictions() before Update = %+v, want the cached result", got)
+	}
+	s.Update([]game.Position{flag})
+	if got := s.Contradictions(); got != nil {
+		t.Errorf("Contradictions() after removing the flag = %+v, want none", got)
+	}
+}
+
+func TestSolver_FlagPolicy(t *testing.T) {
+	// (0,0)の旗は間違いで、本当の地雷は(0,2)
+	const board = "F1*1."
+	mine := game.Position{Row: 0, Col: 2}
+
+	tests := []struct {
+		policy    FlagPolicy
+		wantSafe  bool
+		wantMines []game.Position
+	}{
+		// 旗を信じると地雷のマスを安全と判断してしまう
+		{policy: FlagsTrust, wantSafe: true, wantMines: []game.Position{mine}},
+		{policy: FlagsDistrust, wantSafe: false, wantMines: []game.Position{mine}},
+		{policy: FlagsIgnore, wantSafe: false, wantMines: []game.Position{mine}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			s := NewSolverWithOptions(mustParse(t, board), Options{FlagPolicy: tt.policy})
+
+			result, _ := s.SolveWith(RuleEnumeration)
+			if got := containsPosition(result.SafeCells, mine); got != tt.wantSafe {
+				t.Errorf("mine %v in SafeCells = %v, want %v (%+v)", mine, got, tt.wantSafe, result)
+			}
+			if !reflect.DeepEqual(result.MineCells, tt.wantMines) {
+				t.Errorf("MineCells = %v, want %v", result.MineCells, tt.wantMines)
+			}
+		})
+	}
+}
+
+func TestSolver_FlagsIgnore_MarksFlaggedMines(t *testing.T) {
+	// 旗は推論に使わないので、旗のマスも数字から地雷と導かれればAIの印を付ける手として返す
+	board := mustParse(t, "1F")
+	s := NewSolverWithOptions(board, Options{FlagPolicy: FlagsIgnore})
+
+	result, _ := s.SolveWith(RuleEnumeration)
+	want := []game.Position{{Row: 0, Col: 1}}
+	if !reflect.DeepEqual(result.MineCells, want) || len(result.SafeCells) != 0 {
+		t.Fatalf("SolveWith() = %+v, want mines %v", result, want)
+	}
+
+	// 印を付けた後は、もう手はない
+	board.Cells[0][1].Mark()
+	if result, rule := s.SolveWith(RuleEnumeration); result.CanProgress {
+		t.Errorf("SolveWith() after marking = %+v (%v), want no progress", result, rule)
+	}
+}
+
+func TestParseFlagPolicy(t *testing.T) {
+	for _, policy := range FlagPolicies {
+		if got, err := ParseFlagPolicy(string(policy)); err != nil || got != policy {
+			t.Errorf("ParseFlagPolicy(%q) = %q, %v", policy, got, err)
+		}
+	}
+	if _, err := ParseFlagPolicy("blind"); err == nil {
+		t.Error("ParseFlagPolicy(\"blind\") should fail")
+	}
+}
@@ -0,0 +1,100 @@
+package solver
+
+import (
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// WindowSolver は無限盤面の一部（画面に見えている範囲など）を切り出して解くソルバー.
+// 範囲の外の情報は使わないので、見つかる手は範囲の中だけになる。手は無限盤面の位置で返す.
+// 必要な情報は作成時に写し取るので、解いている間に無限盤面が変わっても（別のゴルーチンからでも）構わない.
+type WindowSolver struct {
+	origin game.Position
+	window *game.Board
+	solver *Solver
+	// opened は外周のマスのうち無限盤面ではすでに開いているマス（切り出した盤面の位置）.
+	opened  map[game.Position]bool
+	density float64
+}
+
+// NewWindowSolver は origin を左上とする width×height の範囲を解くソルバーを作成する.
+// 範囲の外周は数字を使えないので、外周から1マス内側までが推論の対象になる.
+func NewWindowSolver(board *game.InfiniteBoard, origin game.Position, width, height int) *WindowSolver {
+	window := board.Window(origin, width, height)
+	w := &WindowSolver{
+		origin:  origin,
+		window:  window,
+		solver:  NewSolver(window),
+		opened:  map[game.Position]bool{},
+		density: board.Density,
+	}
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			pos := game.Position{Row: row, Col: col}
+			if w.onEdge(pos) && board.GetCell(w.toBoard(pos)).IsRevealed {
+				w.opened[pos] = true
+			}
+		}
+	}
+	return w
+}
+
+// SolveWith は易しい順にmaxRuleまでの規則を試し、最初に手が見つかった規則の結果とその規則を返す.
+// 外周のマスのうち無限盤面ではすでに開いているマスは、安全な手から除く.
+func (w *WindowSolver) SolveWith(maxRule Rule) (SolverResult, Rule) {
+	result, rule := w.solver.SolveWith(maxRule)
+	var safes, mines []game.Position
+	for _, pos := range result.SafeCells {
+		if !w.opened[pos] {
+			safes = append(safes, w.toBoard(pos))
+		}
+	}
+	for _, pos := range result.MineCells {
+		mines = append(mines, w.toBoard(pos))
+	}
+	translated := newResult(safes, mines)
+	if !translated.CanProgress {
+		return translated, RuleNone
+	}
+	return translated, rule
+}
+
+// Guess は範囲の外周を除いたマスから、地雷確率が最も低いマスを選ぶ.
+// 制約のないマスの確率には無限盤面の地雷の密度を使う.
+func (w *WindowSolver) Guess() (Guess, bool) {
+	a := w.solver.analyze()
+	probs := map[game.Position]float64{}
+	for pos, p := range a.probs {
+		if w.onEdge(pos) {
+			continue
+		}
+		if !w.constrained(pos) {
+			p = w.density
+		}
+		probs[pos] = p
+	}
+	candidates := guessCandidates(w.window, probs)
+	if len(candidates) == 0 {
+		return Guess{}, false
+	}
+	best := candidates[0]
+	return Guess{Position: w.toBoard(best), Probability: probs[best]}, true
+}
+
+// constrained はマスの周囲に開いた数字があるかどうか.
+func (w *WindowSolver) constrained(pos game.Position) bool {
+	for _, adj := range w.window.GetAdjacentPositions(pos) {
+		if c := w.window.GetCell(adj); c.IsRevealed && !c.IsMine {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *WindowSolver) onEdge(pos game.Position) bool {
+	return pos.Row == 0 || pos.Col == 0 || pos.Row == w.window.Height-1 || pos.Col == w.window.Width-1
+}
+
+// toBoard は切り出した盤面の位置を無限盤面の位置に変換する.
+func (w *WindowSolver) toBoard(pos game.Position) game.Position {
+	return game.Position{Row: w.origin.Row + pos.Row, Col: w.origin.Col + pos.Col}
+}
@@ -0,0 +1,64 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestWindowSolver_NeverWrong(t *testing.T) {
+	// 範囲の外の数字を知らなくても、範囲の中で見つけた手は実際の地雷配置と一致する
+	origin := game.Position{Row: -8, Col: -12}
+	progressed := false
+	for seed := int64(1); seed <= 5; seed++ {
+		g := game.NewInfiniteGame(seed, game.DefaultInfiniteDensity)
+		start := g.Score
+		for range 100 {
+			result, _ := NewWindowSolver(g.Board, origin, 25, 17).SolveWith(RuleEnumeration)
+			if !result.CanProgress {
+				break
+			}
+			for _, pos := range result.MineCells {
+				if !g.Board.GetCell(pos).IsMine {
+					t.Fatalf("seed %d: %v is safe but reported as a mine", seed, pos)
+				}
+				g.MarkMine(pos)
+			}
+			for _, pos := range result.SafeCells {
+				if g.Board.GetCell(pos).IsMine {
+					t.Fatalf("seed %d: %v is a mine but reported safe", seed, pos)
+				}
+				g.Click(pos)
+			}
+		}
+		if g.Score > start {
+			progressed = true
+		}
+		// 範囲の外のマスは推論で開かない
+		for row := origin.Row - 2; row < origin.Row+19; row++ {
+			for _, col := range []int{origin.Col - 2, origin.Col + 26} {
+				if c := g.Board.GetCell(game.Position{Row: row, Col: col}); c.IsMarked {
+					t.Errorf("seed %d: (%d,%d) outside the window was marked", seed, row, col)
+				}
+			}
+		}
+	}
+	if !progressed {
+		t.Error("solver never made progress")
+	}
+}
+
+func TestWindowSolver_Guess(t *testing.T) {
+	g := game.NewInfiniteGame(1, game.DefaultInfiniteDensity)
+	ws := NewWindowSolver(g.Board, game.Position{Row: -5, Col: -5}, 11, 11)
+	guess, ok := ws.Guess()
+	if !ok {
+		t.Fatal("Guess() found no candidate")
+	}
+	if c := g.Board.GetCell(guess.Position); c.IsRevealed {
+		t.Errorf("Guess() = %v, which is already revealed", guess.Position)
+	}
+	if guess.Position.Row <= -5 || guess.Position.Row >= 5 || guess.Position.Col <= -5 || guess.Position.Col >= 5 {
+		t.Errorf("Guess() = %v, want a cell inside the window edge", guess.Position)
+	}
+}
@@ -0,0 +1,104 @@
+package sshserver
+
+import (
+	"sync"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/tui"
+)
+
+// Coop は接続しているプレイヤー全員が1つのゲームを一緒に遊ぶ協力プレイ.
+// ゲームそのものはゲームのロックで守り、ここではプレイヤーの一覧とカーソルだけを守る.
+type Coop struct {
+	mu      sync.Mutex
+	game    *game.Game
+	players []*Teammate
+	// nextColor は次に加わるプレイヤーの色の番号.
+	nextColor int
+}
+
+// Teammate は協力プレイに加わっている1人のプレイヤー。tui.Coop としてモデルに渡す.
+type Teammate struct {
+	coop   *Coop
+	name   string
+	color  int
+	cursor game.Position
+	// updates はほかのプレイヤーの変更を知らせる。知らせが溜まっても1つにまとめる.
+	updates chan struct{}
+}
+
+// NewCoop は g を全員で遊ぶ協力プレイを作成する.
+func NewCoop(g *game.Game) *Coop {
+	return &Coop{game: g}
+}
+
+// Join はプレイヤーを協力プレイに加え、まだ使っていない色を割り当てる.
+func (c *Coop) Join(name string) *Teammate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &Teammate{coop: c, name: name, color: c.nextColor, updates: make(chan struct{}, 1)}
+	c.nextColor++
+	c.players = append(c.players, t)
+	return t
+}
+
+// Leave はプレイヤーを協力プレイから外し、ほかのプレイヤーの画面からカーソルを消す.
+func (t *Teammate) Leave() {
+	c := t.coop
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, p := range c.players {
+		if p == t {
+			c.players = append(c.players[:i], c.players[i+1:]...)
+			close(t.updates)
+			c.notify(t)
+			return
+		}
+	}
+}
+
+// Game は全員で遊ぶゲーム.
+func (t *Teammate) Game() *game.Game {
+	return t.coop.game
+}
+
+// Broadcast は自分のカーソルの位置を記録し、ほかのプレイヤーに知らせる.
+func (t *Teammate) Broadcast(cursor game.Position) {
+	c := t.coop
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.cursor = cursor
+	c.notify(t)
+}
+
+// notify は from 以外のプレイヤーに変更を知らせる。呼び出し側で c.mu をロックしておく.
+func (c *Coop) notify(from *Teammate) {
+	for _, p := range c.players {
+		if p == from {
+			continue
+		}
+		select {
+		case p.updates <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Partners は自分以外のプレイヤーを加わった順に返す.
+func (t *Teammate) Partners() []tui.Partner {
+	c := t.coop
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var partners []tui.Partner
+	for _, p := range c.players {
+		if p != t {
+			partners = append(partners, tui.Partner{Name: p.name, Cursor: p.cursor, Color: p.color})
+		}
+	}
+	return partners
+}
+
+// Updates はほかのプレイヤーの変更を知らせるチャネル.
+func (t *Teammate) Updates() <-chan struct{} {
+	return t.updates
+}
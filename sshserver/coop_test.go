@@ -0,0 +1,50 @@
+package sshserver
+
+import (
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestCoop(t *testing.T) {
+	c := NewCoop(game.NewGame(game.Beginner))
+	alice := c.Join("alice")
+	bob := c.Join("bob")
+	if alice.Game() != bob.Game() {
+		t.Fatal("teammates should share one game")
+	}
+
+	// alice が動くと bob にだけ知らせが届き、bob から alice のカーソルが見える
+	alice.Broadcast(game.Position{Row: 2, Col: 3})
+	select {
+	case <-bob.Updates():
+	default:
+		t.Fatal("bob was not told about alice's move")
+	}
+	select {
+	case <-alice.Updates():
+		t.Fatal("alice was told about her own move")
+	default:
+	}
+	partners := bob.Partners()
+	if len(partners) != 1 || partners[0].Name != "alice" || partners[0].Cursor != (game.Position{Row: 2, Col: 3}) {
+		t.Fatalf("bob Partners() = %+v", partners)
+	}
+	if alice.Partners()[0].Color == partners[0].Color {
+		t.Error("teammates should have different colors")
+	}
+
+	// 抜けたプレイヤーのチャネルは閉じ、残りのプレイヤーに知らせが届く
+	alice.Leave()
+	if _, ok := <-alice.Updates(); ok {
+		t.Error("alice's updates should be closed after leaving")
+	}
+	select {
+	case <-bob.Updates():
+	default:
+		t.Fatal("bob was not told that alice left")
+	}
+	if partners := bob.Partners(); len(partners) != 0 {
+		t.Errorf("after alice left, Partners() = %+v", partners)
+	}
+}
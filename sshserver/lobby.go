@@ -0,0 +1,67 @@
+package sshserver
+
+import (
+	"sync"
+
+	"github.com/r-horie/ai-minesweeper/tui"
+)
+
+// Lobby は接続しているプレイヤーとそれぞれのゲームの様子を保持する.
+// プレイヤーは接続の順に並ぶ.
+type Lobby struct {
+	mu      sync.Mutex
+	players []*Member
+}
+
+// Member はロビーにいる1人のプレイヤー。tui.Lobby としてモデルに渡す.
+type Member struct {
+	lobby  *Lobby
+	name   string
+	status tui.PlayerStatus
+}
+
+// NewLobby は誰もいないロビーを作成する.
+func NewLobby() *Lobby {
+	return &Lobby{}
+}
+
+// Join はプレイヤーをロビーに加える.
+func (l *Lobby) Join(name string) *Member {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := &Member{lobby: l, name: name}
+	l.players = append(l.players, m)
+	return m
+}
+
+// Leave はプレイヤーをロビーから外す.
+func (m *Member) Leave() {
+	l := m.lobby
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, p := range l.players {
+		if p == m {
+			l.players = append(l.players[:i], l.players[i+1:]...)
+			return
+		}
+	}
+}
+
+// Report は自分のゲームの様子を更新する.
+func (m *Member) Report(status tui.PlayerStatus) {
+	m.lobby.mu.Lock()
+	defer m.lobby.mu.Unlock()
+	m.status = status
+}
+
+// Peers はロビーにいるプレイヤー全員の様子を返す.
+func (m *Member) Peers() []tui.Peer {
+	l := m.lobby
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	peers := make([]tui.Peer, len(l.players))
+	for i, p := range l.players {
+		peers[i] = tui.Peer{Name: p.name, PlayerStatus: p.status}
+	}
+	return peers
+}
@@ -0,0 +1,180 @@
+package sshserver
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/tui"
+)
+
+// RaceRule はレースの勝者の決め方.
+type RaceRule string
+
+const (
+	// RaceFirstClear は最初に盤面を開き切ったプレイヤーが勝つ.
+	RaceFirstClear RaceRule = "first"
+	// RaceFewestGuesses はラウンドが終わった時点で、開き切ったプレイヤーのうち推測の回数が最も少ないプレイヤーが勝つ.
+	// 同じ回数なら先に開き切った方が勝つ.
+	RaceFewestGuesses RaceRule = "guesses"
+)
+
+// RaceRules は使用できる勝者の決め方の一覧.
+var RaceRules = []RaceRule{RaceFirstClear, RaceFewestGuesses}
+
+// ParseRaceRule は名前から勝者の決め方を取得.
+func ParseRaceRule(name string) (RaceRule, error) {
+	for _, r := range RaceRules {
+		if string(r) == name {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown race rule %q", name)
+}
+
+// Race は接続しているプレイヤー全員が同じシードの盤面を同時に遊ぶレース.
+// ラウンドは参加したプレイヤー全員の勝ち負けが決まると終わり、次のラウンドは新しいシードで始まる.
+type Race struct {
+	mu   sync.Mutex
+	rule RaceRule
+	// seed は最初のラウンドのシード。0ならラウンドごとにランダムなシードを使う.
+	seed      int64
+	round     int
+	roundSeed int64
+	racers    []*Racer
+}
+
+// Racer はレースに参加している1人のプレイヤー。tui.Race としてモデルに渡す.
+type Racer struct {
+	race *Race
+	name string
+	// round は参加しているラウンド。0ならまだどのラウンドにも参加していない.
+	round  int
+	status tui.PlayerStatus
+	// cleared は盤面を開き切った時刻.
+	cleared time.Time
+}
+
+// NewRace は誰もいないレースを作成する。seed を指定すると、ラウンドごとに seed, seed+1, ... の盤面を使う.
+func NewRace(rule RaceRule, seed int64) *Race {
+	return &Race{rule: rule, seed: seed}
+}
+
+// Join はプレイヤーをレースに加える。ラウンドへの参加は NextRound で行う.
+func (r *Race) Join(name string) *Racer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := &Racer{race: r, name: name}
+	r.racers = append(r.racers, p)
+	return p
+}
+
+// Leave はプレイヤーをレースから外す。残りのプレイヤーが全員終わっていれば、そのラウンドは終わる.
+func (p *Racer) Leave() {
+	r := p.race
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, q := range r.racers {
+		if q == p {
+			r.racers = append(r.racers[:i], r.racers[i+1:]...)
+			return
+		}
+	}
+}
+
+// NextRound は次に遊ぶラウンドのシードを返す.
+// 今のラウンドが終わっていれば新しいラウンドを始め、続いていればまだ参加していない場合だけそのラウンドに加わる.
+func (p *Racer) NextRound() (int64, bool) {
+	r := p.race
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case r.roundOver():
+		r.startRound()
+	case p.round == r.round:
+		return 0, false
+	}
+	p.round = r.round
+	p.status = tui.PlayerStatus{}
+	p.cleared = time.Time{}
+	return r.roundSeed, true
+}
+
+// startRound は新しいラウンドを始める.
+func (r *Race) startRound() {
+	r.round++
+	if r.seed != 0 {
+		r.roundSeed = r.seed + int64(r.round-1)
+	} else {
+		r.roundSeed = rand.Int63() //nolint:gosec // シードの生成にはmath/randで十分
+	}
+}
+
+// roundOver は今のラウンドに参加しているプレイヤー全員の勝ち負けが決まったかどうか.
+// まだ1つもラウンドが始まっていなければ終わっているとみなす.
+func (r *Race) roundOver() bool {
+	if r.round == 0 {
+		return true
+	}
+	for _, p := range r.racers {
+		if p.round == r.round && p.status.State == game.Playing {
+			return false
+		}
+	}
+	return true
+}
+
+// Report は自分のゲームの様子を更新する。開き切った時刻はここで記録する.
+func (p *Racer) Report(status tui.PlayerStatus) {
+	p.race.mu.Lock()
+	defer p.race.mu.Unlock()
+	if status.State == game.Won && p.cleared.IsZero() {
+		p.cleared = time.Now()
+	}
+	p.status = status
+}
+
+// Peers は自分と同じラウンドのプレイヤーを順位の順に返す.
+// 開き切ったプレイヤーが勝者の決め方に従って先に並び、残りは開いたマスの多い順に並ぶ.
+func (p *Racer) Peers() []tui.Peer {
+	r := p.race
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var racers []*Racer
+	for _, q := range r.racers {
+		if q.round == p.round {
+			racers = append(racers, q)
+		}
+	}
+	slices.SortStableFunc(racers, r.compare)
+
+	peers := make([]tui.Peer, len(racers))
+	for i, q := range racers {
+		peers[i] = tui.Peer{Name: q.name, PlayerStatus: q.status}
+	}
+	over := p.round < r.round || r.roundOver()
+	if len(peers) > 0 && peers[0].State == game.Won && (r.rule == RaceFirstClear || over) {
+		peers[0].Winner = true
+	}
+	return peers
+}
+
+// compare はスコアボードの順位を決める.
+func (r *Race) compare(a, b *Racer) int {
+	aWon, bWon := a.status.State == game.Won, b.status.State == game.Won
+	switch {
+	case aWon && bWon:
+		if r.rule == RaceFewestGuesses && a.status.Guesses != b.status.Guesses {
+			return a.status.Guesses - b.status.Guesses
+		}
+		return a.cleared.Compare(b.cleared)
+	case aWon:
+		return -1
+	case bWon:
+		return 1
+	}
+	return b.status.Cleared - a.status.Cleared
+}
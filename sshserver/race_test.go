@@ -0,0 +1,114 @@
+package sshserver
+
+import (
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/tui"
+)
+
+func TestRace_Rounds(t *testing.T) {
+	r := NewRace(RaceFirstClear, 42)
+	alice := r.Join("alice")
+	bob := r.Join("bob")
+
+	// 同じラウンドの2人には同じシードを配る
+	seed, ok := alice.NextRound()
+	if !ok || seed != 42 {
+		t.Fatalf("alice NextRound() = %d, %v, want 42, true", seed, ok)
+	}
+	if seed, ok := bob.NextRound(); !ok || seed != 42 {
+		t.Fatalf("bob NextRound() = %d, %v, want 42, true", seed, ok)
+	}
+
+	// bob がまだ遊んでいる間は、終わった alice は次のラウンドに進めない
+	alice.Report(tui.PlayerStatus{State: game.Lost})
+	if seed, ok := alice.NextRound(); ok {
+		t.Fatalf("NextRound() = %d, true while bob is playing", seed)
+	}
+
+	// 全員が終わると次のラウンドが始まり、遅れて押した bob も同じラウンドに加わる
+	bob.Report(tui.PlayerStatus{State: game.Won})
+	if seed, ok := alice.NextRound(); !ok || seed != 43 {
+		t.Fatalf("alice NextRound() = %d, %v, want 43, true", seed, ok)
+	}
+	if seed, ok := bob.NextRound(); !ok || seed != 43 {
+		t.Fatalf("bob NextRound() = %d, %v, want 43, true", seed, ok)
+	}
+
+	// 切断したプレイヤーはラウンドの終わりを妨げない
+	alice.Report(tui.PlayerStatus{State: game.Won})
+	bob.Leave()
+	if seed, ok := alice.NextRound(); !ok || seed != 44 {
+		t.Fatalf("NextRound() after bob left = %d, %v, want 44, true", seed, ok)
+	}
+}
+
+func TestRace_Peers(t *testing.T) {
+	tests := []struct {
+		name string
+		rule RaceRule
+		// finish は carol が開き切るかどうか
+		finish     bool
+		wantOrder  []string
+		wantWinner bool
+	}{
+		// 先に開き切った alice が、carol を待たずに勝者になる
+		{"first clear", RaceFirstClear, false, []string{"alice", "bob", "carol", "dave"}, true},
+		// 推測の少ない bob が上に来るが、carol が遊んでいる間は勝者が決まらない
+		{"fewest guesses while playing", RaceFewestGuesses, false, []string{"bob", "alice", "carol", "dave"}, false},
+		{"fewest guesses", RaceFewestGuesses, true, []string{"bob", "alice", "carol", "dave"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRace(tt.rule, 1)
+			var racers []*Racer
+			for _, name := range []string{"dave", "carol", "alice", "bob"} {
+				p := r.Join(name)
+				p.NextRound()
+				racers = append(racers, p)
+			}
+			dave, carol, alice, bob := racers[0], racers[1], racers[2], racers[3]
+			dave.Report(tui.PlayerStatus{State: game.Lost, Cleared: 5})
+			carol.Report(tui.PlayerStatus{State: game.Playing, Cleared: 30})
+			alice.Report(tui.PlayerStatus{State: game.Won, Cleared: 71, Guesses: 3})
+			bob.Report(tui.PlayerStatus{State: game.Won, Cleared: 71, Guesses: 1})
+			if tt.finish {
+				carol.Report(tui.PlayerStatus{State: game.Lost, Cleared: 30})
+			}
+
+			peers := dave.Peers()
+			var order []string
+			for _, p := range peers {
+				order = append(order, p.Name)
+			}
+			if len(order) != len(tt.wantOrder) {
+				t.Fatalf("Peers() = %v, want %v", order, tt.wantOrder)
+			}
+			for i := range order {
+				if order[i] != tt.wantOrder[i] {
+					t.Fatalf("Peers() = %v, want %v", order, tt.wantOrder)
+				}
+			}
+			if peers[0].Winner != tt.wantWinner {
+				t.Errorf("%s Winner = %v, want %v", peers[0].Name, peers[0].Winner, tt.wantWinner)
+			}
+			for _, p := range peers[1:] {
+				if p.Winner {
+					t.Errorf("%s is also a winner", p.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRaceRule(t *testing.T) {
+	for _, rule := range RaceRules {
+		if got, err := ParseRaceRule(string(rule)); err != nil || got != rule {
+			t.Errorf("ParseRaceRule(%q) = %q, %v", rule, got, err)
+		}
+	}
+	if _, err := ParseRaceRule("slowest"); err == nil {
+		t.Error("ParseRaceRule(\"slowest\") returned no error")
+	}
+}
@@ -0,0 +1,103 @@
+// Package sshserver はSSHで接続した端末ごとにゲームを提供するサーバー.
+// バイナリを入れていない端末からでも ssh コマンドだけでプレイできる.
+package sshserver
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/activeterm"
+	bm "github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/tui"
+)
+
+// Options はSSHサーバーの設定.
+type Options struct {
+	// Addr は待ち受けるアドレス.
+	Addr string
+	// HostKeyPath はホスト鍵のファイル。なければ作成する.
+	HostKeyPath string
+	// Config は接続ごとのゲームの設定。難易度やテーマは全員に共通.
+	Config config.Config
+	// Lobby が true なら、接続しているプレイヤーの一覧とそれぞれの進み具合を全員の画面に出す.
+	Lobby bool
+	// Race を指定すると、全員が同じシードの盤面で競うレースになり、ロビーの代わりにスコアボードを出す.
+	Race RaceRule
+	// Seed はレースの最初のラウンドのシード。0ならラウンドごとにランダム.
+	Seed int64
+	// Coop が true なら、接続しているプレイヤー全員が1つのゲームを一緒に遊ぶ.
+	Coop bool
+}
+
+// New はSSHサーバーを作成する。協力プレイでなければ接続ごとに新しいゲームを始め、接続が切れるとそのゲームは捨てる.
+// 疑似端末を要求しない接続は受け付けない.
+func New(opts Options) (*ssh.Server, error) {
+	var shared modes
+	switch {
+	case opts.Race != "":
+		shared.race = NewRace(opts.Race, opts.Seed)
+	case opts.Coop:
+		g, err := tui.NewGame(opts.Config)
+		if err != nil {
+			return nil, err
+		}
+		shared.coop = NewCoop(g)
+	case opts.Lobby:
+		shared.lobby = NewLobby()
+	}
+	return wish.NewServer(
+		wish.WithAddress(opts.Addr),
+		wish.WithHostKeyPath(opts.HostKeyPath),
+		wish.WithMiddleware(
+			bm.Middleware(handler(opts.Config, shared)),
+			activeterm.Middleware(),
+			logging.Middleware(),
+		),
+	)
+}
+
+// modes は接続したプレイヤーどうしのつながり方。どれか1つだけを使う.
+type modes struct {
+	lobby *Lobby
+	race  *Race
+	coop  *Coop
+}
+
+// handler は接続ごとのモデルを作る。色数と背景色は接続してきた端末に合わせる.
+func handler(cfg config.Config, shared modes) bm.Handler {
+	return func(sess ssh.Session) (tea.Model, []tea.ProgramOption) {
+		m, err := tui.NewModelWithRenderer(cfg, bm.MakeRenderer(sess))
+		if err != nil {
+			wish.Fatalln(sess, err)
+			return nil, nil
+		}
+		// 盤面の画像はサーバーのファイルに書かれてしまうので保存させない
+		m = m.WithoutSnapshots()
+		switch {
+		case shared.race != nil:
+			racer := shared.race.Join(sess.User())
+			leaveOnClose(sess, racer.Leave)
+			m = m.WithRace(racer)
+		case shared.coop != nil:
+			teammate := shared.coop.Join(sess.User())
+			leaveOnClose(sess, teammate.Leave)
+			m = m.WithCoop(teammate)
+		case shared.lobby != nil:
+			member := shared.lobby.Join(sess.User())
+			leaveOnClose(sess, member.Leave)
+			m = m.WithLobby(member)
+		}
+		return m, []tea.ProgramOption{tea.WithAltScreen()}
+	}
+}
+
+// leaveOnClose は接続が切れたときに leave を呼ぶ.
+func leaveOnClose(sess ssh.Session, leave func()) {
+	go func() {
+		<-sess.Context().Done()
+		leave()
+	}()
+}
@@ -0,0 +1,104 @@
+package sshserver
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/wish/testsession"
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/tui"
+)
+
+func TestLobby(t *testing.T) {
+	l := NewLobby()
+	alice := l.Join("alice")
+	bob := l.Join("bob")
+	alice.Report(tui.PlayerStatus{Difficulty: "expert", State: game.Playing, Progress: 30})
+	bob.Report(tui.PlayerStatus{Difficulty: "beginner", State: game.Won, Progress: 100})
+
+	peers := bob.Peers()
+	if len(peers) != 2 || peers[0].Name != "alice" || peers[0].Progress != 30 || peers[1].State != game.Won {
+		t.Fatalf("Peers() = %+v", peers)
+	}
+
+	alice.Leave()
+	if peers := bob.Peers(); len(peers) != 1 || peers[0].Name != "bob" {
+		t.Errorf("after alice left, Peers() = %+v", peers)
+	}
+}
+
+// safeBuffer は別のゴルーチンから書き込まれる出力.
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServer_Session(t *testing.T) {
+	cfg := config.Default()
+	cfg.Language = "en"
+	cfg.Theme = "mono"
+	srv, err := New(Options{
+		Addr:        "127.0.0.1:0",
+		HostKeyPath: filepath.Join(t.TempDir(), "host_ed25519"),
+		Config:      cfg,
+		Lobby:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sess := testsession.New(t, srv, &gossh.ClientConfig{User: "alice"})
+	// dumb の端末には背景色を問い合わせないので、応答を待たずに画面が出る
+	if err := sess.RequestPty("dumb", 40, 120, gossh.TerminalModes{}); err != nil {
+		t.Fatal(err)
+	}
+	var out safeBuffer
+	sess.Stdout = &out
+	stdin, err := sess.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sess.Shell(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 接続ごとのゲームの画面に、ロビーにいる自分が出る
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "Lobby: alice") {
+		if time.Now().After(deadline) {
+			t.Fatalf("the game screen did not show the lobby:\n%s", out.String())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if _, err := io.WriteString(stdin, "q"); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- sess.Wait() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the session did not end after quitting")
+	}
+}
@@ -52,6 +52,12 @@ func getCellChar(cell *game.Cell) string {
 	if cell.IsFlagged {
 		return "F"
 	}
+	if cell.IsMarked {
+		return "M"
+	}
+	if cell.IsQuestioned {
+		return "Q"
+	}
 	if !cell.IsRevealed {
 		return "?"
 	}
@@ -96,8 +102,10 @@ func CompareBoardStates(board1, board2 *game.Board) string {
 
 // cellsEqual 2つのセルが等しいか判定.
 func cellsEqual(c1, c2 *game.Cell) bool {
-	return c1.IsMine == c2.IsMine &&
+	return c1.MineCount() == c2.MineCount() &&
 		c1.IsRevealed == c2.IsRevealed &&
-		c1.IsFlagged == c2.IsFlagged &&
+		c1.FlagCount() == c2.FlagCount() &&
+		c1.MarkCount() == c2.MarkCount() &&
+		c1.IsQuestioned == c2.IsQuestioned &&
 		c1.Adjacent == c2.Adjacent
 }
@@ -85,7 +85,7 @@ func TestGameBuilder(t *testing.T) {
 		if !g.FirstClick {
 			t.Error("Default game should have FirstClick=true")
 		}
-		if g.Difficulty.Name != "初級" {
+		if g.Difficulty.Name != "beginner" {
 			t.Error("Default game should be Beginner difficulty")
 		}
 	})
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// Partner は協力プレイのほかのプレイヤー.
+type Partner struct {
+	Name   string
+	Cursor game.Position
+	// Color はプレイヤーごとに割り当てた色の番号.
+	Color int
+}
+
+// Coop は複数のプレイヤーが1つのゲームを一緒に遊ぶ協力プレイ（SSHの協力プレイなど）.
+// プレイヤーごとに別のゴルーチンから呼ばれる。ゲームの読み書きはゲームのロックの間に行う.
+type Coop interface {
+	// Game は全員で遊ぶゲーム.
+	Game() *game.Game
+	// Broadcast は自分のカーソルの位置を伝え、ゲームかカーソルが変わったことをほかのプレイヤーに知らせる.
+	Broadcast(cursor game.Position)
+	// Partners はほかのプレイヤーの一覧.
+	Partners() []Partner
+	// Updates はほかのプレイヤーが Broadcast するか抜けるたびに値を受け取るチャネル。自分が抜けると閉じる.
+	Updates() <-chan struct{}
+}
+
+// coopMsg はほかのプレイヤーがゲームかカーソルを変えたことを伝える.
+type coopMsg struct{}
+
+// partnerColors はほかのプレイヤーのカーソルの色（ANSI 256色）.
+var partnerColors = []string{"166", "34", "127", "37", "136", "98"}
+
+// WithCoop は協力プレイのゲームを一緒に遊ぶモデルを返す.
+// AIのソルバーはロックを持って複製した盤面を解くので、支援と自動プレイもそのまま使える.
+func (m Model) WithCoop(c Coop) Model {
+	m.coop = c
+	m.game = c.Game()
+	m.solver = nil
+	m.game.RLock()
+	defer m.game.RUnlock()
+	m.checkFlags()
+	m.partners = c.Partners()
+	c.Broadcast(m.cursor)
+	return m
+}
+
+// waitCoop はほかのプレイヤーがゲームかカーソルを変えるのを待つ.
+func (m Model) waitCoop() tea.Cmd {
+	if m.coop == nil {
+		return nil
+	}
+	updates := m.coop.Updates()
+	return func() tea.Msg {
+		if _, ok := <-updates; !ok {
+			return nil
+		}
+		return coopMsg{}
+	}
+}
+
+// handleCoop はほかのプレイヤーの変更を画面に反映する。ゲームのロックを持って呼ぶ.
+func (m Model) handleCoop() (tea.Model, tea.Cmd) {
+	m.partners = m.coop.Partners()
+	m.checkFlags()
+	if m.game.FirstClick {
+		// ほかのプレイヤーが新しいゲームを始めた
+		m.rating = nil
+	}
+	if !m.game.Board.IsValidPosition(m.cursor) {
+		m.cursor = game.Position{}
+	}
+	return m, m.waitCoop()
+}
+
+// partnerAt はposにカーソルを置いているほかのプレイヤーを返す.
+func (m Model) partnerAt(pos game.Position) (Partner, bool) {
+	for _, p := range m.partners {
+		if p.Cursor == pos {
+			return p, true
+		}
+	}
+	return Partner{}, false
+}
+
+// partnerCursor はほかのプレイヤーのカーソルがあるマスを、そのプレイヤーの色で示す.
+// ASCIIテーマでは色の代わりに波括弧で囲む.
+func (st styles) partnerCursor(style lipgloss.Style, content string, p Partner) (lipgloss.Style, string) {
+	if st.theme.ASCII {
+		return style, "{" + content + "}"
+	}
+	return style.Background(st.partnerColor(p)), content
+}
+
+func (st styles) partnerColor(p Partner) lipgloss.TerminalColor {
+	return lipgloss.Color(partnerColors[p.Color%len(partnerColors)])
+}
+
+// renderCoop は一緒に遊んでいるプレイヤーの一覧を、それぞれのカーソルの色で描画する.
+func (m Model) renderCoop() string {
+	names := []string{m.text.T("coop.you")}
+	for _, p := range m.partners {
+		name := p.Name
+		if !m.styles.theme.ASCII {
+			name = m.styles.renderer.NewStyle().Foreground(m.styles.partnerColor(p)).Render(name)
+		}
+		names = append(names, name)
+	}
+	return m.styles.help.Render(m.text.T("coop", strings.Join(names, ", ")))
+}
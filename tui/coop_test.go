@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// coopTable は協力プレイのゲームと、席に着いたプレイヤー.
+type coopTable struct {
+	mu    sync.Mutex
+	game  *game.Game
+	seats []*coopSeat
+}
+
+// coopSeat は coopTable の1人分の Coop.
+type coopSeat struct {
+	table   *coopTable
+	name    string
+	cursor  game.Position
+	updates chan struct{}
+}
+
+func (tb *coopTable) join(name string) *coopSeat {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	s := &coopSeat{table: tb, name: name, updates: make(chan struct{}, 1)}
+	tb.seats = append(tb.seats, s)
+	return s
+}
+
+func (s *coopSeat) Game() *game.Game { return s.table.game }
+
+func (s *coopSeat) Broadcast(cursor game.Position) {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+	s.cursor = cursor
+	for _, other := range s.table.seats {
+		if other != s {
+			select {
+			case other.updates <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (s *coopSeat) Partners() []Partner {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+	var partners []Partner
+	for i, other := range s.table.seats {
+		if other != s {
+			partners = append(partners, Partner{Name: other.name, Cursor: other.cursor, Color: i})
+		}
+	}
+	return partners
+}
+
+func (s *coopSeat) Updates() <-chan struct{} { return s.updates }
+
+func newCoopModels(t *testing.T, names ...string) []Model {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Language = "en"
+	cfg.Theme = "mono"
+	table := &coopTable{game: game.NewGameWithOptions(game.Beginner, game.Options{Seed: 3})}
+	var models []Model
+	for _, name := range names {
+		m, err := NewModel(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		models = append(models, m.WithCoop(table.join(name)))
+	}
+	return models
+}
+
+func press(m Model, keys ...string) Model {
+	for _, key := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+	return m
+}
+
+func TestModel_Coop_SharedBoard(t *testing.T) {
+	models := newCoopModels(t, "alice", "bob")
+	alice, bob := models[0], models[1]
+
+	// alice が開いたマスは bob のゲームでも開いている
+	alice = press(alice, "l", "enter")
+	if cell := bob.game.Board.GetCell(game.Position{Row: 0, Col: 1}); !cell.IsRevealed {
+		t.Fatal("bob should see the cell alice revealed")
+	}
+
+	// 知らせを受け取った bob の画面に、alice のカーソルが波括弧で出る
+	updated, _ := bob.Update(coopMsg{})
+	view := updated.(Model).View()
+	if !strings.Contains(view, "{") || !strings.Contains(view, "Playing together: you, alice") {
+		t.Errorf("bob's view should show alice's cursor:\n%s", view)
+	}
+}
+
+func TestModel_Coop_AI(t *testing.T) {
+	models := newCoopModels(t, "alice", "bob")
+	alice, bob := models[0], models[1]
+	alice = press(alice, "j", "j", "j", "j", "l", "l", "l", "l", "enter")
+	<-bob.coop.Updates()
+
+	// alice のAIが複製した盤面を解いている間も、bob は同じゲームを操作できる（go test -race で確かめる）
+	alice.game.RLock()
+	cmd := alice.runSolver()
+	alice.game.RUnlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bob = press(bob, "j", "f", "f")
+	}()
+	var solved solverMsg
+	for range 5 {
+		solved = cmd().(solverMsg)
+		if len(solved.result.SafeCells) > 0 {
+			break
+		}
+		// 地雷に印を付けた後に解き直す
+		var updated tea.Model
+		updated, cmd = alice.Update(solved)
+		alice = updated.(Model)
+	}
+	<-done
+	if len(solved.result.SafeCells) == 0 {
+		t.Fatalf("solver result = %+v, want safe cells to reveal", solved.result)
+	}
+
+	// 結果はロックを持って共有の盤面に反映し、ほかのプレイヤーに知らせる
+	updated, _ := alice.Update(solved)
+	alice = updated.(Model)
+	pos := solved.result.SafeCells[0]
+	select {
+	case <-bob.coop.Updates():
+	default:
+	}
+	updated, _ = alice.Update(revealCellMsg{board: solved.board, positions: []game.Position{pos}})
+	alice = updated.(Model)
+	if cell := bob.game.Board.GetCell(pos); !cell.IsRevealed {
+		t.Errorf("bob should see the cell %v alice's AI revealed", pos)
+	}
+	select {
+	case <-bob.coop.Updates():
+	default:
+		t.Error("bob should be told about the AI's move")
+	}
+
+	// 新しいゲームが始まった後に届いた古い結果は捨てる
+	bob = press(bob, "r")
+	updated, _ = alice.Update(solved)
+	if alice = updated.(Model); alice.aiThinking || alice.game.Board.CountUnrevealedSafeCells() == 0 {
+		t.Fatal("a stale solver result should be dropped")
+	}
+	for _, row := range alice.game.Board.Cells {
+		for _, cell := range row {
+			if cell.IsRevealed || cell.IsMarked {
+				t.Fatal("a stale solver result should not touch the new board")
+			}
+		}
+	}
+}
+
+func TestModel_Coop_Concurrent(t *testing.T) {
+	// 別々のゴルーチンで操作と描画をしても、ゲームのロックで守られる（go test -race で確かめる）
+	models := newCoopModels(t, "alice", "bob", "carol")
+
+	var wg sync.WaitGroup
+	for i, m := range models {
+		wg.Add(1)
+		go func(m Model, i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				m = press(m, "l", "j", "f", "enter")
+				if (i+j)%3 == 0 {
+					updated, _ := m.Update(coopMsg{})
+					m = updated.(Model)
+				}
+				_ = m.View()
+			}
+		}(m, i)
+	}
+	wg.Wait()
+}
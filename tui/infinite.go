@@ -0,0 +1,372 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/i18n"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+// 無限盤面の表示範囲の既定の大きさと最小の大きさ（マス数）.
+const (
+	defaultViewWidth  = 30
+	defaultViewHeight = 16
+	minViewSize       = 5
+)
+
+// cameraMargin はカーソルを表示範囲の端から離しておくマス数。これより端に寄ると表示範囲が動く.
+const cameraMargin = 3
+
+// infiniteChrome は画面のうち盤面以外（タイトル・ヘッダー・状態・操作説明）が使う行数.
+const infiniteChrome = 6
+
+// infiniteActions は無限盤面で使える操作（ヘルプの表示順）。難易度の切り替えはない.
+var infiniteActions = []config.Action{
+	config.ActionUp,
+	config.ActionDown,
+	config.ActionLeft,
+	config.ActionRight,
+	config.ActionReveal,
+	config.ActionFlag,
+	config.ActionNewGame,
+	config.ActionTheme,
+	config.ActionAuto,
+	config.ActionHelp,
+	config.ActionQuit,
+}
+
+// infiniteSolverMsg は表示範囲を解いた結果.
+type infiniteSolverMsg struct {
+	result solver.SolverResult
+	// hint は確定できる手がないときにAIが勧めるマス.
+	hint *solver.Guess
+}
+
+// infiniteRevealMsg はAIが安全と分かったマスをまとめて開くタイミング.
+type infiniteRevealMsg struct {
+	positions []game.Position
+}
+
+// infiniteGuessMsg は自動プレイでAIが推測のマスを開くタイミング.
+type infiniteGuessMsg struct {
+	guess solver.Guess
+}
+
+// InfiniteModel は無限盤面のゲームの画面。表示範囲はカーソルを追って動き、AIは表示範囲だけを解く.
+type InfiniteModel struct {
+	game   *game.InfiniteGame
+	cursor game.Position
+	// camera は表示範囲の左上のマス.
+	camera        game.Position
+	width, height int
+	styles        styles
+	keys          keyMap
+	text          *i18n.Catalog
+	assist        config.AssistLevel
+	aiDelay       time.Duration
+	aiThinking    bool
+	autoPlay      bool
+	showHelp      bool
+	hint          *solver.Guess
+}
+
+// NewInfiniteModel は無限盤面のゲームの画面を作成する。表示範囲は原点を中央に置く.
+func NewInfiniteModel(cfg config.Config, g *game.InfiniteGame) (InfiniteModel, error) {
+	theme, err := configTheme(cfg, lipgloss.DefaultRenderer())
+	if err != nil {
+		return InfiniteModel{}, err
+	}
+	text := i18n.New(i18n.Detect(cfg.Language))
+	m := InfiniteModel{
+		game:    g,
+		width:   defaultViewWidth,
+		height:  defaultViewHeight,
+		styles:  newStyles(theme),
+		keys:    newKeyMap(cfg.KeyBindings, text),
+		text:    text,
+		assist:  cfg.AssistLevel,
+		aiDelay: time.Duration(cfg.AISpeed) * time.Millisecond,
+	}
+	m.centerCamera()
+	return m, nil
+}
+
+func (m InfiniteModel) Init() tea.Cmd {
+	return tea.ClearScreen
+}
+
+func (m InfiniteModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = max(minViewSize, (msg.Width-1)/narrowCellWidth)
+		m.height = max(minViewSize, msg.Height-infiniteChrome)
+		m.follow()
+
+	case infiniteSolverMsg:
+		return m, m.handleSolver(msg)
+
+	case infiniteRevealMsg:
+		for _, pos := range msg.positions {
+			m.game.Click(pos)
+		}
+		if m.autoPlay {
+			// 自動プレイではAIが開いたマスを追って表示範囲を動かし、新しい手掛かりを範囲に入れる
+			m.cursor = msg.positions[len(msg.positions)-1]
+			m.follow()
+		}
+		return m, m.continueAI()
+
+	case infiniteGuessMsg:
+		if !m.autoPlay {
+			m.aiThinking = false
+			return m, nil
+		}
+		m.hint = nil
+		m.cursor = msg.guess.Position
+		m.game.Click(msg.guess.Position)
+		m.follow()
+		return m, m.continueAI()
+	}
+	return m, nil
+}
+
+func (m InfiniteModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) { //nolint:gocyclo // 操作ごとの分岐
+	action, ok := m.keys.action(msg.String())
+	if !ok {
+		return m, nil
+	}
+
+	switch action {
+	case config.ActionQuit:
+		return m, tea.Quit
+	case config.ActionHelp:
+		m.showHelp = !m.showHelp
+		return m, nil
+	case config.ActionAuto:
+		m.autoPlay = !m.autoPlay
+		if m.autoPlay && !m.aiThinking && m.game.State == game.Playing {
+			m.aiThinking = true
+			return m, m.runSolver()
+		}
+		return m, nil
+	}
+
+	if m.aiThinking {
+		return m, nil
+	}
+
+	switch action {
+	case config.ActionUp:
+		m.moveCursor(-1, 0)
+	case config.ActionDown:
+		m.moveCursor(1, 0)
+	case config.ActionLeft:
+		m.moveCursor(0, -1)
+	case config.ActionRight:
+		m.moveCursor(0, 1)
+	case config.ActionReveal:
+		if m.game.State == game.Playing && !m.game.Board.GetCell(m.cursor).IsRevealed {
+			m.hint = nil
+			m.game.Click(m.cursor)
+			return m, m.continueAI()
+		}
+	case config.ActionFlag:
+		m.game.ToggleFlag(m.cursor)
+	case config.ActionTheme:
+		m.styles = m.styles.withTheme(nextTheme(m.styles.theme))
+	case config.ActionNewGame:
+		m.game = game.NewInfiniteGame(0, m.game.Board.Density)
+		m.cursor = game.Position{}
+		m.hint = nil
+		m.centerCamera()
+	}
+	return m, nil
+}
+
+// handleSolver はAIの結果を盤面に反映し、次に行うことを返す.
+func (m *InfiniteModel) handleSolver(msg infiniteSolverMsg) tea.Cmd {
+	result := msg.result
+	marked := false
+	for _, pos := range result.MineCells {
+		if !m.game.Board.GetCell(pos).IsMarked {
+			m.game.MarkMine(pos)
+			marked = true
+		}
+	}
+
+	aiReveals := m.assist == config.AssistFull || m.autoPlay
+	switch {
+	case len(result.SafeCells) > 0 && aiReveals:
+		positions := result.SafeCells
+		return tea.Tick(m.aiDelay, func(time.Time) tea.Msg {
+			return infiniteRevealMsg{positions: positions}
+		})
+	case marked && aiReveals:
+		// 印を付けたことで新しく安全と分かるマスがあるかもしれない
+		return m.runSolver()
+	case msg.hint != nil && m.autoPlay:
+		m.hint = msg.hint
+		guess := *msg.hint
+		return tea.Tick(m.aiDelay, func(time.Time) tea.Msg {
+			return infiniteGuessMsg{guess: guess}
+		})
+	default:
+		m.hint = msg.hint
+		m.aiThinking = false
+		return nil
+	}
+}
+
+// continueAI はプレイヤーかAIがマスを開いた後、まだ続けられるならAIに表示範囲を解かせる.
+func (m *InfiniteModel) continueAI() tea.Cmd {
+	if m.game.State != game.Playing || (m.assist == config.AssistOff && !m.autoPlay) {
+		m.aiThinking = false
+		return nil
+	}
+	m.aiThinking = true
+	return m.runSolver()
+}
+
+// runSolver は表示範囲を解く。表示範囲の外側1マスまで切り出すので、見えているマスの数字はすべて使える.
+// 切り出しはここで済ませ、解いている間は無限盤面に触れない.
+func (m *InfiniteModel) runSolver() tea.Cmd {
+	origin := game.Position{Row: m.camera.Row - 1, Col: m.camera.Col - 1}
+	ws := solver.NewWindowSolver(m.game.Board, origin, m.width+2, m.height+2)
+	return func() tea.Msg {
+		result, _ := ws.SolveWith(solver.RuleEnumeration)
+		msg := infiniteSolverMsg{result: result}
+		if !result.CanProgress {
+			if guess, ok := ws.Guess(); ok {
+				msg.hint = &guess
+			}
+		}
+		return msg
+	}
+}
+
+func (m *InfiniteModel) moveCursor(dr, dc int) {
+	m.cursor = game.Position{Row: m.cursor.Row + dr, Col: m.cursor.Col + dc}
+	m.follow()
+}
+
+// centerCamera はカーソルが表示範囲の中央に来るようにする.
+func (m *InfiniteModel) centerCamera() {
+	m.camera = game.Position{Row: m.cursor.Row - m.height/2, Col: m.cursor.Col - m.width/2}
+}
+
+// follow はカーソルが表示範囲の端からcameraMarginマス以内に入らないよう表示範囲を動かす.
+func (m *InfiniteModel) follow() {
+	m.camera.Row = followAxis(m.camera.Row, m.cursor.Row, m.height)
+	m.camera.Col = followAxis(m.camera.Col, m.cursor.Col, m.width)
+}
+
+// followAxis は1つの軸について、表示範囲の始まりをカーソルに合わせて動かした位置を返す.
+func followAxis(start, cursor, size int) int {
+	margin := min(cameraMargin, (size-1)/2)
+	if cursor < start+margin {
+		return cursor - margin
+	}
+	if cursor > start+size-1-margin {
+		return cursor - size + 1 + margin
+	}
+	return start
+}
+
+func (m InfiniteModel) View() string {
+	sections := []string{
+		m.styles.title.Render(m.text.T("title")),
+		m.renderHeader(),
+	}
+	if m.showHelp {
+		sections = append(sections, helpOverlay(m.styles, m.keys, m.text, infiniteActions))
+	} else {
+		sections = append(sections, m.renderBoard())
+	}
+	sections = append(sections, m.renderStatus(), m.renderHelp())
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+func (m InfiniteModel) renderHeader() string {
+	b := m.game.Board
+	return m.styles.header.Render(m.text.T("infinite.header",
+		m.game.Score, m.cursor.Row, m.cursor.Col, b.Seed, 100*b.Density, m.styles.theme.Name))
+}
+
+func (m InfiniteModel) renderBoard() string {
+	rows := make([]string, 0, m.height)
+	for row := m.camera.Row; row < m.camera.Row+m.height; row++ {
+		cells := make([]string, 0, m.width)
+		for col := m.camera.Col; col < m.camera.Col+m.width; col++ {
+			cells = append(cells, m.renderCell(game.Position{Row: row, Col: col}))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+	return m.styles.renderer.NewStyle().PaddingLeft(1).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+func (m InfiniteModel) renderCell(pos game.Position) string {
+	st := m.styles
+	cell := m.game.Board.GetCell(pos)
+	isCursor := pos == m.cursor
+
+	var style lipgloss.Style
+	var content string
+	switch {
+	case m.game.State == game.Lost && cell.IsMine:
+		// 負けたら表示範囲の地雷をすべて見せる
+		style, content = st.mine, st.theme.MineSymbol
+	case cell.IsRevealed:
+		style, content = st.openedCell(cell, isCursor)
+	default:
+		hinted := m.hint != nil && m.hint.Position == pos
+		style, content = st.coveredCell(cell, isCursor, hinted, false)
+	}
+	return style.Width(narrowCellWidth).Render(st.cursorContent(content, isCursor))
+}
+
+func (m InfiniteModel) renderStatus() string {
+	var status string
+	switch {
+	case m.game.State == game.Lost:
+		status = m.styles.gameOver.Render(m.text.T("infinite.lost", m.game.Score))
+	case m.aiThinking:
+		status = m.styles.header.Render(m.text.T("status.thinking"))
+	default:
+		status = m.styles.header.Render(m.text.T("status.your_turn"))
+	}
+	if m.hint != nil && m.game.State == game.Playing {
+		status = lipgloss.JoinVertical(lipgloss.Left, status, m.styles.header.Render(m.text.T("status.hint",
+			m.hint.Position.Row, m.hint.Position.Col, 100*m.hint.Probability)))
+	}
+	if m.autoPlay {
+		status = lipgloss.JoinHorizontal(lipgloss.Top, status, m.styles.header.Render(m.text.T("status.auto")))
+	}
+	return status
+}
+
+func (m InfiniteModel) renderHelp() string {
+	km := m.keys
+	move := strings.Join([]string{
+		km.keysLabel(config.ActionUp),
+		km.keysLabel(config.ActionDown),
+		km.keysLabel(config.ActionLeft),
+		km.keysLabel(config.ActionRight),
+	}, " ")
+	help := []string{fmt.Sprintf("[%s] %s", move, m.text.T("help.move"))}
+	for _, action := range []config.Action{
+		config.ActionReveal, config.ActionFlag, config.ActionNewGame, config.ActionAuto, config.ActionHelp, config.ActionQuit,
+	} {
+		help = append(help, fmt.Sprintf("[%s] %s", km.keysLabel(action), m.text.T("action."+string(action))))
+	}
+	return m.styles.help.Render(strings.Join(help, "  "))
+}
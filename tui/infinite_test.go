@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func newInfiniteModel(t *testing.T, assist config.AssistLevel) InfiniteModel {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Language = "en"
+	cfg.Theme = "mono"
+	cfg.AssistLevel = assist
+	cfg.AISpeed = 0
+	m, err := NewInfiniteModel(cfg, game.NewInfiniteGame(3, game.DefaultInfiniteDensity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestInfiniteModel_CameraFollowsCursor(t *testing.T) {
+	m := newInfiniteModel(t, config.AssistOff)
+	var model tea.Model = m
+	for range 40 {
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	m = model.(InfiniteModel)
+	if m.cursor != (game.Position{Col: 40}) {
+		t.Fatalf("cursor = %v, want (0,40)", m.cursor)
+	}
+	// カーソルは表示範囲の右端からcameraMarginマス内側に留まる
+	if right := m.camera.Col + m.width - 1 - cameraMargin; right != m.cursor.Col {
+		t.Errorf("camera %v keeps the cursor at column %d, want %d", m.camera, right, m.cursor.Col)
+	}
+	if !strings.Contains(m.View(), "Position: (0,40)") {
+		t.Errorf("header should show the cursor position:\n%s", m.View())
+	}
+}
+
+func TestInfiniteModel_AssistFull(t *testing.T) {
+	// AIが表示範囲で確定できる手を打ち尽くすまで回すと、スコアが増え、地雷は踏まない
+	m := newInfiniteModel(t, config.AssistFull)
+	start := m.game.Score
+	cmd := m.continueAI()
+	var model tea.Model = m
+	for i := 0; cmd != nil && i < 200; i++ {
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			msg = batch[0]()
+		}
+		model, cmd = model.Update(msg)
+	}
+	m = model.(InfiniteModel)
+	if m.game.State != game.Playing {
+		t.Fatal("the AI should never step on a mine without guessing")
+	}
+	if m.game.Score <= start {
+		t.Errorf("score = %d, want more than %d", m.game.Score, start)
+	}
+	if m.aiThinking {
+		t.Error("the AI should stop when no move is certain")
+	}
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/i18n"
+)
+
+// keyMap はキー文字列と操作の対応表.
+type keyMap struct {
+	bindings   map[config.Action][]string
+	actions    map[string]config.Action
+	spaceLabel string
+}
+
+func newKeyMap(bindings map[config.Action][]string, text *i18n.Catalog) keyMap {
+	km := keyMap{
+		bindings:   bindings,
+		actions:    map[string]config.Action{},
+		spaceLabel: text.T("key.space"),
+	}
+	for action, keys := range bindings {
+		for _, key := range keys {
+			km.actions[key] = action
+		}
+	}
+	return km
+}
+
+// action はキーに割り当てられた操作を返す.
+func (km keyMap) action(key string) (config.Action, bool) {
+	a, ok := km.actions[key]
+	return a, ok
+}
+
+// keysLabel は操作に割り当てられたキーを表示用に整形する.
+func (km keyMap) keysLabel(action config.Action) string {
+	var labels []string
+	seen := map[string]bool{}
+	for _, key := range km.bindings[action] {
+		label := km.keyLabel(key)
+		if seen[label] {
+			continue
+		}
+		seen[label] = true
+		labels = append(labels, label)
+	}
+	return strings.Join(labels, "/")
+}
+
+func (km keyMap) keyLabel(key string) string {
+	switch key {
+	case " ", "space":
+		return km.spaceLabel
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	}
+	return key
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/i18n"
+)
+
+func TestKeyMap_Action(t *testing.T) {
+	bindings := config.DefaultKeyBindings()
+	bindings[config.ActionFlag] = []string{"m"}
+	km := newKeyMap(bindings, i18n.New(i18n.English))
+
+	if action, ok := km.action("m"); !ok || action != config.ActionFlag {
+		t.Errorf("action(m) = %q, %v, want %q", action, ok, config.ActionFlag)
+	}
+	if _, ok := km.action("f"); ok {
+		t.Error("action(f) should not be bound after rebinding")
+	}
+}
+
+func TestModel_HelpReflectsBindings(t *testing.T) {
+	cfg := config.Default()
+	cfg.Language = "ja"
+	cfg.KeyBindings[config.ActionFlag] = []string{"m"}
+
+	m, err := NewModel(cfg)
+	if err != nil {
+		t.Fatalf("NewModel() error = %v", err)
+	}
+
+	if help := m.renderHelp(); !strings.Contains(help, "[m]") {
+		t.Errorf("renderHelp() = %q, want it to contain [m]", help)
+	}
+	// テーマの切り替え・自動プレイ・画像の保存もフッターに出す
+	for _, want := range []string{"[t]", "[a]", "[p]"} {
+		if help := m.renderHelp(); !strings.Contains(help, want) {
+			t.Errorf("renderHelp() = %q, want it to contain %s", help, want)
+		}
+	}
+	if overlay := m.renderHelpOverlay(); !strings.Contains(overlay, "スペース/enter") {
+		t.Errorf("renderHelpOverlay() = %q, want reveal keys", overlay)
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// PlayerStatus はロビーの他のプレイヤーに見せる自分のゲームの様子.
+type PlayerStatus struct {
+	// Difficulty は難易度のキー.
+	Difficulty string
+	State      game.GameState
+	// Progress は安全なマスのうち開いたマスの割合（%）.
+	Progress int
+	// Cleared は開いた安全なマスの数.
+	Cleared int
+	// Flags は立てた旗の数.
+	Flags      int
+	Explosions int
+	// Guesses は見えている情報からは安全と確定できないマスを開いた回数。レースのときだけ数える.
+	Guesses int
+}
+
+// Peer はロビーにいるプレイヤー.
+type Peer struct {
+	Name string
+	PlayerStatus
+	// Winner はレースの勝者かどうか.
+	Winner bool
+}
+
+// Lobby は同じサーバーで遊んでいるプレイヤーの様子を共有する（SSHのロビーなど）.
+// プレイヤーごとに別のゴルーチンから呼ばれる.
+type Lobby interface {
+	// Report は自分のゲームの様子が変わるたびに呼ばれる.
+	Report(status PlayerStatus)
+	// Peers はロビーにいるプレイヤーの一覧（自分を含む）.
+	Peers() []Peer
+}
+
+// WithLobby はロビーの様子を画面に出し、自分のゲームの様子をロビーに伝えるモデルを返す.
+func (m Model) WithLobby(l Lobby) Model {
+	m.lobby = l
+	m.reportStatus()
+	return m
+}
+
+// reportStatus は自分のゲームの様子をロビーに伝える.
+func (m Model) reportStatus() {
+	if m.lobby != nil {
+		m.lobby.Report(m.playerStatus())
+	}
+}
+
+// playerStatus は自分のゲームの様子をまとめる.
+func (m Model) playerStatus() PlayerStatus {
+	g := m.game
+	safe, opened, flags := 0, 0, 0
+	for _, row := range g.Board.Cells {
+		for _, cell := range row {
+			flags += cell.FlagCount()
+			if !cell.IsMine {
+				safe++
+				if cell.IsRevealed {
+					opened++
+				}
+			}
+		}
+	}
+	progress := 0
+	if safe > 0 {
+		progress = 100 * opened / safe
+	}
+	return PlayerStatus{
+		Difficulty: g.Difficulty.Name,
+		State:      g.State,
+		Progress:   progress,
+		Cleared:    opened,
+		Flags:      flags,
+		Explosions: g.Explosions,
+		Guesses:    m.guesses,
+	}
+}
+
+func (m Model) renderLobby() string {
+	var players []string
+	for _, p := range m.lobby.Peers() {
+		switch p.State {
+		case game.Won:
+			players = append(players, m.text.T("lobby.won", p.Name))
+		case game.Lost:
+			players = append(players, m.text.T("lobby.lost", p.Name))
+		default:
+			players = append(players, m.text.T("lobby.playing", p.Name, m.difficultyName(game.Difficulty{Name: p.Difficulty}),
+				p.Progress))
+		}
+	}
+	return m.styles.help.Render(m.text.T("lobby", strings.Join(players, "  ")))
+}
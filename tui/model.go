@@ -4,18 +4,41 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
+	"github.com/r-horie/ai-minesweeper/broadcast"
+	"github.com/r-horie/ai-minesweeper/config"
 	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/i18n"
 	"github.com/r-horie/ai-minesweeper/solver"
 )
 
 type tickMsg time.Time
 
 type solverMsg struct {
+	// board は解いた時点の盤面。リセット後の古い結果を捨てるのに使う.
+	board  *game.Board
 	result solver.SolverResult
+	// hint は確定できる手がないときにAIが勧めるマス.
+	hint *solver.Guess
+	// contradictions は数字と矛盾する旗.
+	contradictions []solver.Contradiction
+}
+
+// guessMsg は自動プレイでAIが推測のマスを開くタイミング.
+type guessMsg struct {
+	board *game.Board
+	guess solver.Guess
+}
+
+// ratingMsg は盤面の難しさの評価結果。board は評価した時点の盤面で、リセット後の古い結果を捨てるのに使う.
+type ratingMsg struct {
+	board  *game.Board
+	rating solver.Rating
 }
 
 type revealCellMsg struct {
+	board     *game.Board
 	positions []game.Position
 	index     int
 }
@@ -27,10 +50,106 @@ type Model struct {
 	aiThinking     bool
 	lastUpdate     time.Time
 	pendingReveals []game.Position
+	styles         styles
+	keys           keyMap
+	aiDelay        time.Duration
+	assist         config.AssistLevel
+	showHelp       bool
+	text           *i18n.Catalog
+	guessPolicy    solver.GuessPolicy
+	autoPlay       bool
+	hint           *solver.Guess
+	rating         *solver.Rating
+	flagPolicy     solver.FlagPolicy
+	// wrongFlags は見えている数字と矛盾する旗（盤面の順）.
+	wrongFlags []game.Position
+	// lobby は同じサーバーで遊んでいるプレイヤーの様子。nilならロビーはない.
+	lobby Lobby
+	// race は参加しているレース。nilならレースではない.
+	race Race
+	// waiting はレースで、ほかのプレイヤーが今のラウンドを終えるのを待っているかどうか.
+	waiting bool
+	// guesses はこのゲームで推測で開いた回数（レースのときだけ数える）.
+	guesses int
+	// coop は一緒に遊んでいる協力プレイ。nilなら1人で遊ぶ.
+	coop Coop
+	// partners は協力プレイのほかのプレイヤーのカーソル.
+	partners []Partner
+	// deduced は最後にAIが確定させたマス。観戦者に見せる.
+	deduced *solver.SolverResult
+	// publisher はゲームの出来事の配信先。nilなら配信しない.
+	publisher Publisher
+	// events は観戦している配信の出来事。nilなら観戦ではない.
+	events <-chan broadcast.Event
+	// broadcastEnded は観戦している配信が終わったかどうか.
+	broadcastEnded bool
+	// snapshotDir は盤面の画像を保存するディレクトリ。空なら現在のディレクトリ.
+	snapshotDir string
+	// noSnapshots が true なら盤面の画像を保存できない.
+	noSnapshots bool
+	// snapshotNote は最後に盤面の画像を保存した結果.
+	snapshotNote string
+}
+
+func NewModel(cfg config.Config) (Model, error) {
+	return NewModelWithRenderer(cfg, lipgloss.DefaultRenderer())
+}
+
+// NewModelWithRenderer は描画先の端末（SSHの接続など）を指定して、設定に従った新しいゲームのモデルを作成.
+func NewModelWithRenderer(cfg config.Config, r *lipgloss.Renderer) (Model, error) {
+	g, err := NewGame(cfg)
+	if err != nil {
+		return Model{}, err
+	}
+	return newModel(cfg, g, r)
+}
+
+// NewGame は設定の難易度とルールで新しいゲームを作成.
+func NewGame(cfg config.Config) (*game.Game, error) {
+	difficulty, err := game.ParseDifficulty(cfg.Difficulty)
+	if err != nil {
+		return nil, err
+	}
+	topology, err := game.ParseTopology(cfg.Topology)
+	if err != nil {
+		return nil, err
+	}
+	neighborhood, err := game.ParseNeighborhood(cfg.Neighborhood)
+	if err != nil {
+		return nil, err
+	}
+	options := game.Options{
+		QuestionMarks: cfg.QuestionMarks,
+		Topology:      topology,
+		Neighborhood:  neighborhood,
+		MaxCellMines:  cfg.MaxCellMines,
+		Lives:         cfg.Lives,
+	}
+	return game.NewGameWithOptions(difficulty, options), nil
 }
 
-func NewModel() Model {
-	g := game.NewGame(game.Beginner)
+// NewModelWithGame は作成済みのゲーム（カスタム難易度やシード指定）でモデルを作成.
+func NewModelWithGame(cfg config.Config, g *game.Game) (Model, error) {
+	return newModel(cfg, g, lipgloss.DefaultRenderer())
+}
+
+func newModel(cfg config.Config, g *game.Game, r *lipgloss.Renderer) (Model, error) {
+	theme, err := configTheme(cfg, r)
+	if err != nil {
+		return Model{}, err
+	}
+
+	policy, err := solver.ParseGuessPolicy(cfg.GuessPolicy)
+	if err != nil {
+		return Model{}, err
+	}
+	flagPolicy, err := solver.ParseFlagPolicy(cfg.FlagPolicy)
+	if err != nil {
+		return Model{}, err
+	}
+
+	text := i18n.New(i18n.Detect(cfg.Language))
+
 	return Model{
 		game:           g,
 		solver:         nil,
@@ -38,13 +157,31 @@ func NewModel() Model {
 		aiThinking:     false,
 		lastUpdate:     time.Now(),
 		pendingReveals: []game.Position{},
+		styles:         newRendererStyles(r, theme),
+		keys:           newKeyMap(cfg.KeyBindings, text),
+		aiDelay:        time.Duration(cfg.AISpeed) * time.Millisecond,
+		assist:         cfg.AssistLevel,
+		text:           text,
+		guessPolicy:    policy,
+		flagPolicy:     flagPolicy,
+		snapshotDir:    cfg.SnapshotDir,
+	}, nil
+}
+
+// configTheme は設定のテーマを読み込む。未指定なら描画先の端末に合わせて選ぶ.
+func configTheme(cfg config.Config, r *lipgloss.Renderer) (Theme, error) {
+	if cfg.Theme == "" {
+		return detectTheme(r), nil
 	}
+	return LoadTheme(cfg.Theme)
 }
 
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		tea.ClearScreen,
 		tickCmd(),
+		m.waitCoop(),
+		m.waitEvent(),
 	)
 }
 
@@ -54,16 +191,70 @@ func tickCmd() tea.Cmd {
 	})
 }
 
+// runSolver はゲームごとに1つのソルバーで確定できる手を求める.
+// ソルバーは盤面の変化をnotifyで受け取り、変化した周囲だけを調べ直す.
+// 協力プレイではほかのプレイヤーも盤面を変えるので、ロックを持っている今の盤面を複製して解き、
+// 結果はゲームのロックを持つ Update で盤面に反映する.
 func (m *Model) runSolver() tea.Cmd {
+	policy := m.guessPolicy
+	board := m.game.Board
+	var s *solver.Solver
+	if m.coop != nil {
+		s = solver.NewSolverWithOptions(board.Clone(), solver.Options{FlagPolicy: m.flagPolicy})
+	} else {
+		if m.solver == nil {
+			m.solver = solver.NewSolverWithOptions(board, solver.Options{FlagPolicy: m.flagPolicy})
+		}
+		s = m.solver
+	}
 	return func() tea.Msg {
-		s := solver.NewSolver(m.game.Board)
 		result := s.Solve()
-		return solverMsg{result: result}
+		msg := solverMsg{board: board, result: result, contradictions: s.Contradictions()}
+		if !result.CanProgress {
+			if guess, ok := s.Guess(policy); ok {
+				msg.hint = &guess
+			}
+		}
+		return msg
 	}
 }
 
-func revealNextCell(positions []game.Position, index int) tea.Cmd {
-	return tea.Tick(time.Millisecond*200, func(t time.Time) tea.Msg {
-		return revealCellMsg{positions: positions, index: index}
+// notify は開いたマスや旗を立て外ししたマスをソルバーに伝える.
+func (m *Model) notify(changed ...game.Position) {
+	if m.solver != nil {
+		m.solver.Update(changed)
+	}
+}
+
+// checkFlags は数字と矛盾する旗を調べ直す。ソルバーがあれば、変化がない限りその結果を使い回す.
+func (m *Model) checkFlags() {
+	if m.solver != nil {
+		m.wrongFlags = solver.ContradictingFlags(m.solver.Contradictions())
+		return
+	}
+	m.wrongFlags = solver.ContradictingFlags(solver.Validate(m.game.Board))
+}
+
+// rateBoard は最初のクリックの後に盤面の難しさを評価する.
+// 評価中も盤面は変わるため、呼び出した時点の盤面を複製して評価する.
+func (m *Model) rateBoard(first game.Position) tea.Cmd {
+	board := m.game.Board
+	snapshot := board.Clone()
+	return func() tea.Msg {
+		return ratingMsg{board: board, rating: solver.Rate(snapshot, first)}
+	}
+}
+
+func (m *Model) guessAfterDelay(guess solver.Guess) tea.Cmd {
+	board := m.game.Board
+	return tea.Tick(m.aiDelay, func(t time.Time) tea.Msg {
+		return guessMsg{board: board, guess: guess}
+	})
+}
+
+func (m *Model) revealNextCell(positions []game.Position, index int) tea.Cmd {
+	board := m.game.Board
+	return tea.Tick(m.aiDelay, func(t time.Time) tea.Msg {
+		return revealCellMsg{board: board, positions: positions, index: index}
 	})
 }
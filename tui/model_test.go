@@ -0,0 +1,204 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestModel_WrongFlagWarning(t *testing.T) {
+	board, err := game.ParseBoard("?1*1.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := game.NewGame(game.Beginner)
+	g.Board = board
+	g.FirstClick = false
+
+	cfg := config.Default()
+	cfg.Language = "en"
+	cfg.Theme = "mono"
+	m, err := NewModelWithGame(cfg, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 右の1から(0,2)が地雷と分かるので、(0,0)の旗は間違い
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	view := updated.(Model).View()
+
+	if !strings.Contains(view, "contradict the numbers: (0,0)") {
+		t.Errorf("view should warn about the wrong flag:\n%s", view)
+	}
+	// カーソルは(0,0)にあるので括弧で囲まれる
+	if want := "[" + MonoTheme.WrongFlagSymbol + "]"; !strings.Contains(view, want) {
+		t.Errorf("view should mark the wrong flag with %q:\n%s", want, view)
+	}
+}
+
+func TestModel_RenderCell_MarkIsNotFlag(t *testing.T) {
+	// (0,1)はAIの印、(0,2)はプレイヤーの旗
+	board, err := game.ParseBoard("?MF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := game.NewGame(game.Beginner)
+	g.Board = board
+	g.FirstClick = false
+
+	cfg := config.Default()
+	cfg.Theme = "mono"
+	m, err := NewModelWithGame(cfg, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mark := m.renderCell(game.Position{Row: 0, Col: 1})
+	flag := m.renderCell(game.Position{Row: 0, Col: 2})
+	if !strings.Contains(mark, MonoTheme.MarkSymbol) {
+		t.Errorf("marked cell = %q, want %q", mark, MonoTheme.MarkSymbol)
+	}
+	if !strings.Contains(flag, MonoTheme.FlagSymbol) {
+		t.Errorf("flagged cell = %q, want %q", flag, MonoTheme.FlagSymbol)
+	}
+}
+
+func TestModel_Topology(t *testing.T) {
+	newModel := func(t *testing.T, text string) Model {
+		t.Helper()
+		board, err := game.ParseBoard(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := game.NewGame(game.Beginner)
+		g.Board = board
+		g.FirstClick = false
+
+		cfg := config.Default()
+		cfg.Theme = "mono"
+		m, err := NewModelWithGame(cfg, g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	t.Run("torus cursor wraps", func(t *testing.T) {
+		m := newModel(t, "# topology: torus\n???\n???\n")
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		if got := updated.(Model).cursor; got != (game.Position{Row: 0, Col: 2}) {
+			t.Errorf("cursor = %v, want {0 2}", got)
+		}
+	})
+
+	t.Run("square cursor stops at the edge", func(t *testing.T) {
+		m := newModel(t, "???\n???\n")
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		if got := updated.(Model).cursor; got != (game.Position{}) {
+			t.Errorf("cursor = %v, want {0 0}", got)
+		}
+	})
+
+	t.Run("triangle cells show their direction", func(t *testing.T) {
+		m := newModel(t, "# topology: triangle\n???\n")
+		if got := m.renderCell(game.Position{Row: 0, Col: 2}); !strings.Contains(got, "/#\\") {
+			t.Errorf("up triangle = %q", got)
+		}
+		if got := m.renderCell(game.Position{Row: 0, Col: 1}); !strings.Contains(got, "\\#/") {
+			t.Errorf("down triangle = %q", got)
+		}
+	})
+
+	t.Run("hex shifts odd rows", func(t *testing.T) {
+		m := newModel(t, "# topology: hex\n??\n??\n")
+		lines := strings.Split(m.renderBoard(), "\n")
+		// 盤面の余白1とマスの中央寄せの1に、ずらした幅が加わる
+		if len(lines) != 2 || !strings.HasPrefix(lines[1], strings.Repeat(" ", 2+hexIndent)+"#") {
+			t.Errorf("board = %q, want the second row shifted", lines)
+		}
+	})
+}
+
+func TestModel_Lives(t *testing.T) {
+	board, err := game.ParseBoard("*1?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := game.NewGameWithOptions(game.Beginner, game.Options{Lives: 3})
+	g.Board = board
+	g.FirstClick = false
+
+	cfg := config.Default()
+	cfg.Language = "en"
+	cfg.Theme = "mono"
+	cfg.AssistLevel = config.AssistOff
+	m, err := NewModelWithGame(cfg, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// カーソルのある(0,0)の地雷を踏んでも、ライフが減るだけで続けられる
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	view := updated.(Model).View()
+	if !strings.Contains(view, "Lives: 2") {
+		t.Errorf("view should show the remaining lives:\n%s", view)
+	}
+	if strings.Contains(view, "Game over") {
+		t.Errorf("game should go on after the first hit:\n%s", view)
+	}
+}
+
+func TestNewModelWithGame_KeepsGameOptions(t *testing.T) {
+	// 渡したゲームの設定は、モデルの設定で上書きしない
+	cfg := config.Default()
+	cfg.QuestionMarks = false
+	g := game.NewGameWithOptions(game.Beginner, game.Options{QuestionMarks: true})
+	m, err := NewModelWithGame(cfg, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.game.Options.QuestionMarks {
+		t.Error("QuestionMarks of the given game should be kept")
+	}
+}
+
+func TestModel_LivesOnAIReveal(t *testing.T) {
+	// (0,0)の旗は間違いで、信じたAIは(0,2)の地雷を安全だと判断する
+	board, err := game.ParseBoard("F1*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := game.NewGameWithOptions(game.Beginner, game.Options{Lives: 3})
+	g.Board = board
+	g.FirstClick = false
+
+	cfg := config.Default()
+	cfg.AssistLevel = config.AssistFull
+	cfg.FlagPolicy = "trust"
+	m, err := NewModelWithGame(cfg, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solved, ok := m.runSolver()().(solverMsg)
+	if !ok || len(solved.result.SafeCells) != 1 {
+		t.Fatalf("solver result = %+v, want the mine reported as safe", solved)
+	}
+	updated, cmd := m.Update(solved)
+	if cmd == nil {
+		t.Fatal("the AI should reveal the cell it deduced as safe")
+	}
+	updated, _ = updated.Update(revealCellMsg{board: solved.board, positions: solved.result.SafeCells})
+
+	got := updated.(Model).game
+	if got.Lives != 2 || got.Explosions != 1 {
+		t.Errorf("lives = %d, explosions = %d, want 2 and 1", got.Lives, got.Explosions)
+	}
+	if got.State != game.Playing {
+		t.Errorf("state = %v, want the game to go on with lives left", got.State)
+	}
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+// Race は全員が同じシードの盤面で競うレース。Peers は自分のラウンドの順位の順に並ぶ.
+type Race interface {
+	Lobby
+	// NextRound は次に遊ぶラウンドのシードを返す。ほかのプレイヤーがまだ今のラウンドを遊んでいれば false.
+	NextRound() (seed int64, ok bool)
+}
+
+// WithRace はレースに参加するモデルを返す。公平になるよう、AIの支援と自動プレイ、難易度の変更は使えない.
+func (m Model) WithRace(r Race) Model {
+	m.lobby = r
+	m.race = r
+	m.assist = config.AssistOff
+	m.autoPlay = false
+	m.startRound()
+	m.reportStatus()
+	return m
+}
+
+// startRound はレースの次のラウンドを始める.
+// 盤面は最初のクリックの位置でも変わるので、全員の盤面が同じになるよう中央のマスを開いておく.
+func (m *Model) startRound() {
+	seed, ok := m.race.NextRound()
+	m.waiting = !ok
+	if !ok {
+		return
+	}
+	m.game.Options.Seed = seed
+	m.resetGame()
+	m.guesses = 0
+	m.cursor = game.Position{Row: m.game.Board.Height / 2, Col: m.game.Board.Width / 2}
+	m.game.Click(m.cursor)
+}
+
+// raceLocked はレース中に使えない操作かどうか.
+func raceLocked(action config.Action) bool {
+	switch action {
+	case config.ActionAuto, config.ActionBeginner, config.ActionIntermediate, config.ActionExpert:
+		return true
+	}
+	return false
+}
+
+// renderRace はレースのスコアボードを描画する.
+func (m Model) renderRace() string {
+	lines := []string{m.text.T("race.title")}
+	for i, p := range m.race.Peers() {
+		line := m.text.T("race.row", i+1, p.Name, p.Cleared, p.Progress, p.Flags, p.Explosions, p.Guesses)
+		switch {
+		case p.Winner:
+			line += "  " + m.text.T("race.winner")
+		case p.State == game.Won:
+			line += "  " + m.text.T("race.cleared")
+		case p.State == game.Lost:
+			line += "  " + m.text.T("race.out")
+		}
+		lines = append(lines, line)
+	}
+	if m.waiting {
+		lines = append(lines, m.text.T("race.waiting", m.keys.keysLabel(config.ActionNewGame)))
+	}
+	return m.styles.help.Render(strings.Join(lines, "\n"))
+}
+
+// countGuess はレースでプレイヤーがマスを開く前に、それが推測かどうかを数える.
+func (m *Model) countGuess(pos game.Position) {
+	if m.race != nil && !m.game.FirstClick && solver.IsGuess(m.game.Board, pos) {
+		m.guesses++
+	}
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+// fakeRace は1人だけのレース。NextRound は ok が true の間だけ seed を配る.
+type fakeRace struct {
+	seed   int64
+	ok     bool
+	status PlayerStatus
+}
+
+func (r *fakeRace) Report(status PlayerStatus) { r.status = status }
+
+func (r *fakeRace) Peers() []Peer {
+	return []Peer{{Name: "alice", PlayerStatus: r.status}}
+}
+
+func (r *fakeRace) NextRound() (int64, bool) { return r.seed, r.ok }
+
+func newRaceModel(t *testing.T, race Race) Model {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Language = "en"
+	cfg.Theme = "mono"
+	cfg.Difficulty = "intermediate"
+	m, err := NewModel(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.WithRace(race)
+}
+
+func TestModel_Race_SameBoard(t *testing.T) {
+	a := newRaceModel(t, &fakeRace{seed: 7, ok: true})
+	b := newRaceModel(t, &fakeRace{seed: 7, ok: true})
+
+	// 同じシードなら、最初から中央が開いた同じ盤面になる
+	if a.game.FirstClick || a.game.Board.Notation() != b.game.Board.Notation() {
+		t.Fatalf("boards differ:\n%s\n%s", a.game.Board.Notation(), b.game.Board.Notation())
+	}
+	if want := (game.Position{Row: 8, Col: 8}); a.cursor != want || !a.game.Board.GetCell(want).IsRevealed {
+		t.Errorf("cursor = %v, want the opened center %v", a.cursor, want)
+	}
+	if a.assist != config.AssistOff {
+		t.Errorf("assist = %q, want off during a race", a.assist)
+	}
+}
+
+func TestModel_Race_LockedActions(t *testing.T) {
+	m := newRaceModel(t, &fakeRace{seed: 7, ok: true})
+
+	// 難易度の変更と自動プレイは使えない
+	for _, key := range []string{"3", "a"} {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		got := updated.(Model)
+		if got.game.Difficulty != game.Intermediate || got.autoPlay {
+			t.Errorf("key %q changed the race game: difficulty %s, auto %v", key, got.game.Difficulty.Name, got.autoPlay)
+		}
+	}
+}
+
+func TestModel_Race_CountsGuesses(t *testing.T) {
+	race := &fakeRace{seed: 7, ok: true}
+	m := newRaceModel(t, race)
+
+	// 見えている数字からは安全と言えない、地雷でないマスを開く
+	var target *game.Position
+	for row := 0; row < m.game.Board.Height && target == nil; row++ {
+		for col := 0; col < m.game.Board.Width; col++ {
+			pos := game.Position{Row: row, Col: col}
+			cell := m.game.Board.GetCell(pos)
+			if !cell.IsRevealed && !cell.IsMine && solver.IsGuess(m.game.Board, pos) {
+				target = &pos
+				break
+			}
+		}
+	}
+	if target == nil {
+		t.Fatal("no guess left on the board")
+	}
+	m.cursor = *target
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if race.status.Guesses != 1 || race.status.Cleared == 0 {
+		t.Errorf("reported status = %+v, want 1 guess", race.status)
+	}
+	if view := updated.(Model).View(); !strings.Contains(view, "1. alice") || !strings.Contains(view, "guesses: 1") {
+		t.Errorf("view should show the scoreboard:\n%s", view)
+	}
+}
+
+func TestModel_Race_Waiting(t *testing.T) {
+	race := &fakeRace{seed: 7, ok: true}
+	m := newRaceModel(t, race)
+	board := m.game.Board
+
+	// ほかのプレイヤーが遊んでいる間は、新しいゲームを押しても盤面は変わらない
+	race.ok = false
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	got := updated.(Model)
+	if got.game.Board != board {
+		t.Error("the board was replaced while the round is still running")
+	}
+	if view := got.View(); !strings.Contains(view, "Waiting for the others") {
+		t.Errorf("view should say it is waiting:\n%s", view)
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/render"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+// snapshotMsg は盤面の画像を保存し終えたこと。err が nil でなければ保存できなかった.
+type snapshotMsg struct {
+	paths []string
+	err   error
+}
+
+// WithoutSnapshots は盤面の画像を保存できないモデルを返す.
+// SSHで接続したプレイヤーにサーバーのファイルを書かせないために使う.
+func (m Model) WithoutSnapshots() Model {
+	m.noSnapshots = true
+	return m
+}
+
+// snapshot は今の盤面をSVGとPNGの画像にして保存する.
+// カーソルとAIの推論（確定させたマス・推測の提案、支援がオンなら地雷確率）も重ねて描く.
+// キーを押した時点の盤面を複製し、地雷確率の計算と画像の描画・書き込みは入力を止めないよう後で行う.
+func (m Model) snapshot() tea.Cmd {
+	g := m.game
+	board := g.Board.Clone()
+	cursor := m.cursor
+	opts := render.Options{Cursor: &cursor}
+	if m.deduced != nil {
+		opts.Safe = hiddenCells(board, m.deduced.SafeCells)
+		opts.Mines = hiddenCells(board, m.deduced.MineCells)
+	}
+	if m.hint != nil {
+		guess := m.hint.Position
+		opts.Guess = &guess
+	}
+	probabilities := m.assist != config.AssistOff && g.State == game.Playing && !g.FirstClick
+	flagPolicy := m.flagPolicy
+	base := filepath.Join(m.snapshotDir, snapshotName(g, time.Now()))
+	dir := m.snapshotDir
+
+	return func() tea.Msg {
+		if probabilities {
+			opts.Probabilities = solver.NewSolverWithOptions(board, solver.Options{FlagPolicy: flagPolicy}).Probabilities()
+		}
+		if dir != "" {
+			if err := os.MkdirAll(dir, 0o750); err != nil {
+				return snapshotMsg{err: err}
+			}
+		}
+		var paths []string
+		for _, format := range render.Formats {
+			var buf bytes.Buffer
+			if err := render.Write(&buf, format, board, opts); err != nil {
+				return snapshotMsg{err: err}
+			}
+			path := base + "." + string(format)
+			if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil { //nolint:gosec // 不具合の報告などで共有する画像
+				return snapshotMsg{err: err}
+			}
+			paths = append(paths, path)
+		}
+		return snapshotMsg{paths: paths}
+	}
+}
+
+// snapshotName は難易度とシードと時刻から、拡張子を除いた画像のファイル名を作る.
+func snapshotName(g *game.Game, now time.Time) string {
+	return fmt.Sprintf("minesweeper-%s-%d-%s", g.Difficulty.Name, g.Seed, now.Format("20060102-150405"))
+}
+
+// hiddenCells は未開放のマスだけを返す。AIが確定させたあと開いたマスには枠を描かない.
+func hiddenCells(board *game.Board, positions []game.Position) []game.Position {
+	var hidden []game.Position
+	for _, pos := range positions {
+		if cell := board.GetCell(pos); cell != nil && !cell.IsRevealed {
+			hidden = append(hidden, pos)
+		}
+	}
+	return hidden
+}
+
+// handleSnapshot は画像を保存した結果をステータスに表示する.
+func (m Model) handleSnapshot(msg snapshotMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.snapshotNote = m.text.T("status.snapshot_error", msg.err)
+	} else {
+		m.snapshotNote = m.text.T("status.snapshot", strings.Join(msg.paths, ", "))
+	}
+	return m, nil
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+func TestModel_Snapshot(t *testing.T) {
+	cfg := config.Default()
+	cfg.Language = "en"
+	cfg.Theme = "mono"
+	cfg.SnapshotDir = t.TempDir()
+	m, err := NewModelWithGame(cfg, game.NewGameWithOptions(game.Beginner, game.Options{Seed: 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = press(m, "enter")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if cmd == nil {
+		t.Fatal("the snapshot key should save the board image")
+	}
+	msg, ok := cmd().(snapshotMsg)
+	if !ok || msg.err != nil || len(msg.paths) != 2 {
+		t.Fatalf("snapshot = %+v, want an SVG and a PNG", msg)
+	}
+	for _, path := range msg.paths {
+		if !strings.Contains(path, "minesweeper-beginner-5-") {
+			t.Errorf("path %q should name the difficulty and the seed", path)
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("image %q was not written: %v", path, err)
+		}
+	}
+
+	updated, _ = updated.Update(msg)
+	if view := updated.(Model).View(); !strings.Contains(view, "Saved the board image") {
+		t.Errorf("view should tell where the image was saved:\n%s", view)
+	}
+}
+
+func TestModel_Snapshot_BoardAtKeyPress(t *testing.T) {
+	// 画像は後で描くが、キーを押した時点の盤面を描く
+	cfg := config.Default()
+	cfg.SnapshotDir = t.TempDir()
+	m, err := NewModelWithGame(cfg, game.NewGameWithOptions(game.Beginner, game.Options{Seed: 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = press(m, "enter")
+	var want strings.Builder
+	if err := xml.EscapeText(&want, []byte(m.game.Board.VisibleNotation())); err != nil {
+		t.Fatal(err)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m.game.ToggleFlag(game.Position{Row: 0, Col: 0})
+	m.game.ToggleFlag(game.Position{Row: 8, Col: 8})
+
+	msg, ok := cmd().(snapshotMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("snapshot = %+v", msg)
+	}
+	svg, err := os.ReadFile(msg.paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(svg), want.String()) {
+		t.Errorf("SVG should describe the board when the key was pressed: %s", want.String())
+	}
+}
+
+func TestModel_WithoutSnapshots(t *testing.T) {
+	cfg := config.Default()
+	cfg.SnapshotDir = t.TempDir()
+	m, err := NewModel(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = m.WithoutSnapshots()
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")}); cmd != nil {
+		t.Error("the snapshot key should do nothing when snapshots are disabled")
+	}
+	if entries, _ := os.ReadDir(cfg.SnapshotDir); len(entries) != 0 {
+		t.Errorf("no image should be written, got %d files", len(entries))
+	}
+}
@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/r-horie/ai-minesweeper/broadcast"
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+// Publisher は観戦者にゲームの出来事を配信する。プレイヤーの操作を待たせないよう、すぐに戻る.
+type Publisher interface {
+	Publish(ev broadcast.Event)
+}
+
+// spectateMsg は配信から届いた出来事。ok が false なら配信が終わった.
+type spectateMsg struct {
+	event broadcast.Event
+	ok    bool
+}
+
+// WithBroadcast はゲームの出来事を観戦者に配信するモデルを返す.
+func (m Model) WithBroadcast(p Publisher) Model {
+	m.publisher = p
+	m.publish(broadcast.KindPlayer)
+	return m
+}
+
+// publish は今の画面の状態を出来事として配信する.
+func (m Model) publish(kind broadcast.Kind) {
+	if m.publisher == nil {
+		return
+	}
+	g := m.game
+	ev := broadcast.Event{
+		Kind:        kind,
+		Board:       g.Board.VisibleNotation(),
+		Difficulty:  g.Difficulty,
+		State:       g.State,
+		Cursor:      m.cursor,
+		StartTime:   g.StartTime,
+		ElapsedTime: g.ElapsedTime,
+		Lives:       g.Lives,
+		Thinking:    m.aiThinking,
+		Auto:        m.autoPlay,
+	}
+	if g.Options.Lives > 1 {
+		ev.MaxLives = g.Options.Lives
+	}
+	if m.hint != nil {
+		ev.Hint = &broadcast.Hint{Position: m.hint.Position, Probability: m.hint.Probability}
+	}
+	if m.deduced != nil {
+		ev.Safe = m.deduced.SafeCells
+		ev.Mines = m.deduced.MineCells
+	}
+	m.publisher.Publish(ev)
+}
+
+// eventKind はメッセージがプレイヤーの操作かAIの動きかを返す.
+func eventKind(msg tea.Msg) broadcast.Kind {
+	if _, key := msg.(tea.KeyMsg); key {
+		return broadcast.KindPlayer
+	}
+	return broadcast.KindAI
+}
+
+// NewSpectatorModel は配信されたゲームを見るだけのモデルを作成する。events が閉じると配信の終わりを表示する.
+func NewSpectatorModel(cfg config.Config, events <-chan broadcast.Event) (Model, error) {
+	m, err := newModel(cfg, game.NewGame(game.Beginner), lipgloss.DefaultRenderer())
+	if err != nil {
+		return Model{}, err
+	}
+	m.assist = config.AssistOff
+	m.events = events
+	return m, nil
+}
+
+// waitEvent は配信から次の出来事が届くのを待つ.
+func (m Model) waitEvent() tea.Cmd {
+	if m.events == nil {
+		return nil
+	}
+	events := m.events
+	return func() tea.Msg {
+		ev, ok := <-events
+		return spectateMsg{event: ev, ok: ok}
+	}
+}
+
+// handleSpectate は配信された出来事を画面に反映する。読めない盤面の出来事は飛ばす.
+func (m Model) handleSpectate(msg spectateMsg) (tea.Model, tea.Cmd) {
+	if !msg.ok {
+		m.broadcastEnded = true
+		m.aiThinking = false
+		return m, nil
+	}
+	ev := msg.event
+	board, err := game.ParseBoard(ev.Board)
+	if err != nil {
+		return m, m.waitEvent()
+	}
+	// 見えている盤面から分かるのは見つかった地雷だけなので、地雷の総数は難易度から取る
+	board.Mines = ev.Difficulty.Mines
+
+	g := m.game
+	g.Board = board
+	g.Difficulty = ev.Difficulty
+	g.State = ev.State
+	g.FirstClick = ev.StartTime == 0
+	g.StartTime = ev.StartTime
+	g.ElapsedTime = ev.ElapsedTime
+	g.Lives = ev.Lives
+	g.Options.Lives = ev.MaxLives
+
+	m.cursor = ev.Cursor
+	m.aiThinking = ev.Thinking
+	m.autoPlay = ev.Auto
+	m.hint = nil
+	if ev.Hint != nil {
+		m.hint = &solver.Guess{Position: ev.Hint.Position, Probability: ev.Hint.Probability}
+	}
+	m.deduced = &solver.SolverResult{SafeCells: ev.Safe, MineCells: ev.Mines}
+	m.checkFlags()
+	return m, m.waitEvent()
+}
+
+// spectatorAction は観戦中にも使える操作かどうか.
+func spectatorAction(action config.Action) bool {
+	switch action {
+	case config.ActionQuit, config.ActionHelp, config.ActionTheme, config.ActionSnapshot:
+		return true
+	}
+	return false
+}
+
+// renderSpectator は観戦中であることと、AIが確定させたマスを描画する.
+func (m Model) renderSpectator() string {
+	lines := []string{m.text.T("spectate.watching")}
+	if m.broadcastEnded {
+		lines[0] = m.text.T("spectate.ended")
+	}
+	if d := m.deduced; d != nil && (len(d.SafeCells) > 0 || len(d.MineCells) > 0) {
+		lines = append(lines, m.text.T("spectate.deduced", formatCells(d.SafeCells), formatCells(d.MineCells)))
+	}
+	return m.styles.header.Render(strings.Join(lines, "\n"))
+}
+
+// renderSpectatorHelp は観戦中に使えるキーだけの操作説明.
+func (m Model) renderSpectatorHelp() string {
+	var help []string
+	for _, action := range []config.Action{config.ActionTheme, config.ActionHelp, config.ActionQuit} {
+		help = append(help, fmt.Sprintf("[%s] %s", m.keys.keysLabel(action), m.actionDescription(action)))
+	}
+	return m.styles.help.Render(strings.Join(help, "  "))
+}
+
+// formatCells はマスの一覧を "(行,列)" で並べる。空なら "-".
+func formatCells(positions []game.Position) string {
+	if len(positions) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(positions))
+	for i, pos := range positions {
+		parts[i] = fmt.Sprintf("(%d,%d)", pos.Row, pos.Col)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/r-horie/ai-minesweeper/broadcast"
+	"github.com/r-horie/ai-minesweeper/config"
+	"github.com/r-horie/ai-minesweeper/game"
+)
+
+// recorder は配信された出来事を記録する.
+type recorder struct {
+	events []broadcast.Event
+}
+
+func (r *recorder) Publish(ev broadcast.Event) { r.events = append(r.events, ev) }
+
+func TestModel_Spectate(t *testing.T) {
+	cfg := config.Default()
+	cfg.Language = "en"
+	cfg.Theme = "mono"
+	cfg.AssistLevel = config.AssistOff
+	player, err := NewModelWithGame(cfg, game.NewGameWithOptions(game.Beginner, game.Options{Seed: 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	player = player.WithBroadcast(rec)
+	player = press(player, "l", "j", "enter")
+
+	last := rec.events[len(rec.events)-1]
+	if last.Kind != broadcast.KindPlayer || last.Cursor != (game.Position{Row: 1, Col: 1}) || last.StartTime == 0 {
+		t.Fatalf("last event = %+v, want the reveal at (1,1)", last)
+	}
+
+	events := make(chan broadcast.Event, len(rec.events))
+	for _, ev := range rec.events {
+		events <- ev
+	}
+	close(events)
+	spectator, err := NewSpectatorModel(cfg, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range rec.events {
+		spectator = receiveEvent(t, spectator)
+	}
+
+	// 観戦者の盤面とカーソルはプレイヤーと同じ
+	if got, want := spectator.renderBoard(), player.renderBoard(); got != want {
+		t.Errorf("spectator board:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := spectator.game.GetRemainingMines(), player.game.GetRemainingMines(); got != want {
+		t.Errorf("remaining mines = %d, want %d", got, want)
+	}
+
+	// 観戦者はマスを開けない
+	before := spectator.game.Board.VisibleNotation()
+	spectator = press(spectator, "h", "enter")
+	if spectator.game.Board.VisibleNotation() != before || spectator.cursor != player.cursor {
+		t.Error("spectator should not be able to play")
+	}
+
+	// 配信が終わると、そのことを表示する
+	spectator = receiveEvent(t, spectator)
+	if view := spectator.View(); !strings.Contains(view, "The broadcast has ended") {
+		t.Errorf("view should say the broadcast ended:\n%s", view)
+	}
+}
+
+// receiveEvent は観戦者のモデルに次の出来事を1つ渡す.
+func receiveEvent(t *testing.T, m Model) Model {
+	t.Helper()
+	msg := m.waitEvent()()
+	updated, _ := m.Update(msg)
+	return updated.(Model)
+}
@@ -4,70 +4,163 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	titleStyle = lipgloss.NewStyle().
+type styles struct {
+	theme Theme
+	// renderer は描画先の端末。SSHの接続ごとに色数や背景色が違う.
+	renderer *lipgloss.Renderer
+
+	title             lipgloss.Style
+	header            lipgloss.Style
+	cell              lipgloss.Style
+	unrevealed        lipgloss.Style
+	revealed          lipgloss.Style
+	cursor            lipgloss.Style
+	revealedCursorBg  lipgloss.TerminalColor
+	mine              lipgloss.Style
+	flag              lipgloss.Style
+	flagCursor        lipgloss.Style
+	mark              lipgloss.Style
+	markCursor        lipgloss.Style
+	hint              lipgloss.Style
+	wrongFlag         lipgloss.Style
+	warning           lipgloss.Style
+	help              lipgloss.Style
+	gameOver          lipgloss.Style
+	gameWon           lipgloss.Style
+	numberColors      map[int]lipgloss.TerminalColor
+	defaultNumberFore lipgloss.TerminalColor
+}
+
+func newStyles(theme Theme) styles {
+	return newRendererStyles(lipgloss.DefaultRenderer(), theme)
+}
+
+// newRendererStyles は描画先の端末に合わせたスタイルを作る.
+func newRendererStyles(r *lipgloss.Renderer, theme Theme) styles {
+	cellStyle := r.NewStyle().
+		Width(narrowCellWidth).
+		Height(1).
+		Align(lipgloss.Center)
+
+	s := styles{
+		theme:    theme,
+		renderer: r,
+
+		title: r.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("226")).
-			PaddingLeft(1)
+			Foreground(color(theme.Title)).
+			PaddingLeft(1),
+
+		header: r.NewStyle().
+			Foreground(color(theme.Header)).
+			PaddingLeft(1),
 
-	headerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("250")).
-			PaddingLeft(1)
+		cell: cellStyle,
 
-	cellStyle = lipgloss.NewStyle().
-			Width(3).
-			Height(1).
-			Align(lipgloss.Center)
+		unrevealed: cellStyle.
+			Background(color(theme.UnrevealedBg)).
+			Foreground(color(theme.UnrevealedFg)),
 
-	unrevealedStyle = cellStyle.
-			Background(lipgloss.Color("238")).
-			Foreground(lipgloss.Color("250"))
+		revealed: cellStyle.
+			Background(color(theme.RevealedBg)),
 
-	revealedStyle = cellStyle.
-			Background(lipgloss.Color("235"))
+		cursor: cellStyle.
+			Background(color(theme.CursorBg)).
+			Foreground(color(theme.CursorFg)),
 
-	cursorStyle = cellStyle.
-			Background(lipgloss.Color("33")).
-			Foreground(lipgloss.Color("231"))
+		revealedCursorBg: color(theme.RevealedCursorBg),
 
-	mineStyle = cellStyle.
-			Background(lipgloss.Color("196")).
-			Foreground(lipgloss.Color("231"))
+		mine: cellStyle.
+			Background(color(theme.MineBg)).
+			Foreground(color(theme.MineFg)),
 
-	flagStyle = cellStyle.
-			Background(lipgloss.Color("238")).
-			Foreground(lipgloss.Color("226"))
+		flag: cellStyle.
+			Background(color(theme.UnrevealedBg)).
+			Foreground(color(theme.FlagFg)),
 
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			PaddingLeft(1)
+		flagCursor: cellStyle.
+			Background(color(theme.CursorBg)).
+			Foreground(color(theme.FlagFg)),
 
-	gameOverStyle = lipgloss.NewStyle().
+		mark: cellStyle.
+			Background(color(theme.UnrevealedBg)).
+			Foreground(color(theme.MarkFg)),
+
+		markCursor: cellStyle.
+			Background(color(theme.CursorBg)).
+			Foreground(color(theme.MarkFg)),
+
+		hint: cellStyle.
+			Background(color(theme.HintBg)).
+			Foreground(color(theme.UnrevealedFg)),
+
+		wrongFlag: cellStyle.
+			Background(color(theme.WarningBg)).
+			Foreground(color(theme.FlagFg)),
+
+		warning: r.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("196")).
-			PaddingLeft(1)
+			Foreground(color(theme.Warning)).
+			PaddingLeft(1),
 
-	gameWonStyle = lipgloss.NewStyle().
+		help: r.NewStyle().
+			Foreground(color(theme.Help)).
+			PaddingLeft(1),
+
+		gameOver: r.NewStyle().
+			Bold(true).
+			Foreground(color(theme.Lost)).
+			PaddingLeft(1),
+
+		gameWon: r.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("46")).
-			PaddingLeft(1)
-
-	numberColors = map[int]lipgloss.Color{
-		1: lipgloss.Color("21"),
-		2: lipgloss.Color("22"),
-		3: lipgloss.Color("196"),
-		4: lipgloss.Color("20"),
-		5: lipgloss.Color("88"),
-		6: lipgloss.Color("51"),
-		7: lipgloss.Color("16"),
-		8: lipgloss.Color("236"),
+			Foreground(color(theme.Won)).
+			PaddingLeft(1),
+
+		numberColors:      map[int]lipgloss.TerminalColor{},
+		defaultNumberFore: color(theme.Header),
 	}
-)
 
-func getNumberStyle(num int) lipgloss.Style {
-	color, ok := numberColors[num]
+	for i, c := range theme.Numbers {
+		s.numberColors[i+1] = color(c)
+	}
+
+	return s
+}
+
+// withTheme は同じ描画先で別のテーマのスタイルを作る.
+func (s styles) withTheme(theme Theme) styles {
+	return newRendererStyles(s.renderer, theme)
+}
+
+func (s styles) numberStyle(num int) lipgloss.Style {
+	c, ok := s.numberColors[num]
 	if !ok {
-		color = lipgloss.Color("250")
+		c = s.defaultNumberFore
+	}
+	return s.revealed.Foreground(c)
+}
+
+// withCursor はカーソル位置のセルのスタイルを返す.
+// ASCIIテーマでは背景色の代わりに括弧で囲むので、スタイルはそのまま.
+func (s styles) withCursor(style lipgloss.Style) lipgloss.Style {
+	if s.theme.ASCII {
+		return style
+	}
+	return style.Background(s.revealedCursorBg)
+}
+
+// cursorContent はASCIIテーマでカーソル位置を括弧で示す.
+func (s styles) cursorContent(content string, isCursor bool) string {
+	if s.theme.ASCII && isCursor {
+		return "[" + content + "]"
+	}
+	return content
+}
+
+func color(c string) lipgloss.TerminalColor {
+	if c == "" {
+		return lipgloss.NoColor{}
 	}
-	return revealedStyle.Foreground(color)
+	return lipgloss.Color(c)
 }
@@ -0,0 +1,273 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme は盤面の配色と記号の定義.
+// 色は256色コードまたは"#rrggbb"で指定し、空文字は「色を付けない」を意味する.
+type Theme struct {
+	Name string `json:"name"`
+	// ASCII が true の場合は色に頼らず、カーソルを括弧で表示する.
+	ASCII bool `json:"ascii"`
+
+	Title            string `json:"title"`
+	Header           string `json:"header"`
+	Help             string `json:"help"`
+	UnrevealedBg     string `json:"unrevealed_bg"`
+	UnrevealedFg     string `json:"unrevealed_fg"`
+	RevealedBg       string `json:"revealed_bg"`
+	CursorBg         string `json:"cursor_bg"`
+	CursorFg         string `json:"cursor_fg"`
+	RevealedCursorBg string `json:"revealed_cursor_bg"`
+	MineBg           string `json:"mine_bg"`
+	MineFg           string `json:"mine_fg"`
+	FlagFg           string `json:"flag_fg"`
+	MarkFg           string `json:"mark_fg"`
+	HintBg           string `json:"hint_bg"`
+	// Warning は警告メッセージの文字色、WarningBg は数字と矛盾する旗の背景色.
+	Warning   string `json:"warning"`
+	WarningBg string `json:"warning_bg"`
+	Won       string `json:"won"`
+	Lost      string `json:"lost"`
+	// Numbers は数字1〜8の文字色（インデックス0が数字1）.
+	Numbers []string `json:"numbers"`
+
+	FlagSymbol   string `json:"flag_symbol"`
+	MineSymbol   string `json:"mine_symbol"`
+	HiddenSymbol string `json:"hidden_symbol"`
+	HintSymbol   string `json:"hint_symbol"`
+	// QuestionSymbol はプレイヤーが付けた「?」の印の記号.
+	QuestionSymbol string `json:"question_symbol"`
+	// WrongFlagSymbol は数字と矛盾する旗の記号.
+	WrongFlagSymbol string `json:"wrong_flag_symbol"`
+	// MarkSymbol はAIが地雷と確定させた印の記号。プレイヤーの旗とは別の記号と色（MarkFg）で表示する.
+	MarkSymbol  string `json:"mark_symbol"`
+	EmptySymbol string `json:"empty_symbol"`
+}
+
+// DefaultTheme は暗い背景の256色端末向けの標準テーマ.
+var DefaultTheme = Theme{
+	Name:             "default",
+	Title:            "226",
+	Header:           "250",
+	Help:             "244",
+	UnrevealedBg:     "238",
+	UnrevealedFg:     "250",
+	RevealedBg:       "235",
+	CursorBg:         "33",
+	CursorFg:         "231",
+	RevealedCursorBg: "239",
+	MineBg:           "196",
+	MineFg:           "231",
+	HintBg:           "28",
+	Warning:          "214",
+	WarningBg:        "130",
+	FlagFg:           "226",
+	MarkFg:           "213",
+	Won:              "46",
+	Lost:             "196",
+	Numbers:          []string{"39", "40", "208", "141", "203", "51", "255", "250"},
+	FlagSymbol:       "F",
+	MarkSymbol:       "m",
+	MineSymbol:       "*",
+	HiddenSymbol:     " ",
+	HintSymbol:       " ",
+	QuestionSymbol:   "?",
+	WrongFlagSymbol:  "F",
+	EmptySymbol:      " ",
+}
+
+// ColorBlindTheme は色覚多様性に配慮したOkabe-Ito配色のテーマ.
+// 赤と緑の組み合わせを避け、地雷と数字を明度差でも区別できるようにしている.
+var ColorBlindTheme = Theme{
+	Name:             "colorblind",
+	Title:            "214",
+	Header:           "250",
+	Help:             "244",
+	UnrevealedBg:     "238",
+	UnrevealedFg:     "250",
+	RevealedBg:       "235",
+	CursorBg:         "25",
+	CursorFg:         "231",
+	RevealedCursorBg: "240",
+	MineBg:           "208",
+	MineFg:           "16",
+	HintBg:           "31",
+	Warning:          "227",
+	WarningBg:        "94",
+	FlagFg:           "227",
+	MarkFg:           "175",
+	Won:              "117",
+	Lost:             "208",
+	Numbers:          []string{"117", "227", "214", "75", "175", "37", "231", "250"},
+	FlagSymbol:       "F",
+	MarkSymbol:       "m",
+	MineSymbol:       "*",
+	HiddenSymbol:     " ",
+	HintSymbol:       " ",
+	QuestionSymbol:   "?",
+	WrongFlagSymbol:  "F",
+	EmptySymbol:      " ",
+}
+
+// HighContrastTheme は黒地に原色を使った高コントラストのテーマ.
+var HighContrastTheme = Theme{
+	Name:             "high-contrast",
+	Title:            "231",
+	Header:           "231",
+	Help:             "231",
+	UnrevealedBg:     "244",
+	UnrevealedFg:     "16",
+	RevealedBg:       "16",
+	CursorBg:         "226",
+	CursorFg:         "16",
+	RevealedCursorBg: "240",
+	MineBg:           "196",
+	MineFg:           "231",
+	HintBg:           "46",
+	Warning:          "226",
+	WarningBg:        "201",
+	FlagFg:           "226",
+	MarkFg:           "201",
+	Won:              "46",
+	Lost:             "196",
+	Numbers:          []string{"51", "46", "226", "201", "208", "87", "231", "231"},
+	FlagSymbol:       "F",
+	MarkSymbol:       "m",
+	MineSymbol:       "*",
+	HiddenSymbol:     " ",
+	HintSymbol:       " ",
+	QuestionSymbol:   "?",
+	WrongFlagSymbol:  "F",
+	EmptySymbol:      " ",
+}
+
+// LightTheme は明るい背景の端末向けのテーマ.
+var LightTheme = Theme{
+	Name:             "light",
+	Title:            "130",
+	Header:           "238",
+	Help:             "242",
+	UnrevealedBg:     "250",
+	UnrevealedFg:     "236",
+	RevealedBg:       "255",
+	CursorBg:         "33",
+	CursorFg:         "231",
+	RevealedCursorBg: "153",
+	MineBg:           "160",
+	MineFg:           "231",
+	HintBg:           "114",
+	Warning:          "166",
+	WarningBg:        "223",
+	FlagFg:           "160",
+	MarkFg:           "90",
+	Won:              "28",
+	Lost:             "160",
+	Numbers:          []string{"21", "28", "124", "18", "88", "30", "16", "240"},
+	FlagSymbol:       "F",
+	MarkSymbol:       "m",
+	MineSymbol:       "*",
+	HiddenSymbol:     " ",
+	HintSymbol:       " ",
+	QuestionSymbol:   "?",
+	WrongFlagSymbol:  "F",
+	EmptySymbol:      " ",
+}
+
+// MonoTheme は色を一切使わないASCIIのみのテーマ.
+// NO_COLORが設定されている場合や色を表示できない端末で使用する.
+var MonoTheme = Theme{
+	Name:           "mono",
+	ASCII:          true,
+	FlagSymbol:     "F",
+	MarkSymbol:     "m",
+	MineSymbol:     "*",
+	HiddenSymbol:   "#",
+	HintSymbol:     "+",
+	QuestionSymbol: "?",
+	EmptySymbol:    ".",
+	// 色で区別できないので、矛盾する旗は記号で示す
+	WrongFlagSymbol: "!",
+}
+
+// BuiltinThemes は組み込みテーマの一覧（切り替え順）.
+var BuiltinThemes = []Theme{
+	DefaultTheme,
+	ColorBlindTheme,
+	HighContrastTheme,
+	LightTheme,
+	MonoTheme,
+}
+
+// ThemeByName は名前から組み込みテーマを取得.
+func ThemeByName(name string) (Theme, bool) {
+	for _, t := range BuiltinThemes {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// LoadTheme は組み込みテーマ名またはJSONファイルのパスからテーマを読み込む.
+// ファイルで省略された項目は標準テーマの値で補われる.
+func LoadTheme(nameOrPath string) (Theme, error) {
+	if t, ok := ThemeByName(nameOrPath); ok {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(nameOrPath) //nolint:gosec // ユーザーが指定したテーマファイルを読む
+	if err != nil {
+		return Theme{}, fmt.Errorf("unknown theme %q: %w", nameOrPath, err)
+	}
+
+	theme := DefaultTheme
+	theme.Numbers = append([]string(nil), DefaultTheme.Numbers...)
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return Theme{}, fmt.Errorf("invalid theme file %s: %w", nameOrPath, err)
+	}
+	if theme.Name == "" || theme.Name == DefaultTheme.Name {
+		theme.Name = nameOrPath
+	}
+	return theme, nil
+}
+
+// DetectTheme は環境変数と端末の能力から適切なテーマを選ぶ.
+func DetectTheme() Theme {
+	return detectTheme(lipgloss.DefaultRenderer())
+}
+
+// detectTheme は描画先の端末の色数と背景色からテーマを選ぶ.
+func detectTheme(r *lipgloss.Renderer) Theme {
+	if os.Getenv("NO_COLOR") != "" {
+		return MonoTheme
+	}
+	return detectThemeForProfile(r.ColorProfile(), r.HasDarkBackground())
+}
+
+func detectThemeForProfile(profile termenv.Profile, darkBackground bool) Theme {
+	if profile == termenv.Ascii {
+		return MonoTheme
+	}
+	if !darkBackground {
+		return LightTheme
+	}
+	return DefaultTheme
+}
+
+// nextTheme は組み込みテーマの中で次のテーマを返す.
+func nextTheme(current Theme) Theme {
+	for i, t := range BuiltinThemes {
+		if t.Name == current.Name {
+			return BuiltinThemes[(i+1)%len(BuiltinThemes)]
+		}
+	}
+	return BuiltinThemes[0]
+}
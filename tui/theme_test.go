@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestLoadTheme_Builtin(t *testing.T) {
+	for _, want := range BuiltinThemes {
+		t.Run(want.Name, func(t *testing.T) {
+			got, err := LoadTheme(want.Name)
+			if err != nil {
+				t.Fatalf("LoadTheme(%q) error = %v", want.Name, err)
+			}
+			if got.Name != want.Name {
+				t.Errorf("Name = %q, want %q", got.Name, want.Name)
+			}
+		})
+	}
+}
+
+func TestLoadTheme_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mytheme.json")
+	data := `{"name": "mine", "mine_bg": "#ff00ff", "numbers": ["1", "2"]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	theme, err := LoadTheme(path)
+	if err != nil {
+		t.Fatalf("LoadTheme() error = %v", err)
+	}
+
+	if theme.Name != "mine" {
+		t.Errorf("Name = %q, want %q", theme.Name, "mine")
+	}
+	if theme.MineBg != "#ff00ff" {
+		t.Errorf("MineBg = %q, want %q", theme.MineBg, "#ff00ff")
+	}
+	// 省略された項目は標準テーマで補われる
+	if theme.CursorBg != DefaultTheme.CursorBg {
+		t.Errorf("CursorBg = %q, want %q", theme.CursorBg, DefaultTheme.CursorBg)
+	}
+	if len(theme.Numbers) != 2 {
+		t.Errorf("len(Numbers) = %d, want 2", len(theme.Numbers))
+	}
+}
+
+func TestLoadTheme_Unknown(t *testing.T) {
+	if _, err := LoadTheme("no-such-theme"); err == nil {
+		t.Error("LoadTheme() should fail for unknown theme")
+	}
+}
+
+func TestDetectTheme_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	if got := DetectTheme(); got.Name != MonoTheme.Name {
+		t.Errorf("DetectTheme() = %q, want %q", got.Name, MonoTheme.Name)
+	}
+}
+
+func TestDetectThemeForProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile termenv.Profile
+		dark    bool
+		want    string
+	}{
+		{"ascii terminal", termenv.Ascii, true, MonoTheme.Name},
+		{"dark 256 colors", termenv.ANSI256, true, DefaultTheme.Name},
+		{"light background", termenv.TrueColor, false, LightTheme.Name},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectThemeForProfile(tt.profile, tt.dark); got.Name != tt.want {
+				t.Errorf("detectThemeForProfile() = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextTheme_Cycles(t *testing.T) {
+	theme := BuiltinThemes[0]
+	for range BuiltinThemes {
+		theme = nextTheme(theme)
+	}
+	if theme.Name != BuiltinThemes[0].Name {
+		t.Errorf("nextTheme() did not cycle back, got %q", theme.Name)
+	}
+}
@@ -3,130 +3,268 @@ package tui
 import (
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/r-horie/ai-minesweeper/config"
 	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/solver"
 )
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) { //nolint:gocyclo // UIの処理は多くの分岐が必要
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.coop != nil {
+		// ほかのプレイヤーも同じゲームを操作するので、処理の間はゲームをロックする
+		m.game.Lock()
+		defer m.game.Unlock()
+	}
+	model, cmd := m.update(msg)
+	if _, tick := msg.(tickMsg); !tick {
+		model.(Model).reportStatus()
+		model.(Model).publish(eventKind(msg))
+	}
+	if m.coop != nil && changesGame(msg) {
+		m.coop.Broadcast(model.(Model).cursor)
+	}
+	return model, cmd
+}
+
+// changesGame はプレイヤーの操作かAIの手で、ゲームかカーソルが変わりうるメッセージかどうか.
+func changesGame(msg tea.Msg) bool {
+	switch msg.(type) {
+	case tea.KeyMsg, solverMsg, guessMsg, revealCellMsg:
+		return true
+	}
+	return false
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) { //nolint:gocyclo // UIの処理は多くの分岐が必要
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c", "ctrl+q":
+		action, ok := m.keys.action(msg.String())
+		if !ok || m.actionLocked(action) {
+			return m, nil
+		}
+
+		switch action {
+		case config.ActionQuit:
 			return m, tea.Quit
+		case config.ActionHelp:
+			m.showHelp = !m.showHelp
+			return m, nil
+		case config.ActionSnapshot:
+			return m, m.snapshot()
+		case config.ActionAuto:
+			m.autoPlay = !m.autoPlay
+			if m.autoPlay && !m.aiThinking && m.game.State == game.Playing {
+				return m, m.startAuto()
+			}
+			return m, nil
 		}
 
 		if m.aiThinking {
 			return m, nil
 		}
 
-		switch msg.String() {
-		case "up", "k":
-			if m.cursor.Row > 0 {
-				m.cursor.Row--
-			}
+		switch action {
+		case config.ActionUp:
+			m.moveCursor(-1, 0)
 
-		case "down", "j":
-			if m.cursor.Row < m.game.Board.Height-1 {
-				m.cursor.Row++
-			}
+		case config.ActionDown:
+			m.moveCursor(1, 0)
 
-		case "left", "h":
-			if m.cursor.Col > 0 {
-				m.cursor.Col--
-			}
+		case config.ActionLeft:
+			m.moveCursor(0, -1)
 
-		case "right", "l":
-			if m.cursor.Col < m.game.Board.Width-1 {
-				m.cursor.Col++
-			}
+		case config.ActionRight:
+			m.moveCursor(0, 1)
 
-		case " ", "space", "enter":
+		case config.ActionReveal:
 			if m.game.State == game.Playing {
 				cell := m.game.Board.GetCell(m.cursor)
 				if cell != nil && !cell.IsRevealed {
-					m.game.Click(m.cursor)
-					if m.game.State == game.Playing {
+					m.hint = nil
+					first := m.game.FirstClick
+					m.countGuess(m.cursor)
+					m.notify(m.game.Click(m.cursor)...)
+					m.checkFlags()
+					var cmds []tea.Cmd
+					if first {
+						// 最初のクリックで地雷が配置されるので、ここで盤面を評価できる
+						cmds = append(cmds, m.rateBoard(m.cursor))
+					}
+					if m.game.State == game.Playing && m.assist != config.AssistOff {
 						m.aiThinking = true
-						return m, m.runSolver()
+						cmds = append(cmds, m.runSolver())
 					}
+					return m, tea.Batch(cmds...)
 				}
 			}
 
-		case "f":
+		case config.ActionFlag:
 			if m.game.State == game.Playing {
 				m.game.ToggleFlag(m.cursor)
+				m.notify(m.cursor)
+				m.checkFlags()
 			}
 
-		case "r":
-			m.game.Reset()
-			m.solver = nil
-			m.cursor = game.Position{Row: 0, Col: 0}
-			m.aiThinking = false
-			m.pendingReveals = []game.Position{}
+		case config.ActionTheme:
+			m.styles = m.styles.withTheme(nextTheme(m.styles.theme))
+
+		case config.ActionNewGame:
+			if m.race != nil {
+				m.startRound()
+			} else {
+				m.resetGame()
+			}
 
-		case "1":
+		case config.ActionBeginner:
 			m.game.Difficulty = game.Beginner
-			m.game.Reset()
-			m.solver = nil
-			m.cursor = game.Position{Row: 0, Col: 0}
-			m.aiThinking = false
-			m.pendingReveals = []game.Position{}
+			m.resetGame()
 
-		case "2":
+		case config.ActionIntermediate:
 			m.game.Difficulty = game.Intermediate
-			m.game.Reset()
-			m.solver = nil
-			m.cursor = game.Position{Row: 0, Col: 0}
-			m.aiThinking = false
-			m.pendingReveals = []game.Position{}
+			m.resetGame()
 
-		case "3":
+		case config.ActionExpert:
 			m.game.Difficulty = game.Expert
-			m.game.Reset()
-			m.solver = nil
-			m.cursor = game.Position{Row: 0, Col: 0}
-			m.aiThinking = false
-			m.pendingReveals = []game.Position{}
+			m.resetGame()
 		}
 
 	case solverMsg:
+		if msg.board != m.game.Board {
+			// 考えている間に新しいゲームが始まった
+			m.aiThinking = false
+			return m, nil
+		}
 		result := msg.result
+		m.deduced = &result
+		m.wrongFlags = solver.ContradictingFlags(msg.contradictions)
 
 		for _, minePos := range result.MineCells {
 			cell := m.game.Board.GetCell(minePos)
-			if cell != nil && !cell.IsFlagged {
-				cell.IsFlagged = true
+			if cell != nil && !cell.IsMarked {
+				cell.MarkMines(result.MineCount(minePos))
+				m.notify(minePos)
 			}
 		}
 
-		if len(result.SafeCells) > 0 {
+		switch {
+		case len(result.SafeCells) > 0 && m.aiReveals():
 			m.pendingReveals = result.SafeCells
-			return m, revealNextCell(result.SafeCells, 0)
-		} else {
+			return m, m.revealNextCell(result.SafeCells, 0)
+		case len(result.MineCells) > 0 && m.aiReveals():
+			// 旗を立てたことで新しく安全と分かるマスがあるかもしれない
+			return m, m.runSolver()
+		case msg.hint != nil && m.autoPlay:
+			m.hint = msg.hint
+			return m, m.guessAfterDelay(*msg.hint)
+		default:
+			m.hint = msg.hint
 			m.aiThinking = false
 		}
 
+	case ratingMsg:
+		if msg.board == m.game.Board {
+			m.rating = &msg.rating
+		}
+
+	case guessMsg:
+		if !m.autoPlay || m.game.State != game.Playing || msg.board != m.game.Board {
+			m.aiThinking = false
+			return m, nil
+		}
+		m.hint = nil
+		m.notify(m.game.Click(msg.guess.Position)...)
+		if m.game.State == game.Playing {
+			return m, m.runSolver()
+		}
+		m.aiThinking = false
+
 	case revealCellMsg:
+		if msg.board != m.game.Board {
+			m.aiThinking = false
+			return m, nil
+		}
 		if msg.index < len(msg.positions) {
-			pos := msg.positions[msg.index]
-			m.game.Board.RevealCell(pos)
+			// プレイヤーが信じさせた間違った旗のせいで地雷を開くこともあるので、ライフと勝敗はゲームに任せる
+			m.notify(m.game.Click(msg.positions[msg.index])...)
 
-			if m.game.Board.CountUnrevealedSafeCells() == 0 {
-				m.game.State = game.Won
+			if m.game.State != game.Playing {
 				m.aiThinking = false
 				return m, nil
 			}
 
 			if msg.index+1 < len(msg.positions) {
-				return m, revealNextCell(msg.positions, msg.index+1)
+				return m, m.revealNextCell(msg.positions, msg.index+1)
 			} else {
 				m.aiThinking = true
 				return m, m.runSolver()
 			}
 		}
 
+	case coopMsg:
+		return m.handleCoop()
+
+	case spectateMsg:
+		return m.handleSpectate(msg)
+
+	case snapshotMsg:
+		return m.handleSnapshot(msg)
+
 	case tickMsg:
 		return m, tickCmd()
 	}
 
 	return m, nil
 }
+
+// moveCursor はカーソルを動かす。トーラスの盤面では端から反対側へ回り込む.
+func (m *Model) moveCursor(dr, dc int) {
+	b := m.game.Board
+	next := game.Position{Row: m.cursor.Row + dr, Col: m.cursor.Col + dc}
+	if b.GetTopology() == game.TorusTopology {
+		next = game.Position{Row: (next.Row + b.Height) % b.Height, Col: (next.Col + b.Width) % b.Width}
+	}
+	if b.IsValidPosition(next) {
+		m.cursor = next
+	}
+}
+
+func (m *Model) resetGame() {
+	m.game.Reset()
+	m.solver = nil
+	m.cursor = game.Position{Row: 0, Col: 0}
+	m.aiThinking = false
+	m.pendingReveals = []game.Position{}
+	m.hint = nil
+	m.rating = nil
+	m.wrongFlags = nil
+	m.deduced = nil
+	m.snapshotNote = ""
+}
+
+// aiReveals はAIが安全なマスを自分で開くかどうか。自動プレイ中は支援レベルに関係なく開く.
+func (m *Model) aiReveals() bool {
+	return m.assist == config.AssistFull || m.autoPlay
+}
+
+// actionLocked は今のモードで使えない操作かどうか.
+func (m *Model) actionLocked(action config.Action) bool {
+	switch {
+	case action == config.ActionSnapshot:
+		return m.noSnapshots
+	case m.events != nil:
+		return !spectatorAction(action)
+	case m.race != nil:
+		return raceLocked(action)
+	}
+	return false
+}
+
+// startAuto は自動プレイを始める。まだ開いていなければ中央から開く.
+func (m *Model) startAuto() tea.Cmd {
+	m.aiThinking = true
+	if m.game.FirstClick {
+		center := game.Position{Row: m.game.Board.Height / 2, Col: m.game.Board.Width / 2}
+		m.game.Click(center)
+		return tea.Batch(m.rateBoard(center), m.runSolver())
+	}
+	return m.runSolver()
+}
@@ -2,28 +2,50 @@ package tui
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/r-horie/ai-minesweeper/config"
 	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/i18n"
 )
 
 func (m Model) View() string {
+	if m.coop != nil {
+		m.game.RLock()
+		defer m.game.RUnlock()
+	}
 	var sections []string
 
 	sections = append(sections, m.renderTitle())
 	sections = append(sections, m.renderHeader())
-	sections = append(sections, m.renderBoard())
+	if m.showHelp {
+		sections = append(sections, m.renderHelpOverlay())
+	} else {
+		sections = append(sections, m.renderBoard())
+	}
 	sections = append(sections, m.renderStatus())
+	switch {
+	case m.events != nil:
+		sections = append(sections, m.renderSpectator())
+	case m.race != nil:
+		sections = append(sections, m.renderRace())
+	case m.lobby != nil:
+		sections = append(sections, m.renderLobby())
+	case m.coop != nil:
+		sections = append(sections, m.renderCoop())
+	}
 	sections = append(sections, m.renderHelp())
 
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
 func (m Model) renderTitle() string {
-	return titleStyle.Render("AIマインスイーパー - AIにネタバレされるマインスイーパー")
+	return m.styles.title.Render(m.text.T("title"))
 }
 
 func (m Model) renderHeader() string {
@@ -33,20 +55,35 @@ func (m Model) renderHeader() string {
 		elapsed = 0
 	}
 
-	header := fmt.Sprintf("地雷: %d  時間: %02d:%02d  難易度: %s",
+	header := m.text.T("header",
 		remainingMines,
 		elapsed/60,
 		elapsed%60,
-		m.game.Difficulty.Name,
+		m.difficultyName(m.game.Difficulty),
+		m.styles.theme.Name,
 	)
-	return headerStyle.Render(header)
+	if m.game.Options.Lives > 1 {
+		header += "  " + m.text.T("header.lives", m.game.Lives)
+	}
+	if n := m.game.Board.Neighborhood; n != "" && n != game.NeighborhoodStandard {
+		header += "  " + m.text.T("header.neighborhood", m.text.T("neighborhood."+string(n)))
+	}
+	if m.rating != nil {
+		header += "  " + m.text.T("header.rating", m.text.T("rule."+m.rating.Hardest.String()), m.rating.Guesses)
+	}
+	return m.styles.header.Render(header)
 }
 
 func (m Model) renderBoard() string {
 	var rows []string
+	hex := m.game.Board.GetTopology() == game.HexTopology
 
 	for row := 0; row < m.game.Board.Height; row++ {
 		var cells []string
+		// 六角形のマス目は奇数行を半マスずらして、斜めの隣接が見て分かるようにする
+		if hex && row%2 != 0 {
+			cells = append(cells, strings.Repeat(" ", hexIndent))
+		}
 		for col := 0; col < m.game.Board.Width; col++ {
 			pos := game.Position{Row: row, Col: col}
 			cells = append(cells, m.renderCell(pos))
@@ -55,13 +92,14 @@ func (m Model) renderBoard() string {
 	}
 
 	board := lipgloss.JoinVertical(lipgloss.Left, rows...)
-	return lipgloss.NewStyle().PaddingLeft(1).Render(board)
+	return m.styles.renderer.NewStyle().PaddingLeft(1).Render(board)
 }
 
 func (m Model) renderCell(pos game.Position) string {
+	st := m.styles
 	cell := m.game.Board.GetCell(pos)
 	if cell == nil {
-		return cellStyle.Render(" ")
+		return st.cell.Render(" ")
 	}
 
 	isCursor := pos.Row == m.cursor.Row && pos.Col == m.cursor.Col
@@ -70,65 +108,212 @@ func (m Model) renderCell(pos game.Position) string {
 	var content string
 
 	if m.game.State == game.Lost && cell.IsMine && cell.IsRevealed {
-		style = mineStyle
-		content = "*"
-	} else if cell.IsFlagged {
-		style = flagStyle
-		if isCursor {
-			style = style.Background(lipgloss.Color("33"))
+		style = st.mine
+		content = withCount(st.theme.MineSymbol, cell.MineCount())
+	} else if cell.IsRevealed {
+		style, content = st.openedCell(cell, isCursor)
+	} else {
+		hinted := m.hint != nil && m.hint.Position == pos
+		style, content = st.coveredCell(cell, isCursor, hinted, slices.Contains(m.wrongFlags, pos))
+	}
+
+	partner, hasPartner := m.partnerAt(pos)
+	if hasPartner && !isCursor {
+		style, content = st.partnerCursor(style, content, partner)
+	}
+
+	// ASCIIテーマでカーソルを括弧で囲むマスは、斜線を付けると幅に収まらない
+	bracketed := (isCursor || hasPartner) && st.theme.ASCII
+	if m.game.Board.GetTopology() == game.TriangleTopology && !bracketed {
+		content = triangleContent(content, pos)
+	}
+	return style.Width(m.cellWidth()).Render(st.cursorContent(content, isCursor))
+}
+
+// cellWidth はマスの表示幅。2桁の数字や地雷の数が出る盤面では、括弧や斜線で囲んでも収まるよう1マス広げる.
+// 数字の最大値は隣接マスが最も多い盤面の中央で判断する.
+func (m Model) cellWidth() int {
+	b := m.game.Board
+	center := game.Position{Row: b.Height / 2, Col: b.Width / 2}
+	if b.CellCapacity() > 1 || len(b.GetAdjacentPositions(center)) >= 10 {
+		return wideCellWidth
+	}
+	return narrowCellWidth
+}
+
+// withCount は記号に地雷の数を添える。1個なら記号だけ.
+func withCount(symbol string, n int) string {
+	if n > 1 {
+		return symbol + strconv.Itoa(n)
+	}
+	return symbol
+}
+
+// coveredCell は未開放のマス（旗・AIの印・「?」を含む）のスタイルと記号を返す.
+// hinted はAIが推測を勧めるマス、wrongFlag は数字と矛盾する旗かどうか.
+func (st styles) coveredCell(cell *game.Cell, isCursor, hinted, wrongFlag bool) (lipgloss.Style, string) {
+	cursor := isCursor && !st.theme.ASCII
+
+	switch {
+	case cell.IsFlagged:
+		if wrongFlag {
+			return st.wrongFlag, st.theme.WrongFlagSymbol
 		}
-		content = "F"
-	} else if !cell.IsRevealed {
-		style = unrevealedStyle
-		if isCursor {
-			style = cursorStyle
+		flag := withCount(st.theme.FlagSymbol, cell.FlagCount())
+		if cursor {
+			return st.flagCursor, flag
 		}
-		content = " "
-	} else if cell.IsMine {
-		style = mineStyle
-		content = "*"
-	} else if cell.Adjacent == 0 {
-		style = revealedStyle
-		if isCursor {
-			style = style.Background(lipgloss.Color("239"))
+		return st.flag, flag
+	case cell.IsMarked:
+		mark := withCount(st.theme.MarkSymbol, cell.MarkCount())
+		if cursor {
+			return st.markCursor, mark
 		}
-		content = " "
-	} else {
-		style = getNumberStyle(cell.Adjacent)
-		if isCursor {
-			style = style.Background(lipgloss.Color("239"))
+		return st.mark, mark
+	case cell.IsQuestioned:
+		if cursor {
+			return st.cursor, st.theme.QuestionSymbol
 		}
+		return st.unrevealed, st.theme.QuestionSymbol
+	}
+
+	style := st.unrevealed
+	content := st.theme.HiddenSymbol
+	if hinted {
+		style = st.hint
+		content = st.theme.HintSymbol
+	}
+	if cursor {
+		style = st.cursor
+	}
+	return style, content
+}
+
+// openedCell は開いたマスのスタイルと記号を返す.
+func (st styles) openedCell(cell *game.Cell, isCursor bool) (lipgloss.Style, string) {
+	var style lipgloss.Style
+	var content string
+	switch {
+	case cell.IsMine:
+		return st.mine, withCount(st.theme.MineSymbol, cell.MineCount())
+	case cell.Adjacent == 0:
+		style = st.revealed
+		content = st.theme.EmptySymbol
+	default:
+		style = st.numberStyle(cell.Adjacent)
 		content = fmt.Sprintf("%d", cell.Adjacent)
 	}
+	if isCursor {
+		style = st.withCursor(style)
+	}
+	return style, content
+}
 
-	return style.Render(content)
+// マスの表示幅。wideCellWidth は2桁の数字をカーソルの括弧や三角形の斜線で囲む盤面で使う.
+const (
+	narrowCellWidth = 3
+	wideCellWidth   = 4
+)
+
+// hexIndent は六角形のマス目で奇数行をずらす幅（マスの幅のおよそ半分）.
+const hexIndent = 2
+
+// triangleContent は三角形のマス目で、マスの向きが分かるように斜線で挟む.
+func triangleContent(content string, pos game.Position) string {
+	if game.IsUpTriangle(pos) {
+		return "/" + content + "\\"
+	}
+	return "\\" + content + "/"
 }
 
 func (m Model) renderStatus() string {
 	var status string
 	switch m.game.State {
 	case game.Won:
-		status = gameWonStyle.Render("🎉 おめでとうございます！クリアしました！")
+		status = m.styles.gameWon.Render(m.text.T("status.won"))
 	case game.Lost:
-		status = gameOverStyle.Render("💥 ゲームオーバー！地雷を踏みました！")
+		status = m.styles.gameOver.Render(m.text.T("status.lost"))
 	default:
 		if m.aiThinking {
-			status = headerStyle.Render("🤖 AIが考え中...")
+			status = m.styles.header.Render(m.text.T("status.thinking"))
 		} else {
-			status = headerStyle.Render("あなたの番です！運命の選択を...")
+			status = m.styles.header.Render(m.text.T("status.your_turn"))
+		}
+		if m.hint != nil {
+			status = lipgloss.JoinVertical(lipgloss.Left, status, m.styles.header.Render(m.text.T("status.hint",
+				m.hint.Position.Row, m.hint.Position.Col, 100*m.hint.Probability)))
+		}
+	}
+	if len(m.wrongFlags) > 0 && m.game.State == game.Playing {
+		var flags []string
+		for _, pos := range m.wrongFlags {
+			flags = append(flags, fmt.Sprintf("(%d,%d)", pos.Row, pos.Col))
 		}
+		status = lipgloss.JoinVertical(lipgloss.Left, status,
+			m.styles.warning.Render(m.text.T("status.contradiction", strings.Join(flags, " "))))
+	}
+	if m.autoPlay {
+		status = lipgloss.JoinHorizontal(lipgloss.Top, status, m.styles.header.Render(m.text.T("status.auto")))
+	}
+	if m.snapshotNote != "" {
+		status = lipgloss.JoinVertical(lipgloss.Left, status, m.styles.header.Render(m.snapshotNote))
 	}
 	return status
 }
 
 func (m Model) renderHelp() string {
+	if m.events != nil {
+		return m.renderSpectatorHelp()
+	}
+	km := m.keys
+	move := strings.Join([]string{
+		km.keysLabel(config.ActionUp),
+		km.keysLabel(config.ActionDown),
+		km.keysLabel(config.ActionLeft),
+		km.keysLabel(config.ActionRight),
+	}, " ")
+	difficulty := strings.Join([]string{
+		km.keysLabel(config.ActionBeginner),
+		km.keysLabel(config.ActionIntermediate),
+		km.keysLabel(config.ActionExpert),
+	}, "/")
+
 	help := []string{
-		"[↑↓←→] カーソル移動",
-		"[スペース] マスを開く",
-		"[f] 旗を立てる",
-		"[r] 新しいゲーム",
-		"[1/2/3] 難易度変更",
-		"[q] 終了",
-	}
-	return helpStyle.Render(strings.Join(help, "  "))
+		fmt.Sprintf("[%s] %s", move, m.text.T("help.move")),
+		fmt.Sprintf("[%s] %s", km.keysLabel(config.ActionReveal), m.actionDescription(config.ActionReveal)),
+		fmt.Sprintf("[%s] %s", km.keysLabel(config.ActionFlag), m.actionDescription(config.ActionFlag)),
+		fmt.Sprintf("[%s] %s", km.keysLabel(config.ActionNewGame), m.actionDescription(config.ActionNewGame)),
+		fmt.Sprintf("[%s] %s", difficulty, m.text.T("help.difficulty")),
+		fmt.Sprintf("[%s] %s", km.keysLabel(config.ActionTheme), m.actionDescription(config.ActionTheme)),
+		fmt.Sprintf("[%s] %s", km.keysLabel(config.ActionAuto), m.actionDescription(config.ActionAuto)),
+		fmt.Sprintf("[%s] %s", km.keysLabel(config.ActionSnapshot), m.actionDescription(config.ActionSnapshot)),
+		fmt.Sprintf("[%s] %s", km.keysLabel(config.ActionHelp), m.actionDescription(config.ActionHelp)),
+		fmt.Sprintf("[%s] %s", km.keysLabel(config.ActionQuit), m.actionDescription(config.ActionQuit)),
+	}
+	return m.styles.help.Render(strings.Join(help, "  "))
+}
+
+// renderHelpOverlay は現在のキー割り当てからヘルプ画面を生成する.
+func (m Model) renderHelpOverlay() string {
+	return helpOverlay(m.styles, m.keys, m.text, config.Actions)
+}
+
+// helpOverlay は操作ごとのキー割り当ての一覧を生成する.
+func helpOverlay(st styles, km keyMap, text *i18n.Catalog, actions []config.Action) string {
+	lines := []string{text.T("help.title"), ""}
+	for _, action := range actions {
+		label := km.keysLabel(action)
+		padding := strings.Repeat(" ", max(1, 18-lipgloss.Width(label)))
+		lines = append(lines, "  "+label+padding+text.T("action."+string(action)))
+	}
+	return st.header.Render(strings.Join(lines, "\n"))
+}
+
+func (m Model) actionDescription(action config.Action) string {
+	return m.text.T("action." + string(action))
+}
+
+// difficultyName は難易度のキーを表示言語の名前に変換する.
+func (m Model) difficultyName(d game.Difficulty) string {
+	return m.text.T("difficulty." + d.Name)
 }
@@ -0,0 +1,181 @@
+// ブラウザ版のマインスイーパー。ゲームとソルバーは main.wasm（web/wasm）がTUIと同じコードで動かす.
+"use strict";
+
+const boardEl = document.getElementById("board");
+const statusEl = document.getElementById("status");
+const difficultyEl = document.getElementById("difficulty");
+const seedEl = document.getElementById("seed");
+
+let game = null;
+// hint はソルバーの意見。盤面を変えたら消す.
+let hint = null;
+// seedEdited はシードが入力されたかどうか。入力されていなければ、新しいゲームはランダムなシードにする.
+let seedEdited = false;
+
+async function load() {
+  if (typeof Go === "undefined") {
+    throw new Error("wasm_exec.js がありません");
+  }
+  const go = new Go();
+  const response = await fetch("main.wasm");
+  if (!response.ok) {
+    throw new Error("main.wasm がありません");
+  }
+  const { instance } = await WebAssembly.instantiateStreaming(response, go.importObject);
+  go.run(instance);
+}
+
+function call(name, ...args) {
+  const state = JSON.parse(minesweeper[name](...args));
+  if (state.error) {
+    statusEl.textContent = state.error;
+  }
+  return state;
+}
+
+function newGame() {
+  hint = null;
+  game = call("newGame", difficultyEl.value, seedEl.value.trim());
+  if (game.error) {
+    return;
+  }
+  // 同じURLを開けば同じ盤面で遊べるように、難易度とシードをURLに残す
+  const params = new URLSearchParams({ difficulty: game.difficulty, seed: game.seed });
+  history.replaceState(null, "", "?" + params);
+  seedEl.value = game.seed;
+  seedEdited = false;
+  render();
+}
+
+function play(action, row, col) {
+  if (game.state !== "playing") {
+    return;
+  }
+  hint = null;
+  game = call(action, row, col);
+  render();
+}
+
+function showHint() {
+  if (game.state !== "playing") {
+    return;
+  }
+  hint = JSON.parse(minesweeper.hint());
+  render();
+}
+
+function hintClass(row, col) {
+  if (!hint) {
+    return "";
+  }
+  const at = (p) => p.row === row && p.col === col;
+  if ((hint.safe || []).some(at)) {
+    return "hint-safe";
+  }
+  if ((hint.mines || []).some(at)) {
+    return "hint-mine";
+  }
+  if (hint.guess && at(hint.guess)) {
+    return "hint-guess";
+  }
+  return "";
+}
+
+function render() {
+  boardEl.style.gridTemplateColumns = `repeat(${game.width}, auto)`;
+  boardEl.replaceChildren();
+  game.board.forEach((line, row) => {
+    [...line].forEach((ch, col) => {
+      const cell = document.createElement("button");
+      cell.className = "cell";
+      cell.dataset.row = row;
+      cell.dataset.col = col;
+      switch (ch) {
+        case "?":
+          break;
+        case "F":
+          cell.textContent = "🚩";
+          break;
+        case "X":
+          cell.textContent = "💣";
+          cell.classList.add("open", "exploded");
+          break;
+        case ".":
+          cell.classList.add("open");
+          break;
+        default:
+          cell.textContent = ch;
+          cell.classList.add("open", "n" + ch);
+      }
+      const extra = hintClass(row, col);
+      if (extra) {
+        cell.classList.add(extra);
+      }
+      boardEl.append(cell);
+    });
+  });
+  renderStatus();
+}
+
+function renderStatus() {
+  const elapsed = game.start_time === 0 ? 0 : game.elapsed_time || Math.floor(Date.now() / 1000) - game.start_time;
+  let text = `残り地雷 ${game.remaining_mines}  時間 ${elapsed}s  シード ${game.seed}`;
+  if (game.state === "won") {
+    text = `クリア！ ${text}`;
+  } else if (game.state === "lost") {
+    text = `ゲームオーバー  ${text}`;
+  } else if (hint && hint.guess) {
+    text += `  推測: (${hint.guess.row},${hint.guess.col}) 地雷の確率 ${(hint.probability * 100).toFixed(1)}%`;
+  } else if (hint && hint.rule) {
+    text += `  ${hint.rule} で確定`;
+  }
+  statusEl.textContent = text;
+}
+
+function cellOf(event) {
+  const cell = event.target.closest(".cell");
+  return cell ? [Number(cell.dataset.row), Number(cell.dataset.col)] : null;
+}
+
+boardEl.addEventListener("click", (event) => {
+  const pos = cellOf(event);
+  if (pos) {
+    play("reveal", ...pos);
+  }
+});
+boardEl.addEventListener("dblclick", (event) => {
+  const pos = cellOf(event);
+  if (pos) {
+    play("chord", ...pos);
+  }
+});
+boardEl.addEventListener("contextmenu", (event) => {
+  event.preventDefault();
+  const pos = cellOf(event);
+  if (pos) {
+    play("flag", ...pos);
+  }
+});
+document.getElementById("controls").addEventListener("submit", (event) => {
+  event.preventDefault();
+  if (!seedEdited) {
+    seedEl.value = "";
+  }
+  newGame();
+});
+seedEl.addEventListener("input", () => {
+  seedEdited = true;
+});
+document.getElementById("hint").addEventListener("click", showHint);
+
+load().then(() => {
+  const params = new URLSearchParams(location.search);
+  if (params.has("difficulty")) {
+    difficultyEl.value = params.get("difficulty");
+  }
+  seedEl.value = params.get("seed") || "";
+  newGame();
+  setInterval(() => game && game.state === "playing" && renderStatus(), 1000);
+}).catch((err) => {
+  statusEl.textContent = `${err.message}。リポジトリで make wasm を実行してから、もう一度 web コマンドを起動してください。`;
+});
@@ -0,0 +1,152 @@
+//go:build js && wasm
+
+// Command wasm はゲームとソルバーをWebAssemblyにして、ブラウザのJavaScriptから使えるようにする.
+//
+// グローバルな minesweeper オブジェクトに次の関数を登録する。どれもゲームの状態をJSONの文字列で返す.
+//
+//	newGame(difficulty, seed)   新しいゲームを始める（difficulty は beginner / intermediate / expert、seed は10進の文字列）
+//	reveal(row, col)            マスを開く
+//	flag(row, col)              旗を切り替える
+//	chord(row, col)             数字と同じ数の旗が周囲にあれば、残りの周囲のマスをまとめて開く
+//	hint()                      ソルバーが確定できるマスか、最も安全な推測を返す
+//	state()                     今の状態を返す
+//
+// ビルドは make wasm で行う.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall/js"
+
+	"github.com/r-horie/ai-minesweeper/game"
+	"github.com/r-horie/ai-minesweeper/solver"
+)
+
+// state はブラウザに渡すゲームの状態。地雷の位置は含めない.
+type state struct {
+	State      game.GameState `json:"state"`
+	Difficulty string         `json:"difficulty"`
+	Width      int            `json:"width"`
+	Height     int            `json:"height"`
+	Mines      int            `json:"mines"`
+	// RemainingMines は地雷の数から旗の数を引いたもの.
+	RemainingMines int `json:"remaining_mines"`
+	// Seed はJavaScriptの数では桁が足りないことがあるので文字列で渡す.
+	Seed int64 `json:"seed,string"`
+	// Board は1行ずつの盤面の文字表記（盤面ファイルと同じ記号）.
+	Board []string `json:"board"`
+	// StartTime は最初のクリックの時刻（Unix秒）。まだ開いていなければ0.
+	StartTime   int64  `json:"start_time"`
+	ElapsedTime int64  `json:"elapsed_time"`
+	Error       string `json:"error,omitempty"`
+}
+
+// hint はソルバーの意見。確定できるマスがあれば Safe と Mines、なければ推測で開くマスの Guess を返す.
+type hint struct {
+	Rule  string          `json:"rule,omitempty"`
+	Safe  []game.Position `json:"safe,omitempty"`
+	Mines []game.Position `json:"mines,omitempty"`
+	Guess *game.Position  `json:"guess,omitempty"`
+	// Probability は Guess が地雷である確率.
+	Probability float64 `json:"probability,omitempty"`
+}
+
+var current = game.NewGame(game.Beginner)
+
+func main() {
+	api := map[string]any{
+		"newGame": js.FuncOf(newGame),
+		"reveal":  js.FuncOf(move(func(g *game.Game, pos game.Position) { g.Click(pos) })),
+		"flag":    js.FuncOf(move((*game.Game).ToggleFlag)),
+		"chord":   js.FuncOf(move(func(g *game.Game, pos game.Position) { g.Chord(pos) })),
+		"hint":    js.FuncOf(func(js.Value, []js.Value) any { return encode(currentHint()) }),
+		"state":   js.FuncOf(func(js.Value, []js.Value) any { return encode(snapshot("")) }),
+	}
+	js.Global().Set("minesweeper", js.ValueOf(api))
+	// ページが開いている間はJavaScriptから呼ばれ続けるので、終了しない
+	select {}
+}
+
+// newGame は難易度とシードで新しいゲームを始める。シードが0ならランダム.
+func newGame(_ js.Value, args []js.Value) any {
+	difficulty := game.Beginner
+	var seed int64
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		d, err := game.ParseDifficulty(args[0].String())
+		if err != nil {
+			return encode(snapshot(err.Error()))
+		}
+		difficulty = d
+	}
+	if len(args) > 1 && args[1].Type() == js.TypeString && args[1].String() != "" {
+		s, err := strconv.ParseInt(args[1].String(), 10, 64)
+		if err != nil {
+			return encode(snapshot(fmt.Sprintf("invalid seed %q", args[1].String())))
+		}
+		seed = s
+	}
+	current = game.NewGameWithOptions(difficulty, game.Options{Seed: seed})
+	return encode(snapshot(""))
+}
+
+// move は盤面の位置を取る操作を、JavaScriptから呼べる関数にする.
+func move(fn func(*game.Game, game.Position)) func(js.Value, []js.Value) any {
+	return func(_ js.Value, args []js.Value) any {
+		if len(args) != 2 {
+			return encode(snapshot("want row and col"))
+		}
+		pos := game.Position{Row: args[0].Int(), Col: args[1].Int()}
+		if !current.Board.IsValidPosition(pos) {
+			return encode(snapshot("outside the board"))
+		}
+		fn(current, pos)
+		return encode(snapshot(""))
+	}
+}
+
+// snapshot は今のゲームの状態。errMsg は直前の呼び出しが失敗した理由.
+func snapshot(errMsg string) state {
+	g := current
+	return state{
+		State:          g.State,
+		Difficulty:     g.Difficulty.Name,
+		Width:          g.Board.Width,
+		Height:         g.Board.Height,
+		Mines:          g.Board.Mines,
+		RemainingMines: g.GetRemainingMines(),
+		Seed:           g.Seed,
+		Board:          strings.Split(strings.TrimRight(g.Board.VisibleNotation(), "\n"), "\n"),
+		StartTime:      g.StartTime,
+		ElapsedTime:    g.ElapsedTime,
+		Error:          errMsg,
+	}
+}
+
+// currentHint はルールのソルバーで確定できるマスを求め、なければ最も安全な推測を返す.
+func currentHint() hint {
+	g := current
+	if g.State != game.Playing || g.FirstClick {
+		return hint{}
+	}
+	s := solver.NewSolver(g.Board)
+	result, rule := s.SolveWith(solver.RuleEnumeration)
+	if result.CanProgress {
+		return hint{Rule: rule.String(), Safe: result.SafeCells, Mines: result.MineCells}
+	}
+	if guess, ok := s.Guess(solver.GuessLowestProbability); ok {
+		return hint{Guess: &guess.Position, Probability: guess.Probability}
+	}
+	return hint{}
+}
+
+// encode は値をJSONの文字列にする。JavaScriptには文字列で渡し、JSON.parse で読んでもらう.
+func encode(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	return string(data)
+}
@@ -0,0 +1,35 @@
+// Package web はブラウザで遊ぶためのフロントエンドを配信する.
+//
+// ゲームとソルバーは web/wasm をWebAssemblyにしたもの（main.wasm）をブラウザで動かすので、TUIと同じシードなら同じ盤面になる.
+// main.wasm と Go の wasm_exec.js は make wasm で static に置く。置かれていなければ、ページにその手順を表示する.
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+)
+
+//go:embed static
+var static embed.FS
+
+func init() {
+	// 環境によっては .wasm の種類が登録されておらず、ブラウザがストリーミングでコンパイルできない
+	_ = mime.AddExtensionType(".wasm", "application/wasm")
+}
+
+// Handler はフロントエンドのファイルを配信するハンドラーを返す.
+// dir を指定すると、組み込みのファイルの代わりにそのディレクトリのファイルを配信する（フロントエンドの開発用）.
+func Handler(dir string) http.Handler {
+	if dir != "" {
+		return http.FileServer(http.FS(os.DirFS(dir)))
+	}
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		// static は組み込みのディレクトリなので、ここには来ない
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}